@@ -0,0 +1,164 @@
+package emergencyreporting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the number of workers that a `BulkExecutor` uses if none is given.
+const DefaultBulkConcurrency = 4
+
+// BulkOperation is the operation to perform on each item, such as an incident ID.
+//
+// The value that it returns (if any) ends up in the item's result.
+type BulkOperation func(ctx context.Context, item string) (interface{}, error)
+
+// BulkResult is the result of a single item.
+type BulkResult struct {
+	Index int         // The index of the item.
+	Item  string      // The item itself.
+	Value interface{} // The value from the operation.
+	Err   error       // The error from the operation; if the item never ran, this is the context error or `ErrorBulkStopped`.
+}
+
+// BulkExecutor runs an operation on many items with a bounded number of workers.
+type BulkExecutor struct {
+	Concurrency int                                                // The number of items to run at once.  If zero, `DefaultBulkConcurrency` is used.
+	StopOnError bool                                               // If set, no new items are started after the first failure; items that are already running are left to finish.
+	Stop        <-chan struct{}                                    // If set, no new items are started once this is closed; items that are already running are left to finish.
+	Progress    func(result *BulkResult, completed int, total int) // If set, this is called (one at a time) as each item finishes.
+}
+
+// Run runs the operation on every item and returns the results in the same order as the items.
+//
+// The context is passed to each operation, so canceling it aborts the running items as well as stopping any items
+// that have not started yet; their results have the context's error.  To let the running items finish, close
+// `Stop` instead.  With `StopOnError` or `Stop`, the items that are skipped have `ErrorBulkStopped`.
+func (e *BulkExecutor) Run(ctx context.Context, items []string, operation BulkOperation) *BulkResults {
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	// The stop flag only gates new items; the items that are already running keep the caller's context.
+	var stopMutex sync.Mutex
+	stopped := false
+
+	results := &BulkResults{
+		Results: make([]*BulkResult, len(items)),
+	}
+
+	var progressMutex sync.Mutex
+	completed := 0
+
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexes {
+				result := &BulkResult{Index: index, Item: items[index]}
+				stopMutex.Lock()
+				skip := stopped
+				stopMutex.Unlock()
+				select {
+				case <-e.Stop:
+					skip = true
+				default:
+				}
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+				} else if skip {
+					result.Err = ErrorBulkStopped
+				} else {
+					result.Value, result.Err = operation(ctx, items[index])
+					if result.Err != nil && e.StopOnError {
+						stopMutex.Lock()
+						stopped = true
+						stopMutex.Unlock()
+					}
+				}
+				results.Results[index] = result
+
+				progressMutex.Lock()
+				completed++
+				if e.Progress != nil {
+					e.Progress(result, completed, len(items))
+				}
+				progressMutex.Unlock()
+			}
+		}()
+	}
+
+	for index := range items {
+		indexes <- index
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	return results
+}
+
+// BulkResults are the results of a bulk operation.
+type BulkResults struct {
+	Results []*BulkResult // The results, in the same order as the items.
+}
+
+// Values returns the values from the items that succeeded, in order.
+func (r *BulkResults) Values() []interface{} {
+	var values []interface{}
+	for _, result := range r.Results {
+		if result.Err == nil {
+			values = append(values, result.Value)
+		}
+	}
+	return values
+}
+
+// Succeeded returns the number of items that succeeded.
+func (r *BulkResults) Succeeded() int {
+	return len(r.Results) - r.Failed()
+}
+
+// Failed returns the number of items that failed.
+func (r *BulkResults) Failed() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Err returns a `*BulkError` with all of the failures, or nil if everything succeeded.
+func (r *BulkResults) Err() error {
+	var failures []*BulkResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BulkError{Failures: failures, Total: len(r.Results)}
+}
+
+// BulkError is the combined error from a bulk operation.
+type BulkError struct {
+	Failures []*BulkResult // The items that failed.
+	Total    int           // The total number of items.
+}
+
+// Error returns a summary of all of the failures.
+func (e *BulkError) Error() string {
+	var parts []string
+	for _, failure := range e.Failures {
+		parts = append(parts, fmt.Sprintf("%s: %v", failure.Item, failure.Err))
+	}
+	return fmt.Sprintf("%d of %d items failed: %s", len(e.Failures), e.Total, strings.Join(parts, "; "))
+}
@@ -0,0 +1,108 @@
+package emergencyreporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBulkExecutorOrder(t *testing.T) {
+	items := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+	executor := &BulkExecutor{Concurrency: 3}
+	results := executor.Run(context.Background(), items, func(ctx context.Context, item string) (interface{}, error) {
+		if item == "4" {
+			return nil, fmt.Errorf("bad item")
+		}
+		return "value " + item, nil
+	})
+	if results.Succeeded() != 7 || results.Failed() != 1 {
+		t.Fatalf("Wrong counts: %d succeeded, %d failed", results.Succeeded(), results.Failed())
+	}
+	values := results.Values()
+	if len(values) != 7 || values[0] != "value 1" || values[3] != "value 5" {
+		t.Errorf("Wrong values: %v", values)
+	}
+	var bulkError *BulkError
+	if !errors.As(results.Err(), &bulkError) || len(bulkError.Failures) != 1 || bulkError.Failures[0].Item != "4" {
+		t.Errorf("Wrong error: %v", results.Err())
+	}
+}
+
+func TestBulkExecutorStopOnError(t *testing.T) {
+	items := []string{"fail", "slow", "3", "4", "5"}
+
+	started := make(chan struct{})
+	failed := make(chan struct{})
+	executor := &BulkExecutor{
+		Concurrency: 2,
+		StopOnError: true,
+		Progress: func(result *BulkResult, completed int, total int) {
+			if result.Item == "fail" {
+				close(failed)
+			}
+		},
+	}
+	results := executor.Run(context.Background(), items, func(ctx context.Context, item string) (interface{}, error) {
+		switch item {
+		case "fail":
+			<-started // Make sure that "slow" is already running.
+			return nil, fmt.Errorf("failed")
+		case "slow":
+			close(started)
+			<-failed
+			time.Sleep(10 * time.Millisecond)
+			return "slow", ctx.Err() // The running item must not be canceled by the failure.
+		}
+		return item, nil
+	})
+
+	if results.Results[0].Err == nil {
+		t.Errorf("The first item should have failed")
+	}
+	if results.Results[1].Err != nil {
+		t.Errorf("The running item was canceled: %v", results.Results[1].Err)
+	}
+	for _, result := range results.Results[2:] {
+		if !errors.Is(result.Err, ErrorBulkStopped) {
+			t.Errorf("Item %s has the wrong error: %v", result.Item, result.Err)
+		}
+	}
+}
+
+func TestBulkExecutorCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	executor := &BulkExecutor{}
+	results := executor.Run(ctx, []string{"1", "2"}, func(ctx context.Context, item string) (interface{}, error) {
+		t.Errorf("Item %s should not have run", item)
+		return nil, nil
+	})
+	for _, result := range results.Results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Item %s has the wrong error: %v", result.Item, result.Err)
+		}
+	}
+}
+
+func TestBulkExecutorStop(t *testing.T) {
+	stop := make(chan struct{})
+	executor := &BulkExecutor{Concurrency: 1, Stop: stop}
+	results := executor.Run(context.Background(), []string{"1", "2", "3"}, func(ctx context.Context, item string) (interface{}, error) {
+		if item == "1" {
+			close(stop)
+			time.Sleep(10 * time.Millisecond)
+			return "1", ctx.Err() // The running item must not be canceled by the stop.
+		}
+		return item, nil
+	})
+	if results.Results[0].Err != nil {
+		t.Errorf("The running item was canceled: %v", results.Results[0].Err)
+	}
+	for _, result := range results.Results[1:] {
+		if !errors.Is(result.Err, ErrorBulkStopped) {
+			t.Errorf("Item %s has the wrong error: %v", result.Item, result.Err)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package cadimport
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+const testMapping = `
+format: xml
+records: Call
+key: incidentNumber
+timezone: America/Chicago
+incident:
+  incidentNumber:
+    from: "@id"
+    transform: trim
+  incidentDateTime:
+    from: Received
+    transform: datetime
+    layout: "01/02/2006 15:04"
+  stationID:
+    from: Station
+    map:
+      "Station 1": "1"
+    default: "9"
+location:
+  streetName:
+    from: Address.Street
+    transform: upper
+  city:
+    value: Springfield
+apparatus:
+  from: Units.Unit
+  fields:
+    departmentApparatusID:
+      from: "#text"
+      map:
+        E1: "11"
+        M1: "12"
+    dispatchDateTime:
+      from: "@dispatched"
+      transform: datetime
+      layout: "15:04 01/02/2006"
+`
+
+const testRecords = `<?xml version="1.0"?>
+<Calls>
+  <Call id=" 2021-0001 ">
+    <Received>03/04/2021 05:06</Received>
+    <Station>Station 1</Station>
+    <Address><Street>Main</Street></Address>
+    <Units>
+      <Unit dispatched="05:07 03/04/2021">E1</Unit>
+      <Unit dispatched="05:08 03/04/2021">M1</Unit>
+    </Units>
+  </Call>
+  <Call id="2021-0002">
+    <Received>03/05/2021 10:00</Received>
+    <Units><Unit>E1</Unit></Units>
+  </Call>
+</Calls>
+`
+
+func TestParseMapping(t *testing.T) {
+	rows := []struct {
+		description string
+		input       string
+		err         bool
+	}{
+		{
+			description: "Valid",
+			input:       testMapping,
+		},
+		{
+			description: "Missing key",
+			input:       "incident:\n  incidentNumber:\n    from: ID\n",
+			err:         true,
+		},
+		{
+			description: "Invalid key",
+			input:       "key: stationID\nincident:\n  stationID:\n    from: ID\n",
+			err:         true,
+		},
+		{
+			description: "Key is not mapped",
+			input:       "key: incidentNumber\nincident:\n  stationID:\n    from: ID\n",
+			err:         true,
+		},
+		{
+			description: "Invalid format",
+			input:       "key: incidentNumber\nformat: csv\nincident:\n  incidentNumber:\n    from: ID\n",
+			err:         true,
+		},
+		{
+			description: "Unknown transform",
+			input:       "key: incidentNumber\nincident:\n  incidentNumber:\n    from: ID\n    transform: reverse\n",
+			err:         true,
+		},
+		{
+			description: "Unknown field",
+			input:       "key: incidentNumber\nkeys: x\nincident:\n  incidentNumber:\n    from: ID\n",
+			err:         true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			_, err := ParseMapping([]byte(row.input))
+			if row.err && err == nil {
+				t.Errorf("Expected an error")
+			}
+			if !row.err && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRecords(t *testing.T) {
+	records, err := ParseRecords([]byte(testRecords), "xml", "Call")
+	if err != nil {
+		t.Fatalf("Could not parse the records: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records; got %d", len(records))
+	}
+
+	rows := []struct {
+		path     string
+		expected string
+	}{
+		{"@id", " 2021-0001 "},
+		{"Address.Street", "Main"},
+		{"Units.Unit.1.#text", "M1"},
+		{"Units.Unit.1.@dispatched", "05:08 03/04/2021"},
+		{"Units.Unit.2", ""},
+		{"Missing.Path", ""},
+	}
+	for _, row := range rows {
+		if actual := lookupString(records[0], row.path); actual != row.expected {
+			t.Errorf("%s: expected %q; got %q", row.path, row.expected, actual)
+		}
+	}
+
+	// A single element is a list with one item.
+	if units := lookupList(records[1], "Units.Unit"); len(units) != 1 {
+		t.Errorf("Expected 1 unit; got %d", len(units))
+	}
+
+	records, err = ParseRecords([]byte(`{"calls": [{"id": 12345678901234567890}]}`), "json", "calls")
+	if err != nil {
+		t.Fatalf("Could not parse the records: %v", err)
+	}
+	if actual := lookupString(records[0], "id"); actual != "12345678901234567890" {
+		t.Errorf("The number was not kept exactly: %s", actual)
+	}
+
+	_, err = ParseRecords([]byte("a,b"), "csv", "")
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestApply(t *testing.T) {
+	mapping, err := ParseMapping([]byte(testMapping))
+	if err != nil {
+		t.Fatalf("Could not parse the mapping: %v", err)
+	}
+	records, err := ParseRecords([]byte(testRecords), mapping.Format, mapping.Records)
+	if err != nil {
+		t.Fatalf("Could not parse the records: %v", err)
+	}
+
+	rows := []struct {
+		description string
+		fields      map[string]FieldMapping
+		record      interface{}
+		expected    map[string]interface{}
+	}{
+		{
+			description: "Incident",
+			fields:      mapping.Incident,
+			record:      records[0],
+			expected:    map[string]interface{}{"incidentNumber": "2021-0001", "incidentDateTime": "2021-03-04T05:06:00", "stationID": "1"},
+		},
+		{
+			description: "Default",
+			fields:      mapping.Incident,
+			record:      records[1],
+			expected:    map[string]interface{}{"incidentNumber": "2021-0002", "incidentDateTime": "2021-03-05T10:00:00", "stationID": "9"},
+		},
+		{
+			description: "Constant",
+			fields:      mapping.Location,
+			record:      records[1],
+			expected:    map[string]interface{}{"city": "Springfield"},
+		},
+		{
+			description: "Transform",
+			fields:      mapping.Location,
+			record:      records[0],
+			expected:    map[string]interface{}{"streetName": "MAIN", "city": "Springfield"},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual, err := mapping.apply(row.fields, row.record)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, row.expected) {
+				t.Errorf("Expected %v; got %v", row.expected, actual)
+			}
+		})
+	}
+
+	_, err = mapping.apply(map[string]FieldMapping{"incidentDateTime": {From: "@id", Transform: "datetime"}}, records[0])
+	if err == nil {
+		t.Errorf("Expected an error for a bad date/time")
+	}
+}
+
+// newTestImporter returns an importer for the server that uses the test mapping.
+func newTestImporter(t *testing.T, server *ertest.Server) *Importer {
+	client := server.Client()
+	tokenResponse, err := client.GenerateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Could not generate a token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+
+	mapping, err := ParseMapping([]byte(testMapping))
+	if err != nil {
+		t.Fatalf("Could not parse the mapping: %v", err)
+	}
+	return &Importer{
+		Client:  client,
+		Mapping: mapping,
+	}
+}
+
+func TestImportRecord(t *testing.T) {
+	ctx := context.Background()
+	server := ertest.NewServer()
+	defer server.Close()
+
+	importer := newTestImporter(t, server)
+	mapping := importer.Mapping
+	records, err := ParseRecords([]byte(testRecords), mapping.Format, mapping.Records)
+	if err != nil {
+		t.Fatalf("Could not parse the records: %v", err)
+	}
+
+	result, err := importer.ImportRecord(ctx, records[0])
+	if err != nil {
+		t.Fatalf("Could not import the record: %v", err)
+	}
+	if result.Action != ActionCreated || result.Key != "2021-0001" || result.ApparatusesCreated != 2 {
+		t.Errorf("Wrong result: %+v", result)
+	}
+	if incident := server.Incident(result.IncidentID); incident == nil || incident.StationID != "1" {
+		t.Errorf("Wrong incident: %+v", incident)
+	}
+
+	// The same record again changes nothing.
+	result, err = importer.ImportRecord(ctx, records[0])
+	if err != nil {
+		t.Fatalf("Could not import the record again: %v", err)
+	}
+	if result.Action != ActionUnchanged || result.ApparatusesCreated != 0 {
+		t.Errorf("Wrong result: %+v", result)
+	}
+
+	// A changed record updates the incident.
+	mapping.Incident["stationID"] = FieldMapping{Value: "2"}
+	result, err = importer.ImportRecord(ctx, records[0])
+	if err != nil {
+		t.Fatalf("Could not import the changed record: %v", err)
+	}
+	if result.Action != ActionUpdated {
+		t.Errorf("Wrong result: %+v", result)
+	}
+	if incident := server.Incident(result.IncidentID); incident == nil || incident.StationID != "2" {
+		t.Errorf("Wrong incident: %+v", incident)
+	}
+
+	_, err = importer.ImportRecord(ctx, map[string]interface{}{})
+	if err == nil {
+		t.Errorf("Expected an error for a record without a key")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := ertest.NewServer()
+	defer server.Close()
+	importer := newTestImporter(t, server)
+
+	directory, err := ioutil.TempDir("", "cadimport")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+	write := func(filename string, contents string) {
+		err := ioutil.WriteFile(filepath.Join(directory, filename), []byte(contents), 0644)
+		if err != nil {
+			t.Fatalf("Could not write %s: %v", filename, err)
+		}
+	}
+	write("calls.xml", testRecords)
+	write("broken.xml", "<Calls>")
+	write("notes.txt", "Not a CAD file.")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	imported := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- importer.Watch(ctx, directory, 5*time.Millisecond, func(filename string, results []*Result, err error) {
+			if err == nil && len(results) != 2 {
+				t.Errorf("%s: expected 2 results; got %d", filename, len(results))
+			}
+			imported <- filepath.Base(filename)
+		})
+	}()
+	wait := func() string {
+		select {
+		case filename := <-imported:
+			return filename
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for a file to be imported")
+		}
+		return ""
+	}
+	if first, second := wait(), wait(); first != "broken.xml" || second != "calls.xml" {
+		t.Errorf("Expected broken.xml and then calls.xml; got %s and %s", first, second)
+	}
+
+	// A file with the same name must not replace the first one.
+	write("calls.xml", testRecords)
+	if filename := wait(); filename != "calls.xml" {
+		t.Errorf("Expected calls.xml; got %s", filename)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context's error; got %v", err)
+	}
+
+	for _, row := range []struct {
+		subdirectory string
+		prefix       string
+		count        int
+	}{
+		{"processed", "calls.", 2},
+		{"failed", "broken.", 1},
+	} {
+		entries, err := ioutil.ReadDir(filepath.Join(directory, row.subdirectory))
+		if err != nil {
+			t.Fatalf("Could not read %s: %v", row.subdirectory, err)
+		}
+		if len(entries) != row.count {
+			t.Errorf("Expected %d files in %s; got %d", row.count, row.subdirectory, len(entries))
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), row.prefix) || !strings.HasSuffix(entry.Name(), ".xml") {
+				t.Errorf("Unexpected file in %s: %s", row.subdirectory, entry.Name())
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(directory, "notes.txt")); err != nil {
+		t.Errorf("Other files should be left alone: %v", err)
+	}
+}
+
+func TestWatchCanceled(t *testing.T) {
+	server := ertest.NewServer()
+	defer server.Close()
+	importer := newTestImporter(t, server)
+
+	directory, err := ioutil.TempDir("", "cadimport")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+	err = ioutil.WriteFile(filepath.Join(directory, "calls.xml"), []byte(testRecords), 0644)
+	if err != nil {
+		t.Fatalf("Could not write the file: %v", err)
+	}
+
+	// The watch is stopped in the middle of the import.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	importer.Client.Use(emergencyreporting.BeforeRequest(func(request *http.Request) error {
+		cancel()
+		return context.Canceled
+	}))
+
+	err = importer.Watch(ctx, directory, 5*time.Millisecond, func(filename string, results []*Result, err error) {
+		t.Errorf("%s should not have been finished", filename)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context's error; got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "calls.xml")); err != nil {
+		t.Errorf("The file should have been left in place: %v", err)
+	}
+	for _, subdirectory := range []string{"processed", "failed"} {
+		entries, _ := ioutil.ReadDir(filepath.Join(directory, subdirectory))
+		if len(entries) != 0 {
+			t.Errorf("Expected nothing in %s; got %d files", subdirectory, len(entries))
+		}
+	}
+}
+
+func TestUniqueFilename(t *testing.T) {
+	directory, err := ioutil.TempDir("", "cadimport")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	now := time.Date(2021, 3, 4, 5, 6, 30, 0, time.FixedZone("CST", -6*60*60))
+	for _, expected := range []string{"call.20210304T110630Z.xml", "call.20210304T110630Z-2.xml", "call.20210304T110630Z-3.xml"} {
+		filename, err := uniqueFilename(directory, "call.xml", now)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if filepath.Base(filename) != expected {
+			t.Errorf("Expected %s; got %s", expected, filepath.Base(filename))
+		}
+		err = ioutil.WriteFile(filename, nil, 0644)
+		if err != nil {
+			t.Fatalf("Could not write %s: %v", filename, err)
+		}
+	}
+}
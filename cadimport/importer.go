@@ -0,0 +1,421 @@
+// Package cadimport creates Emergency Reporting incidents from CAD (computer-aided dispatch) files.
+//
+// Each CAD file holds one or more call records in JSON or XML.  A YAML mapping file describes
+// how the fields of a record become an incident, its exposure, the exposure location, and the
+// exposure apparatuses.  Records are matched to existing incidents by the mapping's key field,
+// so importing the same record twice updates the incident instead of duplicating it.
+package cadimport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// Import actions.
+const (
+	ActionCreated   = emergencyreporting.UpsertActionCreated
+	ActionUpdated   = emergencyreporting.UpsertActionUpdated
+	ActionUnchanged = emergencyreporting.UpsertActionUnchanged
+)
+
+// Result is the result of importing a single CAD record.
+type Result struct {
+	File               string `json:"file,omitempty"`
+	Key                string `json:"key"`
+	Action             string `json:"action"`
+	IncidentID         string `json:"incidentID"`
+	ExposureID         string `json:"exposureID"`
+	ApparatusesCreated int    `json:"apparatusesCreated"`
+}
+
+// Importer imports CAD records using a mapping.
+type Importer struct {
+	Client  *emergencyreporting.Client
+	Mapping *Mapping
+	Logger  emergencyreporting.Logger // If empty, then nothing will be logged.
+}
+
+// logger returns the logger to use.
+func (i *Importer) logger() emergencyreporting.Logger {
+	if i.Logger == nil {
+		return emergencyreporting.NullLogger{}
+	}
+	return i.Logger
+}
+
+// ImportFile imports all of the records in a CAD file.
+//
+// This stops at the first record that fails; the results for the records before it are still returned.
+func (i *Importer) ImportFile(ctx context.Context, filename string) ([]*Result, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+
+	format := i.Mapping.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+
+	records, err := ParseRecords(contents, format, i.Mapping.Records)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*Result
+	for index, record := range records {
+		result, err := i.ImportRecord(ctx, record)
+		if err != nil {
+			return results, fmt.Errorf("record %d: %w", index, err)
+		}
+		result.File = filename
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ImportRecord imports a single CAD record.
+func (i *Importer) ImportRecord(ctx context.Context, record interface{}) (*Result, error) {
+	incidentFields, err := i.Mapping.apply(i.Mapping.Incident, record)
+	if err != nil {
+		return nil, fmt.Errorf("incident: %w", err)
+	}
+	exposureFields, err := i.Mapping.apply(i.Mapping.Exposure, record)
+	if err != nil {
+		return nil, fmt.Errorf("exposure: %w", err)
+	}
+	locationFields, err := i.Mapping.apply(i.Mapping.Location, record)
+	if err != nil {
+		return nil, fmt.Errorf("location: %w", err)
+	}
+	var apparatusesFields []map[string]interface{}
+	if i.Mapping.Apparatus.From != "" {
+		for index, unit := range lookupList(record, i.Mapping.Apparatus.From) {
+			fields, err := i.Mapping.apply(i.Mapping.Apparatus.Fields, unit)
+			if err != nil {
+				return nil, fmt.Errorf("apparatus %d: %w", index, err)
+			}
+			apparatusesFields = append(apparatusesFields, fields)
+		}
+	}
+
+	key, _ := incidentFields[i.Mapping.Key].(string)
+	if key == "" {
+		return nil, fmt.Errorf("the record has no value for the key field: %s", i.Mapping.Key)
+	}
+
+	result := &Result{
+		Key: key,
+	}
+
+	var incident emergencyreporting.Incident
+	err = convert(incidentFields, &incident)
+	if err != nil {
+		return nil, fmt.Errorf("incident: %w", err)
+	}
+	upsertResponse, err := i.Client.UpsertIncident(ctx, incident, i.Mapping.Key)
+	if err != nil {
+		return nil, err
+	}
+	result.Action = upsertResponse.Action
+	result.IncidentID = upsertResponse.IncidentID
+	switch result.Action {
+	case ActionCreated:
+		i.logger().Printf("Created incident %s for %s %s.\n", result.IncidentID, i.Mapping.Key, key)
+	case ActionUpdated:
+		i.logger().Printf("Updated incident %s for %s %s.\n", result.IncidentID, i.Mapping.Key, key)
+	}
+
+	var exposure *emergencyreporting.Exposure
+	if result.Action != ActionCreated {
+		exposuresResponse, err := i.Client.GetIncidentExposures(ctx, result.IncidentID, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(exposuresResponse.Exposures) > 0 {
+			exposure = exposuresResponse.Exposures[0]
+		}
+	}
+	if exposure == nil {
+		var newExposure emergencyreporting.Exposure
+		err = convert(exposureFields, &newExposure)
+		if err != nil {
+			return nil, fmt.Errorf("exposure: %w", err)
+		}
+		postExposureResponse, err := i.Client.PostIncidentExposure(ctx, result.IncidentID, newExposure)
+		if err != nil {
+			return nil, err
+		}
+		result.ExposureID = postExposureResponse.ExposureID
+		if result.Action == ActionUnchanged {
+			result.Action = ActionUpdated
+		}
+	} else {
+		result.ExposureID = exposure.ExposureID
+
+		var patch emergencyreporting.PatchExposureRequest
+		changed, err := changedFields(exposureFields, exposure, &patch)
+		if err != nil {
+			return nil, fmt.Errorf("exposure: %w", err)
+		}
+		if changed {
+			_, err = i.Client.PatchIncidentExposure(ctx, result.IncidentID, exposure.ExposureID, exposure.RowVersion, patch)
+			if err != nil {
+				return nil, err
+			}
+			if result.Action == ActionUnchanged {
+				result.Action = ActionUpdated
+			}
+		}
+	}
+
+	if len(locationFields) > 0 {
+		var location emergencyreporting.ExposureLocation
+		locationResponse, err := i.Client.GetExposureLocation(ctx, result.ExposureID)
+		if err != nil {
+			if !errors.Is(err, emergencyreporting.ErrorNotFound) {
+				return nil, err
+			}
+		} else if locationResponse.Location != nil {
+			location = *locationResponse.Location
+		}
+		original := location
+
+		err = convert(locationFields, &location)
+		if err != nil {
+			return nil, fmt.Errorf("location: %w", err)
+		}
+		if !reflect.DeepEqual(original, location) {
+			location.ExposureID = result.ExposureID
+			_, err = i.Client.PutExposureLocation(ctx, result.ExposureID, location)
+			if err != nil {
+				return nil, err
+			}
+			if result.Action == ActionUnchanged {
+				result.Action = ActionUpdated
+			}
+		}
+	}
+
+	if len(apparatusesFields) > 0 {
+		existing := map[string]bool{}
+		{
+			apparatusesResponse, err := i.Client.GetExposureApparatuses(ctx, result.ExposureID)
+			if err != nil {
+				return nil, err
+			}
+			for _, apparatus := range apparatusesResponse.Apparatuses {
+				existing[apparatusKey(apparatus)] = true
+			}
+		}
+		for index, fields := range apparatusesFields {
+			apparatus := emergencyreporting.ExposureApparatus{
+				IncidentID: result.IncidentID,
+				ExposureID: result.ExposureID,
+			}
+			err = convert(fields, &apparatus)
+			if err != nil {
+				return nil, fmt.Errorf("apparatus %d: %w", index, err)
+			}
+			key := apparatusKey(&apparatus)
+			if key == "" {
+				// Without anything to identify the unit, it would be created again on every import.
+				i.logger().Printf("Skipping apparatus %d for %s %s: it has no agency or department apparatus ID.\n", index, i.Mapping.Key, result.Key)
+				continue
+			}
+			if existing[key] {
+				continue
+			}
+			_, err = i.Client.PostExposureApparatus(ctx, result.ExposureID, apparatus)
+			if err != nil {
+				return nil, err
+			}
+			existing[key] = true
+			result.ApparatusesCreated++
+			if result.Action == ActionUnchanged {
+				result.Action = ActionUpdated
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// apparatusKey returns the key that identifies an exposure apparatus when importing.
+//
+// This is the agency apparatus ID, or the department apparatus ID if there is none; it is empty
+// if the apparatus has neither.
+func apparatusKey(apparatus *emergencyreporting.ExposureApparatus) string {
+	if apparatus.AgencyApparatusID != "" {
+		return "agency:" + apparatus.AgencyApparatusID
+	}
+	if apparatus.DepartmentApparatusID != "" {
+		return "department:" + apparatus.DepartmentApparatusID
+	}
+	return ""
+}
+
+// watchedFile is the size and modification time of a file in a watched directory.
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watch imports every CAD file that appears in the given directory until the context is done.
+//
+// The directory is checked at the given interval.  A file is only imported once its size and
+// modification time have not changed between two checks, so that files that the CAD system is
+// still writing are left alone.  Files are moved into a "processed" or "failed" subdirectory once
+// they have been imported, so that they are not imported again; each one gets a timestamp suffix
+// (see `uniqueFilename`) so that a later file with the same name doesn't replace it.  A file whose
+// import was cut short by the context being done is left where it is, to be imported next time.
+func (i *Importer) Watch(ctx context.Context, directory string, interval time.Duration, callback func(filename string, results []*Result, err error)) error {
+	for _, name := range []string{"processed", "failed"} {
+		err := os.MkdirAll(filepath.Join(directory, name), 0755)
+		if err != nil {
+			return fmt.Errorf("could not create directory: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen := map[string]watchedFile{}
+	for {
+		entries, err := ioutil.ReadDir(directory)
+		if err != nil {
+			return fmt.Errorf("could not read directory: %w", err)
+		}
+
+		var filenames []string
+		current := map[string]watchedFile{}
+		for _, entry := range entries {
+			if !entry.Mode().IsRegular() {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".json", ".xml":
+				state := watchedFile{size: entry.Size(), modTime: entry.ModTime()}
+				current[entry.Name()] = state
+				if previous, ok := seen[entry.Name()]; ok && previous == state {
+					filenames = append(filenames, entry.Name())
+				}
+			}
+		}
+		seen = current
+		sort.Strings(filenames)
+
+		for _, filename := range filenames {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			source := filepath.Join(directory, filename)
+			results, importErr := i.ImportFile(ctx, source)
+			if importErr != nil && ctx.Err() != nil {
+				// The records are matched by their keys, so importing the file again picks up where this left off.
+				return ctx.Err()
+			}
+
+			subdirectory := "processed"
+			if importErr != nil {
+				i.logger().Printf("Could not import %s: %v\n", source, importErr)
+				subdirectory = "failed"
+			}
+			destination, err := uniqueFilename(filepath.Join(directory, subdirectory), filename, time.Now())
+			if err == nil {
+				err = os.Rename(source, destination)
+			}
+			if err != nil {
+				return fmt.Errorf("could not move %s: %w", source, err)
+			}
+
+			if callback != nil {
+				callback(source, results, importErr)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// uniqueFilename returns a path in the directory for the file that no other file has, such as
+// "call.20210304T050630Z.xml" for "call.xml" at the given time (with a counter if that is taken).
+func uniqueFilename(directory string, filename string, now time.Time) (string, error) {
+	extension := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, extension) + "." + now.UTC().Format("20060102T150405Z")
+	for count := 1; ; count++ {
+		candidate := base
+		if count > 1 {
+			candidate += fmt.Sprintf("-%d", count)
+		}
+		candidate = filepath.Join(directory, candidate+extension)
+		_, err := os.Lstat(candidate)
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// convert copies the mapped fields (keyed by JSON name) into the target.
+//
+// Fields that are not mapped are left alone.
+func convert(fields map[string]interface{}, target interface{}) error {
+	contents, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(contents, target)
+}
+
+// changedFields fills in the patch with the mapped fields whose values differ from the existing object.
+//
+// This returns true if any field changed.
+func changedFields(fields map[string]interface{}, existing interface{}, patch interface{}) (bool, error) {
+	contents, err := json.Marshal(existing)
+	if err != nil {
+		return false, err
+	}
+	var current map[string]interface{}
+	err = json.Unmarshal(contents, &current)
+	if err != nil {
+		return false, err
+	}
+
+	changes := map[string]interface{}{}
+	for name, value := range fields {
+		if currentValue, ok := current[name]; ok && currentValue == value {
+			continue
+		}
+		changes[name] = value
+	}
+	err = convert(changes, patch)
+	if err != nil {
+		return false, err
+	}
+
+	// Fields that can't be patched are dropped by the conversion, so check what's left.
+	contents, err = json.Marshal(patch)
+	if err != nil {
+		return false, err
+	}
+	return string(contents) != "{}", nil
+}
@@ -0,0 +1,199 @@
+package cadimport
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"gopkg.in/yaml.v2"
+)
+
+// Key fields that can be used to find an existing incident for a CAD record.
+const (
+	KeyIncidentNumber        = emergencyreporting.IncidentKeyIncidentNumber
+	KeyPartnerIncidentNumber = emergencyreporting.IncidentKeyPartnerIncidentNumber
+	KeyDispatchRunNumber     = emergencyreporting.IncidentKeyDispatchRunNumber
+)
+
+// Mapping describes how CAD records are turned into Emergency Reporting objects.
+//
+// Each of the field maps is keyed by the JSON name of the target field (for example,
+// "incidentNumber" or "streetName").
+type Mapping struct {
+	Format   string `yaml:"format"`   // "json" or "xml".  If empty, this is determined from the file extension.
+	Records  string `yaml:"records"`  // The path to the list of records in each file.  If empty, the file is a single record.
+	Key      string `yaml:"key"`      // The incident field used to find existing incidents; one of the `Key*` constants.
+	TimeZone string `yaml:"timezone"` // The time zone for "datetime" transforms.  If empty, the local time zone is used.
+
+	Incident  map[string]FieldMapping `yaml:"incident"`
+	Exposure  map[string]FieldMapping `yaml:"exposure"`
+	Location  map[string]FieldMapping `yaml:"location"`
+	Apparatus ApparatusMapping        `yaml:"apparatus"`
+
+	location *time.Location
+}
+
+// ApparatusMapping describes how the apparatus list in a CAD record is mapped.
+type ApparatusMapping struct {
+	From   string                  `yaml:"from"` // The path to the list of units in the record.
+	Fields map[string]FieldMapping `yaml:"fields"`
+}
+
+// FieldMapping describes how a single target field is computed.
+type FieldMapping struct {
+	From      string            `yaml:"from"`      // The path to the source value, such as "Location.Street".
+	Value     string            `yaml:"value"`     // A constant value; used when `From` is empty.
+	Default   string            `yaml:"default"`   // The value to use when the source value is empty.
+	Transform string            `yaml:"transform"` // The name of a registered transform to apply.
+	Layout    string            `yaml:"layout"`    // The source layout for the "datetime" transform.
+	Map       map[string]string `yaml:"map"`       // A lookup table for source values; unknown values are passed through.
+}
+
+// TransformFunc converts a source value into a target value.
+type TransformFunc func(value string, field FieldMapping, mapping *Mapping) (string, error)
+
+var (
+	transformsMutex sync.RWMutex
+	transforms      = map[string]TransformFunc{
+		"upper":    transformUpper,
+		"lower":    transformLower,
+		"trim":     transformTrim,
+		"datetime": transformDateTime,
+	}
+)
+
+// RegisterTransform registers a transform that can be referenced by name in mapping files.
+//
+// This will replace any existing transform with the same name.
+func RegisterTransform(name string, transform TransformFunc) {
+	transformsMutex.Lock()
+	defer transformsMutex.Unlock()
+
+	transforms[name] = transform
+}
+
+// LoadMapping loads a mapping from a YAML file.
+func LoadMapping(filename string) (*Mapping, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read mapping file: %w", err)
+	}
+	return ParseMapping(contents)
+}
+
+// ParseMapping parses a mapping from YAML.
+func ParseMapping(contents []byte) (*Mapping, error) {
+	var mapping Mapping
+	err := yaml.UnmarshalStrict(contents, &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse mapping: %w", err)
+	}
+
+	switch mapping.Key {
+	case KeyIncidentNumber, KeyPartnerIncidentNumber, KeyDispatchRunNumber:
+	case "":
+		return nil, fmt.Errorf("missing key")
+	default:
+		return nil, fmt.Errorf("invalid key: %s", mapping.Key)
+	}
+	if _, ok := mapping.Incident[mapping.Key]; !ok {
+		return nil, fmt.Errorf("the incident mapping must include the key field: %s", mapping.Key)
+	}
+
+	switch mapping.Format {
+	case "", "json", "xml":
+	default:
+		return nil, fmt.Errorf("invalid format: %s", mapping.Format)
+	}
+
+	mapping.location = time.Local
+	if mapping.TimeZone != "" {
+		mapping.location, err = time.LoadLocation(mapping.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+	}
+
+	for _, fields := range []map[string]FieldMapping{mapping.Incident, mapping.Exposure, mapping.Location, mapping.Apparatus.Fields} {
+		for name, field := range fields {
+			if field.Transform == "" {
+				continue
+			}
+			transformsMutex.RLock()
+			_, ok := transforms[field.Transform]
+			transformsMutex.RUnlock()
+			if !ok {
+				return nil, fmt.Errorf("field %s: unknown transform: %s", name, field.Transform)
+			}
+		}
+	}
+
+	return &mapping, nil
+}
+
+// apply computes all of the target fields from the given source record.
+//
+// Fields with empty results are omitted.
+func (m *Mapping) apply(fields map[string]FieldMapping, record interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for name, field := range fields {
+		value := field.Value
+		if field.From != "" {
+			value = lookupString(record, field.From)
+		}
+		if mapped, ok := field.Map[value]; ok {
+			value = mapped
+		}
+		if value == "" {
+			value = field.Default
+		}
+		if value != "" && field.Transform != "" {
+			transformsMutex.RLock()
+			transform := transforms[field.Transform]
+			transformsMutex.RUnlock()
+
+			var err error
+			value, err = transform(value, field, m)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+		if value == "" {
+			continue
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+func transformUpper(value string, field FieldMapping, mapping *Mapping) (string, error) {
+	return strings.ToUpper(value), nil
+}
+
+func transformLower(value string, field FieldMapping, mapping *Mapping) (string, error) {
+	return strings.ToLower(value), nil
+}
+
+func transformTrim(value string, field FieldMapping, mapping *Mapping) (string, error) {
+	return strings.TrimSpace(value), nil
+}
+
+// transformDateTime converts a date/time in the field's layout to the Emergency Reporting format.
+func transformDateTime(value string, field FieldMapping, mapping *Mapping) (string, error) {
+	layout := field.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	location := mapping.location
+	if location == nil {
+		location = time.Local
+	}
+	t, err := time.ParseInLocation(layout, strings.TrimSpace(value), location)
+	if err != nil {
+		return "", fmt.Errorf("could not parse date/time: %w", err)
+	}
+	return t.Format("2006-01-02T15:04:05"), nil
+}
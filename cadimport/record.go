@@ -0,0 +1,181 @@
+package cadimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseRecords parses the contents of a CAD file into generic records.
+//
+// JSON files are decoded as-is.  XML files are converted into nested maps: child elements
+// become keys, repeated elements become lists, attributes are prefixed with "@", and the text
+// of an element with attributes or children is stored as "#text".
+//
+// If `recordsPath` is set, then the records are taken from that path; otherwise, the whole file
+// is a single record.
+func ParseRecords(contents []byte, format string, recordsPath string) ([]interface{}, error) {
+	var document interface{}
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.UseNumber()
+		err := decoder.Decode(&document)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JSON: %w", err)
+		}
+	case "xml":
+		var err error
+		document, err = parseXML(contents)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse XML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	if recordsPath == "" {
+		return []interface{}{document}, nil
+	}
+
+	value := lookup(document, recordsPath)
+	switch typedValue := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return typedValue, nil
+	default:
+		// A single XML element looks the same as a list with one item.
+		return []interface{}{typedValue}, nil
+	}
+}
+
+// parseXML converts an XML document into nested maps.
+func parseXML(contents []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(contents))
+
+	type frame struct {
+		name     string
+		element  map[string]interface{}
+		text     strings.Builder
+		children bool
+	}
+
+	root := map[string]interface{}{}
+	stack := []*frame{{element: root}}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch typedToken := token.(type) {
+		case xml.StartElement:
+			f := &frame{
+				name:    typedToken.Name.Local,
+				element: map[string]interface{}{},
+			}
+			for _, attribute := range typedToken.Attr {
+				f.element["@"+attribute.Name.Local] = attribute.Value
+			}
+			stack[len(stack)-1].children = true
+			stack = append(stack, f)
+		case xml.EndElement:
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			var value interface{}
+			text := strings.TrimSpace(f.text.String())
+			if !f.children && len(f.element) == 0 {
+				value = text
+			} else {
+				if text != "" {
+					f.element["#text"] = text
+				}
+				value = f.element
+			}
+
+			parent := stack[len(stack)-1].element
+			if existing, ok := parent[f.name]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					parent[f.name] = append(list, value)
+				} else {
+					parent[f.name] = []interface{}{existing, value}
+				}
+			} else {
+				parent[f.name] = value
+			}
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(typedToken)
+		}
+	}
+
+	// Unwrap the document element.
+	for _, value := range root {
+		return value, nil
+	}
+	return nil, fmt.Errorf("empty document")
+}
+
+// lookup finds the value at a dotted path, such as "Units.0.UnitID".
+//
+// Numeric path components index into lists.  Missing values return nil.
+func lookup(value interface{}, path string) interface{} {
+	for _, part := range strings.Split(path, ".") {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			value = typedValue[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(typedValue) {
+				return nil
+			}
+			value = typedValue[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// lookupString finds the value at a dotted path and converts it to a string.
+func lookupString(value interface{}, path string) string {
+	switch typedValue := lookup(value, path).(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case json.Number:
+		return typedValue.String()
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case map[string]interface{}:
+		if text, ok := typedValue["#text"].(string); ok {
+			return text
+		}
+		return ""
+	default:
+		return fmt.Sprintf("%v", typedValue)
+	}
+}
+
+// lookupList finds the list at a dotted path.
+//
+// A single value is treated as a list with one item.
+func lookupList(value interface{}, path string) []interface{} {
+	switch typedValue := lookup(value, path).(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return typedValue
+	default:
+		return []interface{}{typedValue}
+	}
+}
@@ -0,0 +1,235 @@
+// Package cassette records HTTP traffic to disk and replays it later.
+//
+// A cassette is a directory with one JSON file per request/response pair (an "interaction").
+// Recording captures the real traffic with any credentials scrubbed and with the values of
+// sensitive JSON fields (such as names and patient information) redacted; the files are only
+// readable by their owner:
+//
+//	recorder := &cassette.Recorder{Directory: "testdata/incidents"}
+//	client.SetTransport(recorder)
+//
+// Replaying serves the recorded responses without touching the network:
+//
+//	replayer, err := cassette.NewReplayer("testdata/incidents")
+//	client.SetTransport(replayer)
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// Redacted replaces any scrubbed value.
+const Redacted = "REDACTED"
+
+// scrubbedHeaders are the request headers that are never written to a cassette.
+var scrubbedHeaders = []string{
+	"Authorization",
+	"Ocp-Apim-Subscription-Key",
+}
+
+// scrubbedFormFields are the form fields (such as in the token request) that are never written to a cassette.
+var scrubbedFormFields = []string{
+	"password",
+	"client_secret",
+}
+
+// scrubbedJSONFields are the top-level response fields (such as in the token response) that are never written to a cassette.
+var scrubbedJSONFields = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+}
+
+// redactedFields returns the fields to redact from JSON bodies.
+//
+// If there are none, then `emergencyreporting.DefaultRedactedFields` are used.
+func redactedFields(fields []string) []string {
+	if len(fields) == 0 {
+		return emergencyreporting.DefaultRedactedFields
+	}
+	return fields
+}
+
+// redactJSON returns the body with the values of any matching fields redacted, if it is JSON.
+//
+// The redacted body still decodes into the same types, so that it can be replayed.  Anything
+// else is returned as-is.
+func redactJSON(body string, fields []string) string {
+	contents, err := emergencyreporting.RedactJSONKeepingTypes([]byte(body), redactedFields(fields))
+	if err != nil {
+		return body
+	}
+	return string(contents)
+}
+
+// Interaction is a single request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load loads all of the interactions in the cassette directory, in the order that they were recorded.
+func Load(directory string) ([]*Interaction, error) {
+	filenames, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+
+	var interactions []*Interaction
+	for _, filename := range filenames {
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read '%s': %w", filename, err)
+		}
+		var interaction Interaction
+		err = json.Unmarshal(contents, &interaction)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s': %w", filename, err)
+		}
+		interactions = append(interactions, &interaction)
+	}
+	return interactions, nil
+}
+
+// save writes the interaction to the cassette directory as the given (1-indexed) entry.
+func save(directory string, index int, interaction *Interaction) error {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return err
+	}
+
+	// The name is mostly for humans; the number keeps the files in order.
+	path := "root"
+	if u, err := url.Parse(interaction.Request.URL); err == nil && strings.Trim(u.Path, "/") != "" {
+		path = strings.Trim(u.Path, "/")
+	}
+	path = strings.NewReplacer("/", "_", ".", "_").Replace(path)
+	if len(path) > 80 {
+		path = path[len(path)-80:]
+	}
+	filename := filepath.Join(directory, fmt.Sprintf("%04d-%s-%s.json", index, interaction.Request.Method, path))
+
+	contents, err := json.MarshalIndent(interaction, "" /*prefix*/, "\t" /*indent*/)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(contents, '\n'), 0600)
+}
+
+// scrubRequest returns the recorded form of the request, with any credentials removed and the redacted fields redacted.
+func scrubRequest(request *http.Request, body []byte, redacted []string) Request {
+	result := Request{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: request.Header.Clone(),
+		Body:   string(body),
+	}
+	for _, name := range scrubbedHeaders {
+		if result.Header.Get(name) != "" {
+			result.Header.Set(name, Redacted)
+		}
+	}
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(result.Body)
+		if err == nil {
+			for _, name := range scrubbedFormFields {
+				if values.Get(name) != "" {
+					values.Set(name, Redacted)
+				}
+			}
+			result.Body = values.Encode()
+		}
+	}
+	result.Body = redactJSON(result.Body, redacted)
+	return result
+}
+
+// scrubResponse returns the recorded form of the response, with any credentials removed and the redacted fields redacted.
+func scrubResponse(response *http.Response, body []byte, redacted []string) Response {
+	result := Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       string(body),
+	}
+	result.Header.Del("Set-Cookie")
+	result.Header.Del("Content-Length") // The body may change when it is scrubbed.
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) == nil {
+		changed := false
+		for _, name := range scrubbedJSONFields {
+			if _, ok := fields[name]; ok {
+				fields[name], _ = json.Marshal(Redacted)
+				changed = true
+			}
+		}
+		if changed {
+			contents, err := json.Marshal(fields)
+			if err == nil {
+				result.Body = string(contents)
+			}
+		}
+	}
+	result.Body = redactJSON(result.Body, redacted)
+	return result
+}
+
+// toResponse converts a recorded response into an `http.Response` for the request.
+func toResponse(request *http.Request, recorded Response) *http.Response {
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       request,
+	}
+}
+
+// readBody reads the request body and puts it back so that the request can still be sent.
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body.Close()
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,254 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/cassette"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+// record records a token request and the given calls against a fake server, returning the cassette directory
+// and a new client (without a token) for the same server.
+func record(t *testing.T, calls func(client *emergencyreporting.Client)) (string, *emergencyreporting.Client) {
+	directory, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+
+	server := ertest.NewServer()
+	defer server.Close()
+	incidentID := server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2021-0001"})
+	if incidentID != "1" {
+		t.Fatalf("Expected incident ID 1; got %s", incidentID)
+	}
+
+	client := server.Client()
+	client.SetTransport(&cassette.Recorder{Directory: directory})
+	tokenResponse, err := client.GenerateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Could not generate a token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	calls(client)
+	return directory, server.Client()
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	directory, client := record(t, func(recording *emergencyreporting.Client) {
+		for i := 0; i < 2; i++ {
+			_, err := recording.GetIncident(ctx, "1")
+			if err != nil {
+				t.Fatalf("Could not get the incident: %v", err)
+			}
+		}
+		_, err := recording.GetIncident(ctx, "2")
+		if err == nil {
+			t.Fatalf("Expected an error for a missing incident")
+		}
+	})
+	defer os.RemoveAll(directory)
+
+	interactions, err := cassette.Load(directory)
+	if err != nil {
+		t.Fatalf("Could not load the cassette: %v", err)
+	}
+	if len(interactions) != 4 {
+		t.Fatalf("Expected 4 interactions; got %d", len(interactions))
+	}
+	for index, interaction := range interactions {
+		if index == 0 {
+			continue // The token request has no credentials in its headers.
+		}
+		for _, name := range []string{"Authorization", "Ocp-Apim-Subscription-Key"} {
+			if value := interaction.Request.Header.Get(name); value != cassette.Redacted {
+				t.Errorf("Interaction %d: the %s header was not scrubbed: %q", index, name, value)
+			}
+		}
+	}
+	contents, err := ioutil.ReadFile(firstFile(t, directory))
+	if err != nil {
+		t.Fatalf("Could not read the token interaction: %v", err)
+	}
+	if strings.Contains(string(contents), "ertest-password") {
+		t.Errorf("The password was not scrubbed: %s", contents)
+	}
+
+	replayer, err := cassette.NewReplayer(directory)
+	if err != nil {
+		t.Fatalf("Could not load the cassette: %v", err)
+	}
+	client.SetTransport(replayer)
+
+	tokenResponse, err := client.GenerateToken(ctx)
+	if err != nil {
+		t.Fatalf("Could not replay the token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	for i := 0; i < 2; i++ {
+		incidentResponse, err := client.GetIncident(ctx, "1")
+		if err != nil {
+			t.Fatalf("Could not replay the incident: %v", err)
+		}
+		if incidentResponse.Incident.IncidentNumber != "2021-0001" {
+			t.Errorf("Expected incident number 2021-0001; got %s", incidentResponse.Incident.IncidentNumber)
+		}
+	}
+	_, err = client.GetIncident(ctx, "2")
+	if !errors.Is(err, emergencyreporting.ErrorNotFound) {
+		t.Errorf("Expected the recorded not found error; got %v", err)
+	}
+	if replayer.Remaining() != 0 {
+		t.Errorf("Expected every interaction to be used; %d remain", replayer.Remaining())
+	}
+
+	// Every interaction has been used, so a repeat doesn't match anything.
+	_, err = client.GetIncident(ctx, "1")
+	if err == nil || !strings.Contains(err.Error(), "no recorded interaction for GET "+client.Host+"/agencyincidents/incidents/1") {
+		t.Errorf("Expected a mismatch error; got %v", err)
+	}
+}
+
+func TestReplayMatcher(t *testing.T) {
+	ctx := context.Background()
+	directory, recorded := record(t, func(recording *emergencyreporting.Client) {
+		_, err := recording.GetIncident(ctx, "1")
+		if err != nil {
+			t.Fatalf("Could not get the incident: %v", err)
+		}
+	})
+	defer os.RemoveAll(directory)
+
+	rows := []struct {
+		description string
+		matcher     cassette.Matcher
+		host        string
+		success     bool
+	}{
+		{
+			description: "Default matcher",
+			success:     true,
+		},
+		{
+			description: "Default matcher with another host",
+			host:        "http://other.example.test",
+		},
+		{
+			description: "Path matcher with another host",
+			matcher:     cassette.MatchAll(cassette.MatchMethod, cassette.MatchPath),
+			host:        "http://other.example.test",
+			success:     true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			replayer, err := cassette.NewReplayer(directory)
+			if err != nil {
+				t.Fatalf("Could not load the cassette: %v", err)
+			}
+			replayer.Matcher = row.matcher
+			client := &emergencyreporting.Client{
+				Host:   row.host,
+				Token:  cassette.Redacted,
+				Logger: emergencyreporting.NullLogger{},
+			}
+			if client.Host == "" {
+				client.Host = recorded.Host
+			}
+			client.SetTransport(replayer)
+
+			_, err = client.GetIncident(ctx, "1")
+			if row.success && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !row.success && (err == nil || !strings.Contains(err.Error(), "no recorded interaction")) {
+				t.Errorf("Expected a mismatch error; got %v", err)
+			}
+		})
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	rows := []struct {
+		description string
+		matcher     cassette.Matcher
+		request     cassette.Request
+		recorded    cassette.Request
+		expected    bool
+	}{
+		{
+			description: "Same method",
+			matcher:     cassette.MatchMethod,
+			request:     cassette.Request{Method: "GET"},
+			recorded:    cassette.Request{Method: "GET"},
+			expected:    true,
+		},
+		{
+			description: "Different method",
+			matcher:     cassette.MatchMethod,
+			request:     cassette.Request{Method: "GET"},
+			recorded:    cassette.Request{Method: "DELETE"},
+		},
+		{
+			description: "Query order does not matter",
+			matcher:     cassette.MatchURL,
+			request:     cassette.Request{URL: "https://example.test/a?x=1&y=2"},
+			recorded:    cassette.Request{URL: "https://example.test/a?y=2&x=1"},
+			expected:    true,
+		},
+		{
+			description: "Different query",
+			matcher:     cassette.MatchURL,
+			request:     cassette.Request{URL: "https://example.test/a?x=1"},
+			recorded:    cassette.Request{URL: "https://example.test/a?x=2"},
+		},
+		{
+			description: "Different host",
+			matcher:     cassette.MatchURL,
+			request:     cassette.Request{URL: "https://one.example.test/a"},
+			recorded:    cassette.Request{URL: "https://two.example.test/a"},
+		},
+		{
+			description: "Path ignores the host",
+			matcher:     cassette.MatchPath,
+			request:     cassette.Request{URL: "https://one.example.test/a?x=1"},
+			recorded:    cassette.Request{URL: "http://two.example.test/a?x=1"},
+			expected:    true,
+		},
+		{
+			description: "Different body",
+			matcher:     cassette.MatchBody,
+			request:     cassette.Request{Body: `{"a":1}`},
+			recorded:    cassette.Request{Body: `{"a":2}`},
+		},
+		{
+			description: "All must match",
+			matcher:     cassette.DefaultMatcher,
+			request:     cassette.Request{Method: "POST", URL: "https://example.test/a", Body: `{"a":1}`},
+			recorded:    cassette.Request{Method: "POST", URL: "https://example.test/a", Body: `{"a":2}`},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := row.matcher(row.request, row.recorded)
+			if actual != row.expected {
+				t.Errorf("Expected %v; got %v", row.expected, actual)
+			}
+		})
+	}
+}
+
+// firstFile returns the first file in the directory.
+func firstFile(t *testing.T, directory string) string {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Could not list '%s': %v", directory, err)
+	}
+	return directory + "/" + files[0].Name()
+}
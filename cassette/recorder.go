@@ -0,0 +1,67 @@
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Recorder is an `http.RoundTripper` that sends requests on and saves each interaction to a cassette.
+type Recorder struct {
+	Directory string            // The cassette directory.  It is created if necessary.
+	Transport http.RoundTripper // The transport that actually sends the requests.  If nil, `http.DefaultTransport` is used.
+
+	RedactedFields []string // The fields to redact from recorded JSON bodies.  If empty, then `emergencyreporting.DefaultRedactedFields` will be used.
+
+	mutex sync.Mutex
+	count int
+}
+
+// RoundTrip sends the request and records the interaction.
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+
+	interaction := &Interaction{
+		Request:  scrubRequest(request, requestBody, r.RedactedFields),
+		Response: scrubResponse(response, responseBody, r.RedactedFields),
+	}
+
+	r.mutex.Lock()
+	if r.count == 0 {
+		// Continue an existing cassette rather than overwriting it.
+		existing, err := Load(r.Directory)
+		if err == nil {
+			r.count = len(existing)
+		}
+	}
+	r.count++
+	err = save(r.Directory, r.count, interaction)
+	r.mutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("could not save interaction: %w", err)
+	}
+
+	// The caller gets the real response, not the scrubbed one.
+	real := toResponse(request, Response{StatusCode: response.StatusCode, Header: response.Header, Body: string(responseBody)})
+	real.Status = response.Status
+	return real, nil
+}
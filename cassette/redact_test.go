@@ -0,0 +1,101 @@
+package cassette_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/cassette"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+func TestRecordRedactsPHI(t *testing.T) {
+	ctx := context.Background()
+	directory, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	server := ertest.NewServer()
+	defer server.Close()
+	pcrID := server.AddPatientCareReport(emergencyreporting.PatientCareReport{
+		PCRNumber: "PCR-1",
+		Patient:   &emergencyreporting.EMSPatient{FirstName: "Pat", LastName: "Smith", StreetAddress: "12 Elm St", Age: "51"},
+		Vitals:    []*emergencyreporting.EMSVitals{{}},
+		Narrative: "Pt found sitting.",
+	})
+	userID := server.AddUser(emergencyreporting.User{FullName: "Jane Doe", PrimaryEmail: "jane@example.com"})
+
+	client := newRecordingClient(t, server, &cassette.Recorder{Directory: directory})
+	reportResponse, err := client.GetPatientCareReport(ctx, pcrID)
+	if err != nil {
+		t.Fatalf("Could not get the patient care report: %v", err)
+	}
+	if reportResponse.PatientCareReport.Patient == nil || reportResponse.PatientCareReport.Patient.LastName != "Smith" {
+		t.Errorf("The caller should get the real response; got %+v", reportResponse.PatientCareReport.Patient)
+	}
+	_, err = client.GetUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("Could not get the user: %v", err)
+	}
+
+	filenames, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil || len(filenames) != 3 {
+		t.Fatalf("Expected 3 cassette files; got %d (%v)", len(filenames), err)
+	}
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatalf("Could not stat '%s': %v", filename, err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("'%s': expected mode 0600; got %v", filename, info.Mode().Perm())
+		}
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Could not read '%s': %v", filename, err)
+		}
+		for _, value := range []string{"Smith", "12 Elm St", "Pt found sitting.", "Jane Doe", "jane@example.com"} {
+			if strings.Contains(string(contents), value) {
+				t.Errorf("'%s' still has %q", filepath.Base(filename), value)
+			}
+		}
+	}
+
+	// The redacted cassette still replays.
+	replayer, err := cassette.NewReplayer(directory)
+	if err != nil {
+		t.Fatalf("Could not load the cassette: %v", err)
+	}
+	client = server.Client()
+	client.SetTransport(replayer)
+	tokenResponse, err := client.GenerateToken(ctx)
+	if err != nil {
+		t.Fatalf("Could not replay the token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	reportResponse, err = client.GetPatientCareReport(ctx, pcrID)
+	if err != nil {
+		t.Fatalf("Could not replay the patient care report: %v", err)
+	}
+	if reportResponse.PatientCareReport.PCRNumber != "PCR-1" || reportResponse.PatientCareReport.Patient != nil {
+		t.Errorf("Expected the report without the patient; got %+v", reportResponse.PatientCareReport)
+	}
+}
+
+// newRecordingClient returns a client for the server that records through the recorder and has a token.
+func newRecordingClient(t *testing.T, server *ertest.Server, recorder *cassette.Recorder) *emergencyreporting.Client {
+	client := server.Client()
+	client.SetTransport(recorder)
+	tokenResponse, err := client.GenerateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Could not generate a token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	return client
+}
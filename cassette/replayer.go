@@ -0,0 +1,134 @@
+package cassette
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Matcher returns whether a request matches a recorded request.
+//
+// The request has already been scrubbed the same way that recorded requests are.
+type Matcher func(request Request, recorded Request) bool
+
+// MatchMethod matches requests with the same method.
+func MatchMethod(request Request, recorded Request) bool {
+	return request.Method == recorded.Method
+}
+
+// MatchURL matches requests with the same URL; the order of the query parameters does not matter.
+func MatchURL(request Request, recorded Request) bool {
+	a, err := url.Parse(request.URL)
+	if err != nil {
+		return request.URL == recorded.URL
+	}
+	b, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return a.Scheme == b.Scheme && a.Host == b.Host && a.Path == b.Path && a.Query().Encode() == b.Query().Encode()
+}
+
+// MatchPath matches requests with the same path and query, ignoring the scheme and host.
+func MatchPath(request Request, recorded Request) bool {
+	a, err := url.Parse(request.URL)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return a.Path == b.Path && a.Query().Encode() == b.Query().Encode()
+}
+
+// MatchBody matches requests with the same body.
+func MatchBody(request Request, recorded Request) bool {
+	return request.Body == recorded.Body
+}
+
+// MatchAll combines matchers; a request must satisfy all of them.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(request Request, recorded Request) bool {
+		for _, matcher := range matchers {
+			if !matcher(request, recorded) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// DefaultMatcher matches the method, URL, and body.
+var DefaultMatcher = MatchAll(MatchMethod, MatchURL, MatchBody)
+
+// Replayer is an `http.RoundTripper` that answers requests from a cassette.
+//
+// Each recorded interaction is used once, in order; a request gets the first unused
+// interaction that matches it.  This keeps replays deterministic even when the same
+// request is made several times with different results.
+type Replayer struct {
+	Matcher        Matcher  // If nil, `DefaultMatcher` is used.
+	RedactedFields []string // The fields that were redacted when recording, so that request bodies match.  If empty, then `emergencyreporting.DefaultRedactedFields` will be used.
+
+	mutex        sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewReplayer loads the cassette in the directory.
+func NewReplayer(directory string) (*Replayer, error) {
+	interactions, err := Load(directory)
+	if err != nil {
+		return nil, err
+	}
+	if len(interactions) == 0 {
+		return nil, fmt.Errorf("no interactions in '%s'", directory)
+	}
+	r := &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+	return r, nil
+}
+
+// RoundTrip returns the recorded response for the request.
+func (r *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+	scrubbed := scrubRequest(request, body, r.RedactedFields)
+
+	matcher := r.Matcher
+	if matcher == nil {
+		matcher = DefaultMatcher
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for index, interaction := range r.interactions {
+		if r.used[index] || !matcher(scrubbed, interaction.Request) {
+			continue
+		}
+		r.used[index] = true
+		return toResponse(request, interaction.Response), nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s", scrubbed.Method, scrubbed.URL)
+}
+
+// Remaining returns the number of interactions that have not been used yet.
+func (r *Replayer) Remaining() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for _, used := range r.used {
+		if !used {
+			count++
+		}
+	}
+	return count
+}
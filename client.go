@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -23,6 +24,11 @@ var (
 	ErrorDuplicate = fmt.Errorf("Duplicate")
 	// ErrorNotFound represents a 404 "not found" error.
 	ErrorNotFound = fmt.Errorf("NotFound")
+	// ErrorBulkStopped represents a bulk item that was never started because an earlier item failed or the run was stopped.
+	ErrorBulkStopped = fmt.Errorf("BulkStopped")
+	// ErrorDryRun represents a request that was not sent because the client is in dry-run mode.
+	// The error is a `*DryRunError` with the request.
+	ErrorDryRun = fmt.Errorf("DryRun")
 )
 
 // Logger is the basic logger for this package.
@@ -52,15 +58,21 @@ type Client struct {
 	ClientSecret    string `json:"client_secret"`
 	AccountID       string `json:"account_id"`       // (New password authentication) This is the user's account ID.
 	UserID          string `json:"user_id"`          // (New password authentication) This is the user's ID.
-	TenantHost      string `json:"tenant_host"`      // (New password authentication) If present, use this instead of the default value.
+	TenantHost      string `json:"tenant_host"`      // (New password authentication) If present, use this instead of the default value.  If the protocol is not specified, "https://" is assumed.
 	TenantSegment   string `json:"tenant_segment"`   // (New password authentication) If present, use this instead of the default value.
 	Token           string `json:"token"`            // Required, but can be generated using the username, etc.
 	Host            string `json:"host"`             // If set, this will be used instead of "https://data.emergencyreporting.com".  If the protocol is not specified, "https://" is assumed.
 	SubscriptionKey string `json:"subscription_key"` // Required no matter what.
 
-	Logger Logger `json:"-"` // This is the Logger instance to use.  If empty, then the default one will be used.
+	Logger         Logger        `json:"-"` // This is the Logger instance to use.  If empty, then the default one will be used.
+	LeveledLogger  LeveledLogger `json:"-"` // If set, this is used instead of Logger.  Otherwise, Logger gets everything at the info level and above.
+	RedactedFields []string      `json:"-"` // The fields to redact from logged bodies.  If empty, then `DefaultRedactedFields` will be used.
+	HTTPClient     *http.Client  `json:"-"` // This is the HTTP client to use (for proxies, custom CAs, mTLS, etc.).  If empty, then a new one will be used.
+	Tracer         Tracer        `json:"-"` // If set, every operation gets a span.
+	Metrics        Metrics       `json:"-"` // If set, every operation records metrics.
+	DryRun         bool          `json:"-"` // If set, requests that would change something are logged and returned as a `*DryRunError` instead of being sent.  Reads still go through.
 
-	client http.Client
+	middleware []Middleware
 }
 
 // init makes sure that everything is initialized.
@@ -68,6 +80,26 @@ func (c *Client) init() {
 	if c.Logger == nil {
 		c.Logger = &DefaultLogger{}
 	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{}
+	}
+}
+
+// log returns the leveled logger to use.
+func (c *Client) log() LeveledLogger {
+	if c.LeveledLogger != nil {
+		return c.LeveledLogger
+	}
+	return NewPrintfLogger(c.Logger, LevelInfo)
+}
+
+// redact returns the body with the sensitive fields redacted, for logging.
+func (c *Client) redact(body []byte) string {
+	fields := c.RedactedFields
+	if len(fields) == 0 {
+		fields = DefaultRedactedFields
+	}
+	return Redact(body, fields)
 }
 
 // SetTimeout sets the timeout for any given request.
@@ -79,7 +111,24 @@ func (c *Client) init() {
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.init()
 
-	c.client.Timeout = timeout
+	c.HTTPClient.Timeout = timeout
+}
+
+// SetTransport sets the `http.RoundTripper` that sends every request, including the token requests.
+// By default, this uses `http.DefaultTransport`.
+//
+// This is how to record or replay traffic with the "cassette" package.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.init()
+
+	c.HTTPClient.Transport = transport
+}
+
+// SetHTTPClient sets the HTTP client to use for every request.
+//
+// This is the same as setting the `HTTPClient` field.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.HTTPClient = httpClient
 }
 
 // GenerateToken generates a new token.
@@ -125,7 +174,7 @@ func (c *Client) GenerateTokenLegacy(ctx context.Context) (*GenerateTokenRespons
 		"client_secret": {c.ClientSecret},
 	}
 
-	c.Logger.Printf("POST %s\n", targetURL)
+	c.log().Debug("Requesting a token", "method", http.MethodPost, "url", targetURL)
 
 	request, err := http.NewRequest(http.MethodPost, targetURL, strings.NewReader(values.Encode()))
 	if err != nil {
@@ -135,7 +184,7 @@ func (c *Client) GenerateTokenLegacy(ctx context.Context) (*GenerateTokenRespons
 
 	request = request.WithContext(ctx)
 
-	response, err := c.client.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		return nil, fmt.Errorf("could not post form: %w", err)
 	}
@@ -147,7 +196,7 @@ func (c *Client) GenerateTokenLegacy(ctx context.Context) (*GenerateTokenRespons
 	}
 
 	if response.StatusCode < 200 || response.StatusCode > 299 {
-		c.Logger.Printf("Body: %v\n", string(contents))
+		c.log().Debug("Token response", "status", response.StatusCode, "body", c.redact(contents))
 		return nil, fmt.Errorf("bad status code: %d", response.StatusCode)
 	}
 
@@ -178,7 +227,11 @@ func (c *Client) GenerateToken2020(ctx context.Context) (*GenerateTokenResponseV
 		tenantSegment = c.TenantSegment
 	}
 
-	targetURL := "https://" + tenantHost + "/" + tenantSegment + "/B2C_1A_PasswordGrant/oauth2/v2.0/token"
+	if !strings.HasPrefix(tenantHost, "http://") && !strings.HasPrefix(tenantHost, "https://") {
+		tenantHost = "https://" + tenantHost
+	}
+
+	targetURL := strings.TrimRight(tenantHost, "/") + "/" + tenantSegment + "/B2C_1A_PasswordGrant/oauth2/v2.0/token"
 
 	values := url.Values{
 		"grant_type":    {"password"},
@@ -192,7 +245,7 @@ func (c *Client) GenerateToken2020(ctx context.Context) (*GenerateTokenResponseV
 		"er_uid":        {c.UserID},
 	}
 
-	c.Logger.Printf("POST %s\n", targetURL)
+	c.log().Debug("Requesting a token", "method", http.MethodPost, "url", targetURL)
 
 	request, err := http.NewRequest(http.MethodPost, targetURL, strings.NewReader(values.Encode()))
 	if err != nil {
@@ -202,7 +255,7 @@ func (c *Client) GenerateToken2020(ctx context.Context) (*GenerateTokenResponseV
 
 	request = request.WithContext(ctx)
 
-	response, err := c.client.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		return nil, fmt.Errorf("could not post form: %w", err)
 	}
@@ -214,7 +267,7 @@ func (c *Client) GenerateToken2020(ctx context.Context) (*GenerateTokenResponseV
 	}
 
 	if response.StatusCode < 200 || response.StatusCode > 299 {
-		c.Logger.Printf("Body: %v\n", string(contents))
+		c.log().Debug("Token response", "status", response.StatusCode, "body", c.redact(contents))
 		return nil, fmt.Errorf("bad status code: %d", response.StatusCode)
 	}
 
@@ -231,6 +284,11 @@ func (c *Client) GenerateToken2020(ctx context.Context) (*GenerateTokenResponseV
 	return &parsedResponse, nil
 }
 
+// FilterEquals returns a "filter" option value that matches records whose field is equal to the given value.
+func FilterEquals(field string, value string) string {
+	return field + " eq '" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
 // RawOperation performs a raw HTTP request.
 func (c *Client) RawOperation(ctx context.Context, method string, targetURL string, options map[string]string, headers map[string]string, body []byte) (json.RawMessage, error) {
 	var response json.RawMessage
@@ -266,7 +324,7 @@ func (c *Client) internalRequest(ctx context.Context, method string, targetURL s
 		targetURL += "?" + queryParts.Encode()
 	}
 
-	c.Logger.Printf("%s %s\n", method, targetURL)
+	c.log().Debug("Request", "method", method, "url", targetURL)
 	request, err := http.NewRequest(method, targetURL, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("could not make request: %w", err)
@@ -277,10 +335,14 @@ func (c *Client) internalRequest(ctx context.Context, method string, targetURL s
 		request.Header.Set(key, value)
 	}
 
+	if c.DryRun && !readOnlyMethod(method) {
+		return c.dryRun(request, body)
+	}
+
 	// Set the context for the request.
 	request = request.WithContext(ctx)
 
-	response, err := c.client.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		return fmt.Errorf("could not perform operation: %w", err)
 	}
@@ -290,7 +352,7 @@ func (c *Client) internalRequest(ctx context.Context, method string, targetURL s
 	if err != nil {
 		return fmt.Errorf("could not read body: %w", err)
 	}
-	c.Logger.Printf("%s %s %d %d\n", method, targetURL, response.StatusCode, len(contents))
+	c.log().Info("Response", "method", method, "path", request.URL.Path, "status", response.StatusCode, "bytes", len(contents))
 
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		var errorType string
@@ -322,7 +384,7 @@ func (c *Client) internalRequest(ctx context.Context, method string, targetURL s
 				return ErrorNotFound
 			}
 
-			c.Logger.Printf("Error: %s\n", string(contents))
+			c.log().Debug("Error response", "status", response.StatusCode, "body", c.redact(contents))
 			return fmt.Errorf("bad status code: %d", response.StatusCode)
 		}
 		switch errorType {
@@ -410,7 +472,7 @@ func (c *Client) PostIncident(ctx context.Context, incident Incident) (*PostInci
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -445,6 +507,182 @@ func (c *Client) DeleteIncident(ctx context.Context, incidentID string) error {
 	return nil
 }
 
+// PatchIncident TODO
+// See: https://developer.emergencyreporting.com/docs/services/agency-incidents/operations/patchIncident?
+func (c *Client) PatchIncident(ctx context.Context, incidentID string, rowVersion string, payload PatchIncidentRequest) (*PatchIncidentResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyincidents/incidents/{incidentID}
+
+	targetURL := "/agencyincidents/incidents/" + url.PathEscape(incidentID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchIncidentResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the incident: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// UpsertIncident creates an incident, or updates the existing one with the same key.
+//
+// The key is the JSON name of the incident field used to find the existing incident; it must be
+// one of the `IncidentKey*` constants.  If an incident is found, then only the fields that differ
+// are patched.  Empty fields in the given incident are treated as "not specified" and are never
+// used to clear existing values.
+func (c *Client) UpsertIncident(ctx context.Context, incident Incident, key string) (*UpsertIncidentResponse, error) {
+	var keyValue string
+	switch key {
+	case IncidentKeyIncidentNumber:
+		keyValue = incident.IncidentNumber
+	case IncidentKeyPartnerIncidentNumber:
+		keyValue = incident.PartnerIncidentNumber
+	case IncidentKeyDispatchRunNumber:
+		keyValue = incident.DispatchRunNumber
+	default:
+		return nil, fmt.Errorf("invalid key: %s", key)
+	}
+	if keyValue == "" {
+		return nil, fmt.Errorf("the incident has no value for the key field: %s", key)
+	}
+
+	options := map[string]string{
+		"filter": FilterEquals(key, keyValue),
+	}
+	incidentsResponse, err := c.GetIncidents(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(incidentsResponse.Incidents) > 1 {
+		return nil, fmt.Errorf("found %d incidents with %s %q: %w", len(incidentsResponse.Incidents), key, keyValue, ErrorDuplicate)
+	}
+
+	if len(incidentsResponse.Incidents) == 0 {
+		postIncidentResponse, err := c.PostIncident(ctx, incident)
+		if err != nil {
+			return nil, err
+		}
+		return &UpsertIncidentResponse{
+			Action:     UpsertActionCreated,
+			IncidentID: postIncidentResponse.IncidentID,
+		}, nil
+	}
+
+	existing := incidentsResponse.Incidents[0]
+	patch, changed := DiffIncident(*existing, incident)
+	if !changed {
+		return &UpsertIncidentResponse{
+			Action:     UpsertActionUnchanged,
+			IncidentID: existing.IncidentID,
+			RowVersion: existing.RowVersion,
+		}, nil
+	}
+
+	patchIncidentResponse, err := c.PatchIncident(ctx, existing.IncidentID, existing.RowVersion, patch)
+	if err != nil {
+		return nil, err
+	}
+	return &UpsertIncidentResponse{
+		Action:     UpsertActionUpdated,
+		IncidentID: existing.IncidentID,
+		RowVersion: patchIncidentResponse.RowVersion,
+		Patch:      &patch,
+	}, nil
+}
+
+// DiffIncident returns the patch that turns the existing incident into the desired one.
+//
+// Empty fields in the desired incident are skipped.  This returns false if nothing changed.
+func DiffIncident(existing Incident, desired Incident) (PatchIncidentRequest, bool) {
+	var patch PatchIncidentRequest
+	fields := []struct {
+		existing string
+		desired  string
+		target   **string
+	}{
+		{existing.StationID, desired.StationID, &patch.StationID},
+		{existing.State, desired.State, &patch.State},
+		{existing.IncidentDateTime, desired.IncidentDateTime, &patch.IncidentDateTime},
+		{existing.FDID, desired.FDID, &patch.FDID},
+		{existing.IncidentNumber, desired.IncidentNumber, &patch.IncidentNumber},
+		{existing.PartnerIncidentNumber, desired.PartnerIncidentNumber, &patch.PartnerIncidentNumber},
+		{existing.DispatchRunNumber, desired.DispatchRunNumber, &patch.DispatchRunNumber},
+		{existing.IsComplete, desired.IsComplete, &patch.IsComplete},
+		{existing.IsReviewed, desired.IsReviewed, &patch.IsReviewed},
+		{existing.NarrativesRequired, desired.NarrativesRequired, &patch.NarrativesRequired},
+	}
+
+	changed := false
+	for _, field := range fields {
+		if field.desired == "" || field.desired == field.existing {
+			continue
+		}
+		value := field.desired
+		*field.target = &value
+		changed = true
+	}
+	return patch, changed
+}
+
+// GetIncidentDetails gets an incident along with all of its exposures.
+//
+// Each exposure is populated with its location, apparatuses, and crew members.
+// An exposure without a location is not an error; its `Location` will simply be nil.
+func (c *Client) GetIncidentDetails(ctx context.Context, incidentID string) (*Incident, error) {
+	incidentResponse, err := c.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	incident := incidentResponse.Incident
+	if incident == nil {
+		return nil, ErrorNotFound
+	}
+
+	exposuresResponse, err := c.GetIncidentExposures(ctx, incidentID, nil)
+	if err != nil {
+		return nil, err
+	}
+	incident.Exposures = exposuresResponse.Exposures
+
+	for _, exposure := range incident.Exposures {
+		locationResponse, err := c.GetExposureLocation(ctx, exposure.ExposureID)
+		if err != nil {
+			if !errors.Is(err, ErrorNotFound) {
+				return nil, err
+			}
+		} else {
+			exposure.Location = locationResponse.Location
+		}
+
+		apparatusesResponse, err := c.GetExposureApparatuses(ctx, exposure.ExposureID)
+		if err != nil {
+			return nil, err
+		}
+		exposure.Apparatuses = apparatusesResponse.Apparatuses
+
+		membersResponse, err := c.GetExposureMembers(ctx, exposure.ExposureID, nil)
+		if err != nil {
+			return nil, err
+		}
+		exposure.CrewMembers = membersResponse.CrewMembers
+	}
+
+	return incident, nil
+}
+
 // GetIncidentExposures TODO
 // See: https://developer.emergencyreporting.com/docs/services/agency-incidents/operations/IncidentsExposuresByIncidentIDGet?
 func (c *Client) GetIncidentExposures(ctx context.Context, incidentID string, options map[string]string) (*GetExposuresResponse, error) {
@@ -492,7 +730,7 @@ func (c *Client) PostIncidentExposure(ctx context.Context, incidentID string, ex
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -557,7 +795,7 @@ func (c *Client) PatchIncidentExposure(ctx context.Context, incidentID string, e
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -604,7 +842,7 @@ func (c *Client) PutExposureLocation(ctx context.Context, exposureID string, loc
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -674,7 +912,7 @@ func (c *Client) PostExposureApparatus(ctx context.Context, exposureID string, a
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -724,6 +962,34 @@ func (c *Client) GetExposureMembers(ctx context.Context, exposureID string, opti
 	return &parsedResponse, nil
 }
 
+// PostExposureMember adds a crew member to an exposure.
+func (c *Client) PostExposureMember(ctx context.Context, exposureID string, member CrewMember) (*PostExposureMemberResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyincidents/exposures/{exposureID}/crewmembers
+
+	targetURL := "/agencyincidents/exposures/" + url.PathEscape(exposureID) + "/crewmembers"
+
+	jsonInput, err := json.Marshal(member)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostExposureMemberResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the exposure member: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
 // GetExposureMemberRoles TODO
 // See: https://developer.emergencyreporting.com/docs/services/agency-incidents/operations/CrewmembersRolesByExposureUserIDGet?
 func (c *Client) GetExposureMemberRoles(ctx context.Context, exposureUserID string, options map[string]string) (*GetExposureMemberRolesResponse, error) {
@@ -760,6 +1026,25 @@ func (c *Client) GetUsers(ctx context.Context, options map[string]string) (*GetU
 	return &parsedResponse, nil
 }
 
+// GetAllUsers gets every page of users.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllUsers(ctx context.Context, options map[string]string) ([]*User, error) {
+	var users []*User
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetUsers(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		users = append(users, response.Users...)
+		return len(response.Users), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // GetUser TODO
 // See: https://developer.emergencyreporting.com/docs/services/agency-users/operations/V1UsersByUserIDGet?
 func (c *Client) GetUser(ctx context.Context, userID string) (*GetUserResponse, error) {
@@ -797,7 +1082,7 @@ func (c *Client) GetUserContactInfo(ctx context.Context, userID string) (*GetUse
 	return &parsedResponse, nil
 }
 
-// PatchUser TODO
+// PatchUser applies a JSON Patch to a user.  See `DiffUser` to make one from two users.
 // See: https://developer.emergencyreporting.com/docs/services/agency-users/operations/V1UsersByUserIDPatch?
 func (c *Client) PatchUser(ctx context.Context, userID string, rowVersion string, payload PatchUserRequest) (*PatchUserResponse, error) {
 	c.init()
@@ -806,11 +1091,16 @@ func (c *Client) PatchUser(ctx context.Context, userID string, rowVersion string
 
 	targetURL := "/agencyusers/users/" + url.PathEscape(userID)
 
+	err := payload.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
 	jsonInput, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("could not create JSON: %w", err)
 	}
-	c.Logger.Printf("JSON input: %s\n", string(jsonInput))
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
 
 	headers := map[string]string{
 		"Content-Type": "application/json",
@@ -860,3 +1150,22 @@ func (c *Client) GetApparatuses(ctx context.Context, options map[string]string)
 
 	return &parsedResponse, nil
 }
+
+// GetAllApparatuses gets every page of apparatuses.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllApparatuses(ctx context.Context, options map[string]string) ([]*Apparatus, error) {
+	var apparatuses []*Apparatus
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetApparatuses(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		apparatuses = append(apparatuses, response.Apparatuses...)
+		return len(response.Apparatuses), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return apparatuses, nil
+}
@@ -0,0 +1,57 @@
+package emergencyreporting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffIncident(t *testing.T) {
+	stringPointer := func(value string) *string {
+		return &value
+	}
+
+	existing := Incident{
+		IncidentNumber: "2021-0001",
+		StationID:      "1",
+		IsComplete:     "0",
+	}
+
+	rows := []struct {
+		description string
+		desired     Incident
+		patch       PatchIncidentRequest
+		changed     bool
+	}{
+		{
+			description: "Same",
+			desired:     existing,
+		},
+		{
+			description: "Empty fields are skipped",
+			desired:     Incident{},
+		},
+		{
+			description: "Changed",
+			desired:     Incident{IncidentNumber: "2021-0001", StationID: "2", FDID: "12345"},
+			patch:       PatchIncidentRequest{StationID: stringPointer("2"), FDID: stringPointer("12345")},
+			changed:     true,
+		},
+		{
+			description: "Zero is a value",
+			desired:     Incident{IsComplete: "1", NarrativesRequired: "0"},
+			patch:       PatchIncidentRequest{IsComplete: stringPointer("1"), NarrativesRequired: stringPointer("0")},
+			changed:     true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			patch, changed := DiffIncident(existing, row.desired)
+			if changed != row.changed {
+				t.Errorf("Expected changed to be %v", row.changed)
+			}
+			if !reflect.DeepEqual(patch, row.patch) {
+				t.Errorf("Wrong patch: %+v", patch)
+			}
+		})
+	}
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// Batch result statuses.
+const (
+	batchStatusOK      = "ok"
+	batchStatusError   = "error"
+	batchStatusSkipped = "skipped"
+	batchStatusDryRun  = "dry-run"
+)
+
+// batchOperation is a single line in a batch file.
+type batchOperation struct {
+	Operation string          `json:"op"`                // Such as "incident.create".
+	Args      []string        `json:"args,omitempty"`    // The positional arguments, such as the incident ID.
+	Payload   json.RawMessage `json:"payload,omitempty"` // The JSON payload, if the operation takes one.
+	Key       string          `json:"key,omitempty"`     // The key field for "incident.upsert".
+}
+
+// batchResult is the result of a single line in a batch file.
+type batchResult struct {
+	Number    int         `json:"number"` // The operation's number, counting from 1 and not counting blank lines or comments; this is what "$N" refers to.
+	Line      int         `json:"line"`   // The line in the file.
+	Operation string      `json:"op,omitempty"`
+	Status    string      `json:"status"` // One of the `batchStatus*` constants.
+	Args      []string    `json:"args,omitempty"`
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// batchHandler runs a batch operation.
+type batchHandler struct {
+	args        string // The names of the arguments, for the error messages; optional arguments are in brackets.
+	minimumArgs int
+	maximumArgs int
+	run         func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error)
+}
+
+// batchHandlers are all of the batch operations, by name.
+var batchHandlers = map[string]batchHandler{
+	"incident.create": {
+		args: "", minimumArgs: 0, maximumArgs: 0,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var incident emergencyreporting.Incident
+			if err := decodePayload(operation, &incident); err != nil {
+				return nil, err
+			}
+			return client.PostIncident(ctx, incident)
+		},
+	},
+	"incident.delete": {
+		args: "<incident-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			return nil, client.DeleteIncident(ctx, operation.Args[0])
+		},
+	},
+	"incident.get": {
+		args: "<incident-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			response, err := client.GetIncident(ctx, operation.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return response.Incident, nil
+		},
+	},
+	"incident.patch": {
+		args: "<incident-id> [<row-version>]", minimumArgs: 1, maximumArgs: 2,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var payload emergencyreporting.PatchIncidentRequest
+			if err := decodePayload(operation, &payload); err != nil {
+				return nil, err
+			}
+			rowVersion, err := batchRowVersion(operation, 1, func() (string, error) {
+				response, err := client.GetIncident(ctx, operation.Args[0])
+				if err != nil {
+					return "", err
+				}
+				if response.Incident == nil {
+					return "", emergencyreporting.ErrorNotFound
+				}
+				return response.Incident.RowVersion, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return client.PatchIncident(ctx, operation.Args[0], rowVersion, payload)
+		},
+	},
+	"incident.upsert": {
+		args: "", minimumArgs: 0, maximumArgs: 0,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var incident emergencyreporting.Incident
+			if err := decodePayload(operation, &incident); err != nil {
+				return nil, err
+			}
+			key := operation.Key
+			if key == "" {
+				key = emergencyreporting.IncidentKeyIncidentNumber
+			}
+			return client.UpsertIncident(ctx, incident, key)
+		},
+	},
+	"incident-exposure.create": {
+		args: "<incident-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var exposure emergencyreporting.Exposure
+			if err := decodePayload(operation, &exposure); err != nil {
+				return nil, err
+			}
+			return client.PostIncidentExposure(ctx, operation.Args[0], exposure)
+		},
+	},
+	"incident-exposure.delete": {
+		args: "<incident-id> <exposure-id>", minimumArgs: 2, maximumArgs: 2,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			return nil, client.DeleteIncidentExposure(ctx, operation.Args[0], operation.Args[1])
+		},
+	},
+	"incident-exposure.get": {
+		args: "<incident-id> <exposure-id>", minimumArgs: 2, maximumArgs: 2,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			response, err := client.GetIncidentExposure(ctx, operation.Args[0], operation.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			return response.Exposure, nil
+		},
+	},
+	"incident-exposure.patch": {
+		args: "<incident-id> <exposure-id> [<row-version>]", minimumArgs: 2, maximumArgs: 3,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var payload emergencyreporting.PatchExposureRequest
+			if err := decodePayload(operation, &payload); err != nil {
+				return nil, err
+			}
+			rowVersion, err := batchRowVersion(operation, 2, func() (string, error) {
+				response, err := client.GetIncidentExposure(ctx, operation.Args[0], operation.Args[1])
+				if err != nil {
+					return "", err
+				}
+				if response.Exposure == nil {
+					return "", emergencyreporting.ErrorNotFound
+				}
+				return response.Exposure.RowVersion, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return client.PatchIncidentExposure(ctx, operation.Args[0], operation.Args[1], rowVersion, payload)
+		},
+	},
+	"exposure-location.get": {
+		args: "<exposure-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			response, err := client.GetExposureLocation(ctx, operation.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return response.Location, nil
+		},
+	},
+	"exposure-location.put": {
+		args: "<exposure-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var location emergencyreporting.ExposureLocation
+			if err := decodePayload(operation, &location); err != nil {
+				return nil, err
+			}
+			if location.RowVersion == "" {
+				response, err := client.GetExposureLocation(ctx, operation.Args[0])
+				if err != nil {
+					return nil, err
+				}
+				if response.Location == nil {
+					return nil, emergencyreporting.ErrorNotFound
+				}
+				location.RowVersion = response.Location.RowVersion
+			}
+			return client.PutExposureLocation(ctx, operation.Args[0], location)
+		},
+	},
+	"exposure-apparatus.create": {
+		args: "<exposure-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var apparatus emergencyreporting.ExposureApparatus
+			if err := decodePayload(operation, &apparatus); err != nil {
+				return nil, err
+			}
+			return client.PostExposureApparatus(ctx, operation.Args[0], apparatus)
+		},
+	},
+	"user.get": {
+		args: "<user-id>", minimumArgs: 1, maximumArgs: 1,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			response, err := client.GetUser(ctx, operation.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return response.User, nil
+		},
+	},
+	"user.patch": {
+		args: "<user-id> [<row-version>]", minimumArgs: 1, maximumArgs: 2,
+		run: func(ctx context.Context, client *emergencyreporting.Client, operation *batchOperation) (interface{}, error) {
+			var payload emergencyreporting.PatchUserRequest
+			if err := decodePayload(operation, &payload); err != nil {
+				return nil, err
+			}
+			rowVersion, err := batchRowVersion(operation, 1, func() (string, error) {
+				response, err := client.GetUser(ctx, operation.Args[0])
+				if err != nil {
+					return "", err
+				}
+				if response.User == nil {
+					return "", emergencyreporting.ErrorNotFound
+				}
+				return response.User.RowVersion, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return client.PatchUser(ctx, operation.Args[0], rowVersion, payload)
+		},
+	},
+}
+
+// decodePayload decodes the operation's payload into the target.
+func decodePayload(operation *batchOperation, target interface{}) error {
+	if len(operation.Payload) == 0 {
+		return fmt.Errorf("missing payload")
+	}
+	err := json.Unmarshal(operation.Payload, target)
+	if err != nil {
+		return fmt.Errorf("could not parse payload: %w", err)
+	}
+	return nil
+}
+
+// batchRowVersion returns the row version argument at the index, or looks it up if it was not given.
+func batchRowVersion(operation *batchOperation, index int, lookup func() (string, error)) (string, error) {
+	if len(operation.Args) > index && operation.Args[index] != "" {
+		return operation.Args[index], nil
+	}
+	rowVersion, err := lookup()
+	if err != nil {
+		return "", fmt.Errorf("could not look up the row version: %w", err)
+	}
+	return rowVersion, nil
+}
+
+// errBatchDependency is returned when an operation refers to an operation that did not succeed.
+var errBatchDependency = errors.New("refers to an operation that did not succeed")
+
+// batchReferencePattern matches references to earlier results, such as "$1.incidentID".
+//
+// The number is the operation's number (see `batchResult.Number`), not its line in the file.
+var batchReferencePattern = regexp.MustCompile(`\$([0-9]+)((?:\.[A-Za-z0-9_-]+)+)`)
+
+// resolveReferences replaces the references in the value with the results of the earlier operations.
+//
+// A string that is only a reference becomes the referenced value (keeping its type); references
+// inside of a longer string are replaced by their text.
+func resolveReferences(value interface{}, results map[int]*batchResult) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case string:
+		matches := batchReferencePattern.FindAllStringSubmatchIndex(typedValue, -1)
+		if len(matches) == 0 {
+			return typedValue, nil
+		}
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(typedValue) {
+			return lookupReference(typedValue, results)
+		}
+		var err error
+		resolved := batchReferencePattern.ReplaceAllStringFunc(typedValue, func(reference string) string {
+			value, lookupErr := lookupReference(reference, results)
+			if lookupErr != nil {
+				err = lookupErr
+				return reference
+			}
+			return fmt.Sprintf("%v", value)
+		})
+		return resolved, err
+	case map[string]interface{}:
+		for key, child := range typedValue {
+			resolved, err := resolveReferences(child, results)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[key] = resolved
+		}
+	case []interface{}:
+		for index, child := range typedValue {
+			resolved, err := resolveReferences(child, results)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[index] = resolved
+		}
+	}
+	return value, nil
+}
+
+// lookupReference returns the value of a single reference, such as "$1.incidentID".
+func lookupReference(reference string, results map[int]*batchResult) (interface{}, error) {
+	match := batchReferencePattern.FindStringSubmatch(reference)
+	number, _ := strconv.Atoi(match[1])
+	result, ok := results[number]
+	if !ok {
+		return nil, fmt.Errorf("%s refers to operation %d, which has not run", reference, number)
+	}
+	if result.Status == batchStatusDryRun {
+		// There is no result yet, so the reference is left as-is.
+		return reference, nil
+	}
+	if result.Status != batchStatusOK {
+		return nil, fmt.Errorf("%s: %w", reference, errBatchDependency)
+	}
+
+	contents, err := json.Marshal(result.Result)
+	if err != nil {
+		return nil, err
+	}
+	var current interface{}
+	err = json.Unmarshal(contents, &current)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(match[2], "."), ".") {
+		switch typedCurrent := current.(type) {
+		case map[string]interface{}:
+			current = typedCurrent[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(typedCurrent) {
+				current = nil
+			} else {
+				current = typedCurrent[index]
+			}
+		default:
+			current = nil
+		}
+		if current == nil {
+			return nil, fmt.Errorf("%s: operation %d has no %s", reference, number, strings.TrimPrefix(match[2], "."))
+		}
+	}
+	return current, nil
+}
+
+// pendingReferences returns whether the text refers to an operation that only had a dry run.
+func pendingReferences(text string, results map[int]*batchResult) bool {
+	for _, match := range batchReferencePattern.FindAllStringSubmatch(text, -1) {
+		number, _ := strconv.Atoi(match[1])
+		if result, ok := results[number]; ok && result.Status == batchStatusDryRun {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareBatchOperation parses a line and resolves its references.
+func prepareBatchOperation(text string, results map[int]*batchResult) (*batchOperation, *batchHandler, error) {
+	var operation batchOperation
+	err := json.Unmarshal([]byte(text), &operation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse line: %w", err)
+	}
+	handler, ok := batchHandlers[operation.Operation]
+	if !ok {
+		return &operation, nil, fmt.Errorf("unknown operation: %q", operation.Operation)
+	}
+	if len(operation.Args) < handler.minimumArgs || len(operation.Args) > handler.maximumArgs {
+		return &operation, nil, fmt.Errorf("%s takes the arguments: %s", operation.Operation, handler.args)
+	}
+
+	for index, arg := range operation.Args {
+		resolved, err := resolveReferences(arg, results)
+		if err != nil {
+			return &operation, nil, err
+		}
+		operation.Args[index] = fmt.Sprintf("%v", resolved)
+	}
+	if len(operation.Payload) > 0 {
+		var payload interface{}
+		err = json.Unmarshal(operation.Payload, &payload)
+		if err != nil {
+			return &operation, nil, fmt.Errorf("could not parse payload: %w", err)
+		}
+		payload, err = resolveReferences(payload, results)
+		if err != nil {
+			return &operation, nil, err
+		}
+		operation.Payload, err = json.Marshal(payload)
+		if err != nil {
+			return &operation, nil, err
+		}
+	}
+	return &operation, &handler, nil
+}
+
+func doBatchRun(cmd *cobra.Command, args []string) {
+	continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+
+	filename := args[0]
+	var input io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			logrus.Errorf("Could not open '%s': [%T] %v", filename, err, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var output io.Writer = os.Stdout
+	if resultsFilename := cmd.Flag("results").Value.String(); resultsFilename != "-" {
+		file, err := os.Create(resultsFilename)
+		if err != nil {
+			logrus.Errorf("Could not create '%s': [%T] %v", resultsFilename, err, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	client := makeClient(cmd)
+
+	counts, err := runBatch(context.Background(), client, input, output, continueOnError)
+	if err != nil {
+		logrus.Errorf("Could not run '%s': [%T] %v", filename, err, err)
+		os.Exit(1)
+	}
+
+	var summary []string
+	for status, count := range counts {
+		summary = append(summary, fmt.Sprintf("%d %s", count, status))
+	}
+	sort.Strings(summary)
+	fmt.Fprintf(os.Stderr, "batch: %s\n", strings.Join(summary, ", "))
+
+	if counts[batchStatusError] > 0 || counts[batchStatusSkipped] > 0 {
+		os.Exit(1)
+	}
+}
+
+// runBatch runs the operations in the input, writing a result line to the output for each one.
+//
+// This stops at the first failed operation unless `continueOnError` is set.  It returns the number
+// of operations with each status; the error is only for problems reading the input or writing the output.
+func runBatch(ctx context.Context, client *emergencyreporting.Client, input io.Reader, output io.Writer, continueOnError bool) (map[string]int, error) {
+	encoder := json.NewEncoder(output)
+	dryRun := client.DryRun
+
+	results := map[int]*batchResult{} // By operation number.
+	counts := map[string]int{}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	line := 0
+	number := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		number++
+
+		result := &batchResult{Number: number, Line: line}
+		operation, handler, err := prepareBatchOperation(text, results)
+		if operation != nil {
+			result.Operation = operation.Operation
+			result.Args = operation.Args
+		}
+		switch {
+		case errors.Is(err, errBatchDependency):
+			result.Status = batchStatusSkipped
+			result.Error = err.Error()
+		case err != nil:
+			result.Status = batchStatusError
+			result.Error = err.Error()
+		case dryRun && pendingReferences(text, results):
+			// This depends on something that was not created, so there is nothing to show but the payload.
+			result.Status = batchStatusDryRun
+			if len(operation.Payload) > 0 {
+				result.Result = operation.Payload
+			}
+		default:
+			value, err := handler.run(ctx, client, operation)
+			if request := dryRunRequest(err); request != nil {
+				result.Status = batchStatusDryRun
+				result.Result = request
+			} else if err != nil {
+				result.Status = batchStatusError
+				result.Error = err.Error()
+			} else {
+				result.Status = batchStatusOK
+				result.Result = value
+			}
+		}
+		results[number] = result
+		counts[result.Status]++
+
+		err = encoder.Encode(result)
+		if err != nil {
+			return counts, fmt.Errorf("could not write result: %w", err)
+		}
+		if result.Status == batchStatusError {
+			logrus.Errorf("Operation %d on line %d (%s): %s", number, line, result.Operation, result.Error)
+			if !continueOnError {
+				break
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return counts, fmt.Errorf("could not read: %w", err)
+	}
+	return counts, nil
+}
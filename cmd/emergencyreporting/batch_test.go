@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+// newTestClient returns a client for the server that already has a token.
+func newTestClient(t *testing.T, server *ertest.Server) *emergencyreporting.Client {
+	client := server.Client()
+	tokenResponse, err := client.GenerateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Could not generate a token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	return client
+}
+
+func TestRunBatch(t *testing.T) {
+	input := strings.Join([]string{
+		`# Create an incident and read it back.`,
+		`{"op": "incident.create", "payload": {"incidentNumber": "2026-0001"}}`,
+		``,
+		`{"op": "incident.get", "args": ["999999"]}`,
+		`{"op": "incident.get", "args": ["$2.incidentID"]}`,
+		`{"op": "incident.get", "args": ["$1.incidentID"]}`,
+	}, "\n")
+
+	rows := []struct {
+		description     string
+		continueOnError bool
+		statuses        []string
+		counts          map[string]int
+	}{
+		{
+			description: "Stop on error",
+			statuses:    []string{batchStatusOK, batchStatusError},
+			counts:      map[string]int{batchStatusOK: 1, batchStatusError: 1},
+		},
+		{
+			description:     "Continue on error",
+			continueOnError: true,
+			statuses:        []string{batchStatusOK, batchStatusError, batchStatusSkipped, batchStatusOK},
+			counts:          map[string]int{batchStatusOK: 2, batchStatusError: 1, batchStatusSkipped: 1},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			server := ertest.NewServer()
+			defer server.Close()
+			client := newTestClient(t, server)
+
+			var output bytes.Buffer
+			counts, err := runBatch(context.Background(), client, strings.NewReader(input), &output, row.continueOnError)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(counts, row.counts) {
+				t.Errorf("Expected counts %v; got %v", row.counts, counts)
+			}
+
+			var results []batchResult
+			decoder := json.NewDecoder(&output)
+			for decoder.More() {
+				var result batchResult
+				if err := decoder.Decode(&result); err != nil {
+					t.Fatalf("Could not decode a result: %v", err)
+				}
+				results = append(results, result)
+			}
+			var statuses []string
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+			if !reflect.DeepEqual(statuses, row.statuses) {
+				t.Fatalf("Expected statuses %v; got %v", row.statuses, statuses)
+			}
+
+			if results[1].Number != 2 || results[1].Line != 4 || results[1].Error == "" {
+				t.Errorf("Expected operation 2 on line 4 to have an error; got %+v", results[1])
+			}
+			if len(results) == 4 {
+				incidentID := results[0].Result.(map[string]interface{})["incidentID"]
+				if incident := server.Incident(incidentID.(string)); incident == nil || incident.IncidentNumber != "2026-0001" {
+					t.Errorf("The incident was not created: %+v", incident)
+				}
+				if results[3].Args[0] != incidentID {
+					t.Errorf("Expected the reference to resolve to %v; got %v", incidentID, results[3].Args)
+				}
+			}
+		})
+	}
+}
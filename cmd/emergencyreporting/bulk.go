@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// runBulk runs the operation on every item using the "--concurrency" flag.
+//
+// Failures are logged as they happen, and if there is more than one item, a summary is
+// written to stderr at the end.  Interrupting the program stops any items that have not
+// started yet and lets the running ones finish; interrupting it again aborts them.
+func runBulk(cmd *cobra.Command, description string, items []string, operation emergencyreporting.BulkOperation) *emergencyreporting.BulkResults {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	return runBulkUntil(cmd, description, items, operation, signals)
+}
+
+// runBulkUntil is `runBulk` with the interrupts coming from the given channel.
+func runBulkUntil(cmd *cobra.Command, description string, items []string, operation emergencyreporting.BulkOperation, signals <-chan os.Signal) *emergencyreporting.BulkResults {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+	// The requests get their own context so that stopping the scheduling doesn't abort them.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := make(chan struct{})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-signals:
+			logrus.Warnf("Interrupted; waiting for the running items to finish (interrupt again to abort them).")
+			close(stop)
+		case <-done:
+			return
+		}
+		select {
+		case <-signals:
+			logrus.Warnf("Interrupted again; aborting the running items.")
+			cancel()
+		case <-done:
+		}
+	}()
+
+	executor := &emergencyreporting.BulkExecutor{
+		Concurrency: concurrency,
+		Stop:        stop,
+		Progress: func(result *emergencyreporting.BulkResult, completed int, total int) {
+			if result.Err != nil {
+				logrus.Errorf("Could not %s '%s': [%T] %v", description, result.Item, result.Err, result.Err)
+				return
+			}
+			logrus.Debugf("Finished %s '%s' (%d of %d).", description, result.Item, completed, total)
+		},
+	}
+	results := executor.Run(ctx, items, operation)
+
+	if len(items) > 1 {
+		fmt.Fprintf(os.Stderr, "%s: %d succeeded, %d failed, %d total\n", description, results.Succeeded(), results.Failed(), len(items))
+	}
+	return results
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func TestRunBulkInterrupted(t *testing.T) {
+	rows := []struct {
+		description string
+		interrupts  int
+		first       error // The error for the item that is running when the interrupts arrive.
+		rest        error // The error for the items after it.
+	}{
+		{
+			description: "Once",
+			interrupts:  1,
+			rest:        emergencyreporting.ErrorBulkStopped,
+		},
+		{
+			description: "Twice",
+			interrupts:  2,
+			first:       context.Canceled,
+			rest:        context.Canceled,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Int("concurrency", 1, "")
+
+			signals := make(chan os.Signal, row.interrupts)
+			results := runBulkUntil(cmd, "test", []string{"1", "2", "3"}, func(ctx context.Context, item string) (interface{}, error) {
+				if item != "1" {
+					return item, nil
+				}
+				for i := 0; i < row.interrupts; i++ {
+					signals <- os.Interrupt
+				}
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(50 * time.Millisecond):
+					return item, nil
+				}
+			}, signals)
+
+			if !errors.Is(results.Results[0].Err, row.first) {
+				t.Errorf("Item 1: expected %v; got %v", row.first, results.Results[0].Err)
+			}
+			for _, result := range results.Results[1:] {
+				if !errors.Is(result.Err, row.rest) {
+					t.Errorf("Item %s: expected %v; got %v", result.Item, row.rest, result.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// redactedValue replaces secrets in any configuration output.
+const redactedValue = "REDACTED"
+
+// environmentPrefix is the prefix for all of the environment variable overrides.
+const environmentPrefix = "ER_"
+
+// profileConfig is the configuration for a single profile.
+//
+// All of the client fields are available, along with commands that can produce the secrets.
+type profileConfig struct {
+	emergencyreporting.Client
+
+	PasswordCommand     string `json:"password_command,omitempty"`      // If set (and there is no password), the output of this command is the password.
+	ClientSecretCommand string `json:"client_secret_command,omitempty"` // If set (and there is no client secret), the output of this command is the client secret.
+}
+
+// configFile is the structure of the configuration file.
+//
+// Any client fields at the top level apply to every profile; each profile then overrides them.
+// A file without any profiles is a single, unnamed profile.
+type configFile struct {
+	DefaultProfile string                     `json:"default_profile,omitempty"`
+	Profiles       map[string]json.RawMessage `json:"profiles,omitempty"`
+}
+
+// profileName returns the name of the profile to use.
+func profileName(cmd *cobra.Command) string {
+	profile := cmd.Flag("profile").Value.String()
+	if profile == "" {
+		profile = os.Getenv(environmentPrefix + "PROFILE")
+	}
+	return profile
+}
+
+// loadConfig loads the profile configuration from the config file and the environment.
+//
+// The secret commands are not run; see `resolveSecrets`.
+func loadConfig(cmd *cobra.Command) (*profileConfig, error) {
+	var config profileConfig
+
+	configFilename := cmd.Flag("config").Value.String()
+	profile := profileName(cmd)
+
+	configBytes, err := ioutil.ReadFile(configFilename)
+	if err != nil {
+		// The default config file is optional, since everything can come from the environment.
+		if !os.IsNotExist(err) || cmd.Flag("config").Changed {
+			return nil, fmt.Errorf("could not read '%s': %w", configFilename, err)
+		}
+		if profile != "" {
+			return nil, fmt.Errorf("could not find profile '%s': '%s' does not exist", profile, configFilename)
+		}
+	} else {
+		err = json.Unmarshal(configBytes, &config)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s': %w", configFilename, err)
+		}
+
+		var file configFile
+		err = json.Unmarshal(configBytes, &file)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s': %w", configFilename, err)
+		}
+
+		if profile == "" {
+			profile = file.DefaultProfile
+		}
+		if profile != "" {
+			profileBytes, ok := file.Profiles[profile]
+			if !ok {
+				return nil, fmt.Errorf("could not find profile '%s' in '%s'", profile, configFilename)
+			}
+			err = json.Unmarshal(profileBytes, &config)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse profile '%s': %w", profile, err)
+			}
+		}
+	}
+
+	for _, field := range configFields(&config) {
+		value, ok := os.LookupEnv(field.environment)
+		if ok {
+			field.value.SetString(value)
+		}
+	}
+
+	return &config, nil
+}
+
+// configField is a single string field in the profile configuration.
+type configField struct {
+	name        string        // The JSON name, such as "client_id".
+	environment string        // The environment variable, such as "ER_CLIENT_ID".
+	value       reflect.Value // The settable value.
+}
+
+// configFields returns all of the string fields in the profile configuration.
+func configFields(config *profileConfig) []configField {
+	var fields []configField
+
+	var walk func(value reflect.Value)
+	walk = func(value reflect.Value) {
+		for i := 0; i < value.NumField(); i++ {
+			structField := value.Type().Field(i)
+			if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+				walk(value.Field(i))
+				continue
+			}
+			if structField.PkgPath != "" || structField.Type.Kind() != reflect.String {
+				continue
+			}
+			name := strings.Split(structField.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			fields = append(fields, configField{
+				name:        name,
+				environment: environmentPrefix + strings.ToUpper(name),
+				value:       value.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(config).Elem())
+
+	return fields
+}
+
+// resolveSecrets runs the secret commands for any secrets that are not already set.
+func resolveSecrets(config *profileConfig) error {
+	if config.Password == "" && config.PasswordCommand != "" {
+		value, err := runSecretCommand(config.PasswordCommand)
+		if err != nil {
+			return fmt.Errorf("could not run password_command: %w", err)
+		}
+		config.Password = value
+	}
+	if config.ClientSecret == "" && config.ClientSecretCommand != "" {
+		value, err := runSecretCommand(config.ClientSecretCommand)
+		if err != nil {
+			return fmt.Errorf("could not run client_secret_command: %w", err)
+		}
+		config.ClientSecret = value
+	}
+	return nil
+}
+
+// runSecretCommand runs the command with the shell and returns its output, minus the trailing newline.
+func runSecretCommand(command string) (string, error) {
+	var stderr bytes.Buffer
+	shellCommand := exec.Command("sh", "-c", command)
+	shellCommand.Stdin = os.Stdin
+	shellCommand.Stderr = &stderr
+	output, err := shellCommand.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// redactedConfig returns the profile configuration as a map, with any secrets redacted.
+func redactedConfig(config *profileConfig) map[string]string {
+	result := map[string]string{}
+	for _, field := range configFields(config) {
+		value := field.value.String()
+		if value == "" {
+			continue
+		}
+		switch field.name {
+		case "password", "client_secret", "token", "subscription_key":
+			value = redactedValue
+		}
+		result[field.name] = value
+	}
+	return result
+}
+
+func doConfigInit(cmd *cobra.Command, args []string) {
+	configFilename := cmd.Flag("config").Value.String()
+	profile := profileName(cmd)
+	if profile == "" {
+		profile = "default"
+	}
+	force, _ := cmd.Flags().GetBool("force")
+
+	document := map[string]interface{}{}
+	configBytes, err := ioutil.ReadFile(configFilename)
+	if err == nil {
+		err = json.Unmarshal(configBytes, &document)
+		if err != nil {
+			logrus.Errorf("Could not parse '%s': [%T] %v", configFilename, err, err)
+			os.Exit(1)
+		}
+	} else if !os.IsNotExist(err) {
+		logrus.Errorf("Could not read '%s': [%T] %v", configFilename, err, err)
+		os.Exit(1)
+	}
+
+	profiles, _ := document["profiles"].(map[string]interface{})
+	if profiles == nil {
+		profiles = map[string]interface{}{}
+	}
+	if _, ok := profiles[profile]; ok && !force {
+		logrus.Errorf("Profile '%s' already exists in '%s'; use --force to replace it", profile, configFilename)
+		os.Exit(1)
+	}
+	profiles[profile] = map[string]interface{}{
+		"username":              "",
+		"password_command":      "",
+		"account_id":            "",
+		"user_id":               "",
+		"client_id":             "",
+		"client_secret_command": "",
+		"subscription_key":      "",
+	}
+	document["profiles"] = profiles
+	if _, ok := document["default_profile"]; !ok {
+		document["default_profile"] = profile
+	}
+
+	jsonBytes, err := json.MarshalIndent(document, "" /*prefix*/, "\t" /*indent*/)
+	if err != nil {
+		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	err = ioutil.WriteFile(configFilename, append(jsonBytes, '\n'), 0600)
+	if err != nil {
+		logrus.Errorf("Could not write '%s': [%T] %v", configFilename, err, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote profile '%s' to '%s'.\n", profile, configFilename)
+}
+
+func doConfigShow(cmd *cobra.Command, args []string) {
+	config, err := loadConfig(cmd)
+	if err != nil {
+		logrus.Errorf("Could not load configuration: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, redactedConfig(config))
+}
+
+func doConfigValidate(cmd *cobra.Command, args []string) {
+	config, err := loadConfig(cmd)
+	if err != nil {
+		logrus.Errorf("Could not load configuration: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	var problems []string
+	err = resolveSecrets(config)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if config.Token == "" {
+		required := map[string]string{
+			"username":         config.Username,
+			"password":         config.Password,
+			"client_id":        config.ClientID,
+			"client_secret":    config.ClientSecret,
+			"account_id":       config.AccountID,
+			"user_id":          config.UserID,
+			"subscription_key": config.SubscriptionKey,
+		}
+		for name, value := range required {
+			if value == "" {
+				problems = append(problems, fmt.Sprintf("missing %s", name))
+			}
+		}
+	} else if config.SubscriptionKey == "" {
+		problems = append(problems, "missing subscription_key")
+	}
+	sort.Strings(problems)
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("%s\n", problem)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("OK\n")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfig(t *testing.T) {
+	directory, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	configFilename := filepath.Join(directory, "config.json")
+	err = ioutil.WriteFile(configFilename, []byte(`{
+		"host": "top.example.test",
+		"username": "top",
+		"subscription_key": "top-key",
+		"default_profile": "station1",
+		"profiles": {
+			"station1": {"username": "one", "password_command": "echo one-password"},
+			"station2": {"username": "two", "host": "two.example.test", "password": "two-password", "password_command": "exit 1"}
+		}
+	}`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write the configuration: %v", err)
+	}
+	singleFilename := filepath.Join(directory, "single.json")
+	err = ioutil.WriteFile(singleFilename, []byte(`{"username": "single", "token": "single-token"}`), 0600)
+	if err != nil {
+		t.Fatalf("Could not write the configuration: %v", err)
+	}
+
+	rows := []struct {
+		description string
+		filename    string // The "--config" flag; if empty, the flag is left at a default that does not exist.
+		profile     string // The "--profile" flag.
+		environment map[string]string
+		expected    map[string]string // The non-empty fields, after the secrets are resolved.
+		err         string            // If set, the error must contain this.
+	}{
+		{
+			description: "Default profile",
+			filename:    configFilename,
+			expected:    map[string]string{"host": "top.example.test", "username": "one", "password": "one-password", "password_command": "echo one-password", "subscription_key": "top-key"},
+		},
+		{
+			description: "Profile flag",
+			filename:    configFilename,
+			profile:     "station2",
+			expected:    map[string]string{"host": "two.example.test", "username": "two", "password": "two-password", "password_command": "exit 1", "subscription_key": "top-key"},
+		},
+		{
+			description: "Profile variable",
+			filename:    configFilename,
+			environment: map[string]string{"ER_PROFILE": "station2"},
+			expected:    map[string]string{"host": "two.example.test", "username": "two", "password": "two-password", "password_command": "exit 1", "subscription_key": "top-key"},
+		},
+		{
+			description: "Profile flag beats the variable",
+			filename:    configFilename,
+			profile:     "station1",
+			environment: map[string]string{"ER_PROFILE": "station2"},
+			expected:    map[string]string{"host": "top.example.test", "username": "one", "password": "one-password", "password_command": "echo one-password", "subscription_key": "top-key"},
+		},
+		{
+			description: "Variables beat the file",
+			filename:    configFilename,
+			environment: map[string]string{"ER_USERNAME": "env", "ER_PASSWORD": "env-password", "ER_HOST": "env.example.test"},
+			expected:    map[string]string{"host": "env.example.test", "username": "env", "password": "env-password", "password_command": "echo one-password", "subscription_key": "top-key"},
+		},
+		{
+			description: "Without profiles",
+			filename:    singleFilename,
+			expected:    map[string]string{"username": "single", "token": "single-token"},
+		},
+		{
+			description: "Missing profile",
+			filename:    configFilename,
+			profile:     "station3",
+			err:         "could not find profile 'station3'",
+		},
+		{
+			description: "Missing file",
+			filename:    filepath.Join(directory, "missing.json"),
+			err:         "could not read",
+		},
+		{
+			description: "Missing default file",
+			environment: map[string]string{"ER_USERNAME": "env"},
+			expected:    map[string]string{"username": "env"},
+		},
+		{
+			description: "Missing default file with a profile",
+			profile:     "station1",
+			err:         "could not find profile 'station1'",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			for name, value := range row.environment {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			cmd := &cobra.Command{}
+			cmd.Flags().String("config", filepath.Join(directory, "default.json"), "")
+			cmd.Flags().String("profile", "", "")
+			if row.filename != "" {
+				cmd.Flags().Set("config", row.filename)
+			}
+			if row.profile != "" {
+				cmd.Flags().Set("profile", row.profile)
+			}
+
+			config, err := loadConfig(cmd)
+			if err == nil {
+				err = resolveSecrets(config)
+			}
+			if row.err != "" {
+				if err == nil || !strings.Contains(err.Error(), row.err) {
+					t.Errorf("Expected an error with %q; got %v", row.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			actual := map[string]string{}
+			for _, field := range configFields(config) {
+				if value := field.value.String(); value != "" {
+					actual[field.name] = value
+				}
+			}
+			if !reflect.DeepEqual(actual, row.expected) {
+				t.Errorf("Expected %v; got %v", row.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResolveSecretsError(t *testing.T) {
+	config := &profileConfig{ClientSecretCommand: "echo nope >&2; exit 3"}
+	err := resolveSecrets(config)
+	if err == nil || !strings.Contains(err.Error(), "client_secret_command") || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Expected the command's error; got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doUserContactInfoExport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	vCard, _ := cmd.Flags().GetBool("vcard")
+	if !vCard {
+		logrus.Errorf("Missing export format (--vcard)")
+		os.Exit(1)
+	}
+
+	// Without any user IDs, everyone is exported.
+	usersByID := map[string]*emergencyreporting.User{}
+	userIDs := args
+	if len(userIDs) == 0 {
+		users, err := client.GetAllUsers(ctx, map[string]string{"limit": cmd.Flag("limit").Value.String()})
+		if err != nil {
+			logrus.Errorf("Could not get users: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		for _, user := range users {
+			userIDs = append(userIDs, user.UserID)
+			usersByID[user.UserID] = user
+		}
+	}
+
+	results := runBulk(cmd, "export contact info", userIDs, func(ctx context.Context, userID string) (interface{}, error) {
+		user, ok := usersByID[userID]
+		if !ok {
+			userResponse, err := client.GetUser(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			if userResponse.User == nil {
+				return nil, fmt.Errorf("user not found")
+			}
+			user = userResponse.User
+		}
+
+		// Not everyone has contact info; they still get a vCard with what the user has.
+		var contactInfo *emergencyreporting.UserContactInfo
+		contactInfoResponse, err := client.GetUserContactInfo(ctx, userID)
+		if err != nil {
+			if !errors.Is(err, emergencyreporting.ErrorNotFound) {
+				return nil, err
+			}
+		} else {
+			contactInfo = contactInfoResponse.ContactInfo
+		}
+
+		return emergencyreporting.UserVCard(user, contactInfo), nil
+	})
+
+	for _, value := range results.Values() {
+		fmt.Print(value.(string))
+	}
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func doUserContactInfoUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	userID := args[0]
+
+	var contactInfo emergencyreporting.UserContactInfo
+	err := json.Unmarshal([]byte(args[1]), &contactInfo)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	// Users without any contact info yet have no row version to check.
+	contactInfoResponse, err := client.GetUserContactInfo(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, emergencyreporting.ErrorNotFound) {
+			logrus.Errorf("Could not get user contact info: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	} else if contactInfoResponse.ContactInfo != nil {
+		contactInfo.RowVersion = contactInfoResponse.ContactInfo.RowVersion
+	}
+
+	putContactInfoResponse, err := client.PutUserContactInfo(ctx, userID, contactInfo)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update user contact info: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, putContactInfoResponse)
+}
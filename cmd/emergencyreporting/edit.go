@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+	"gopkg.in/yaml.v2"
+)
+
+// Edit formats.
+const (
+	editFormatYAML = "yaml"
+	editFormatJSON = "json"
+)
+
+// editYAMLHeader is written at the top of the YAML files being edited.
+const editYAMLHeader = `# Change the fields below, then save and quit.
+# Fields that are removed are left alone; an empty file cancels the edit.
+`
+
+// errEditCanceled is returned when the user empties the file.
+var errEditCanceled = errors.New("edit canceled")
+
+// addEditFlags adds the flags used by every "edit" command.
+func addEditFlags(command *cobra.Command) {
+	command.Flags().String("format", editFormatYAML, "The format to edit the record in: yaml or json.")
+	command.Flags().Bool("yes", false, "Apply the changes without asking.")
+}
+
+// editRecord opens the record in the user's editor and returns the fields that changed, by JSON name.
+//
+// The read-only fields (such as IDs and the row version) cannot be changed, and fields may not be added.
+// If nothing changed, this returns an empty map.
+func editRecord(cmd *cobra.Command, record interface{}, readOnlyFields []string) (map[string]interface{}, error) {
+	format := cmd.Flag("format").Value.String()
+
+	original, err := toGeneric(record)
+	if err != nil {
+		return nil, err
+	}
+	document, err := toOrdered(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []byte
+	switch format {
+	case editFormatYAML:
+		contents, err = yaml.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		contents = append([]byte(editYAMLHeader), contents...)
+	case editFormatJSON:
+		var compact bytes.Buffer
+		err = writeOrderedJSON(&compact, document)
+		if err != nil {
+			return nil, err
+		}
+		var indented bytes.Buffer
+		err = json.Indent(&indented, compact.Bytes(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		contents = append(indented.Bytes(), '\n')
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+
+	file, err := ioutil.TempFile("", "emergencyreporting-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary file: %w", err)
+	}
+	filename := file.Name()
+	defer os.Remove(filename)
+	_, err = file.Write(contents)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not write temporary file: %w", err)
+	}
+
+	err = runEditor(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read temporary file: %w", err)
+	}
+
+	var edited map[string]interface{}
+	switch format {
+	case editFormatYAML:
+		var value interface{}
+		err = yaml.Unmarshal(contents, &value)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse YAML: %w", err)
+		}
+		if value == nil {
+			return nil, errEditCanceled
+		}
+		converted, ok := fromYAML(value).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the record must be a mapping")
+		}
+		edited = converted
+	case editFormatJSON:
+		if len(bytes.TrimSpace(contents)) == 0 {
+			return nil, errEditCanceled
+		}
+		err = json.Unmarshal(contents, &edited)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JSON: %w", err)
+		}
+	}
+
+	return diffRecord(original, edited, readOnlyFields)
+}
+
+// runEditor runs $VISUAL or $EDITOR (or "vi") on the file and waits for it to finish.
+func runEditor(filename string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may have its own arguments, such as "code --wait".
+	command := exec.Command("sh", "-c", editor+` "$1"`, "sh", filename)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err := command.Run()
+	if err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
+
+// toGeneric converts the value to its generic JSON representation.
+func toGeneric(value interface{}) (map[string]interface{}, error) {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fromYAML converts a decoded YAML value to the equivalent decoded JSON value.
+func fromYAML(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, child := range typedValue {
+			result[fmt.Sprintf("%v", key)] = fromYAML(child)
+		}
+		return result
+	case []interface{}:
+		for index, child := range typedValue {
+			typedValue[index] = fromYAML(child)
+		}
+	}
+	return value
+}
+
+// diffRecord returns the fields in the edited record that differ from the original.
+func diffRecord(original map[string]interface{}, edited map[string]interface{}, readOnlyFields []string) (map[string]interface{}, error) {
+	changes := map[string]interface{}{}
+	for key, value := range edited {
+		originalValue, ok := original[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown field: %s", key)
+		}
+		// Unquoted YAML (or JSON) numbers and booleans are strings in the API.
+		if _, isString := originalValue.(string); isString || originalValue == nil {
+			switch value.(type) {
+			case int, int64, float64, bool:
+				value = fmt.Sprintf("%v", value)
+			}
+		}
+		if reflect.DeepEqual(originalValue, value) {
+			continue
+		}
+		for _, readOnlyField := range readOnlyFields {
+			if key == readOnlyField {
+				return nil, fmt.Errorf("%s cannot be changed", key)
+			}
+		}
+		changes[key] = value
+	}
+	return changes, nil
+}
+
+// confirmChanges shows the changes and asks whether to apply them (unless "--yes" was given).
+func confirmChanges(cmd *cobra.Command, original interface{}, changes map[string]interface{}) bool {
+	originalFields, _ := toGeneric(original)
+
+	var keys []string
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(os.Stderr, "Changes:\n")
+	for _, key := range keys {
+		before, _ := json.Marshal(originalFields[key])
+		after, _ := json.Marshal(changes[key])
+		fmt.Fprintf(os.Stderr, "  %s: %s -> %s\n", key, before, after)
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "Apply these changes? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// decodeChanges decodes the changes into the target, such as a patch request.
+func decodeChanges(changes map[string]interface{}, target interface{}) error {
+	contents, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(target)
+	if err != nil {
+		return fmt.Errorf("could not apply the changes: %w", err)
+	}
+	return nil
+}
+
+// applyChanges decodes the record with the changes applied into the target.
+//
+// This works on a copy, since a plain copy of a record would share its pointer fields with the original.
+func applyChanges(record interface{}, changes map[string]interface{}, target interface{}) error {
+	fields, err := toGeneric(record)
+	if err != nil {
+		return err
+	}
+	for key, value := range changes {
+		fields[key] = value
+	}
+	return decodeChanges(fields, target)
+}
+
+// editAndConfirm runs the editor and the confirmation, exiting if there is nothing to do.
+func editAndConfirm(cmd *cobra.Command, record interface{}, readOnlyFields []string) map[string]interface{} {
+	changes, err := editRecord(cmd, record, readOnlyFields)
+	if errors.Is(err, errEditCanceled) {
+		fmt.Fprintf(os.Stderr, "Edit canceled.\n")
+		os.Exit(0)
+	}
+	if err != nil {
+		logrus.Errorf("Could not edit: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(os.Stderr, "No changes.\n")
+		os.Exit(0)
+	}
+	if !confirmChanges(cmd, record, changes) {
+		fmt.Fprintf(os.Stderr, "Not changed.\n")
+		os.Exit(0)
+	}
+	return changes
+}
+
+func doIncidentEdit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	incidentID := args[0]
+
+	incidentResponse, err := client.GetIncident(ctx, incidentID)
+	if err != nil {
+		logrus.Errorf("Could not get incident: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if incidentResponse.Incident == nil {
+		logrus.Errorf("Incident not found")
+		os.Exit(1)
+	}
+	incident := incidentResponse.Incident
+
+	changes := editAndConfirm(cmd, incident, []string{"incidentID", "rowVersion"})
+
+	var patchIncidentRequest emergencyreporting.PatchIncidentRequest
+	err = decodeChanges(changes, &patchIncidentRequest)
+	if err != nil {
+		logrus.Errorf("Could not patch incident: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	patchIncidentResponse, err := client.PatchIncident(ctx, incident.IncidentID, incident.RowVersion, patchIncidentRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not patch incident: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchIncidentResponse)
+}
+
+func doIncidentExposureEdit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	incidentID := args[0]
+	exposureID := args[1]
+
+	exposureResponse, err := client.GetIncidentExposure(ctx, incidentID, exposureID)
+	if err != nil {
+		logrus.Errorf("Could not get exposure: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if exposureResponse.Exposure == nil {
+		logrus.Errorf("Exposure not found")
+		os.Exit(1)
+	}
+	exposure := exposureResponse.Exposure
+
+	changes := editAndConfirm(cmd, exposure, []string{"exposureID", "incidentID", "rowVersion"})
+
+	var patchExposureRequest emergencyreporting.PatchExposureRequest
+	err = decodeChanges(changes, &patchExposureRequest)
+	if err != nil {
+		logrus.Errorf("Could not patch exposure: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	patchExposureResponse, err := client.PatchIncidentExposure(ctx, incidentID, exposureID, exposure.RowVersion, patchExposureRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not patch exposure: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchExposureResponse)
+}
+
+func doExposureLocationEdit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	exposureID := args[0]
+
+	locationResponse, err := client.GetExposureLocation(ctx, exposureID)
+	if err != nil {
+		logrus.Errorf("Could not get exposure location: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if locationResponse.Location == nil {
+		logrus.Errorf("Exposure location not found")
+		os.Exit(1)
+	}
+	location := locationResponse.Location
+
+	changes := editAndConfirm(cmd, location, []string{"exposureID", "rowVersion"})
+
+	// The location is replaced as a whole, so the changes are applied to the current one.
+	var updatedLocation emergencyreporting.ExposureLocation
+	err = applyChanges(location, changes, &updatedLocation)
+	if err != nil {
+		logrus.Errorf("Could not update exposure location: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	putResponse, err := client.PutExposureLocation(ctx, exposureID, updatedLocation)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update exposure location: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, putResponse)
+}
+
+func doUserEdit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	userID := args[0]
+
+	userResponse, err := client.GetUser(ctx, userID)
+	if err != nil {
+		logrus.Errorf("Could not get user: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if userResponse.User == nil {
+		logrus.Errorf("User not found")
+		os.Exit(1)
+	}
+	user := userResponse.User
+
+	changes := editAndConfirm(cmd, user, []string{"rowNum", "userID", "rowVersion"})
+
+	var updatedUser emergencyreporting.User
+	err = applyChanges(user, changes, &updatedUser)
+	if err != nil {
+		logrus.Errorf("Could not patch user: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	patchUserRequest, err := emergencyreporting.DiffUser(*user, updatedUser)
+	if err != nil {
+		logrus.Errorf("Could not patch user: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	patchResponse, err := client.PatchUser(ctx, user.UserID, user.RowVersion, patchUserRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not patch user: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchResponse)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+func TestEdit(t *testing.T) {
+	server := ertest.NewServer()
+	defer server.Close()
+	incidentID := server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2026-0001", DispatchRunNumber: "R-1"})
+	exposureID := server.AddExposure(incidentID, emergencyreporting.Exposure{})
+	server.SetExposureLocation(exposureID, emergencyreporting.ExposureLocation{StreetName: "Main", City: "Springfield"})
+	title := "Firefighter"
+	userID := server.AddUser(emergencyreporting.User{FullName: "Jane Doe", Title: &title})
+
+	rows := []struct {
+		description string
+		args        []string
+		editor      string
+		method      string      // The method of the change request; empty means that nothing should be sent.
+		body        interface{} // The expected body of the change request, decoded from JSON.
+	}{
+		{
+			description: "Incident",
+			args:        []string{"incident", "edit", incidentID},
+			editor:      `sed -i 's/"2026-0001"/"2026-0009"/'`,
+			method:      http.MethodPatch,
+			body:        map[string]interface{}{"incidentNumber": "2026-0009"},
+		},
+		{
+			description: "Incident without changes",
+			args:        []string{"incident", "edit", incidentID},
+			editor:      "true",
+		},
+		{
+			description: "Exposure location",
+			args:        []string{"exposure-location", "edit", exposureID},
+			editor:      `sed -i 's/"Springfield"/"Shelbyville"/'`,
+			method:      http.MethodPut,
+		},
+		{
+			description: "Exposure location without changes",
+			args:        []string{"exposure-location", "edit", exposureID},
+			editor:      "true",
+		},
+		{
+			description: "User",
+			args:        []string{"user", "edit", userID},
+			editor:      `sed -i 's/"Firefighter"/"Captain"/'`,
+			method:      http.MethodPatch,
+			body: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/title", "value": "Captain"},
+			},
+		},
+		{
+			description: "User without changes",
+			args:        []string{"user", "edit", userID},
+			editor:      "true",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			os.Setenv("VISUAL", row.editor)
+			defer os.Unsetenv("VISUAL")
+
+			before := len(server.Requests())
+			output, exitCode := runMain(t, server, append([]string{"--dry-run", "--log-level", "error"}, append(row.args, "--format", "json", "--yes")...)...)
+			if exitCode != 0 {
+				t.Fatalf("Expected exit code 0; got %d (output: %s)", exitCode, output)
+			}
+			for _, request := range server.Requests()[before:] {
+				if request.Method != http.MethodGet && request.Method != http.MethodPost {
+					t.Errorf("The server got a %s request for %s", request.Method, request.Path)
+				}
+			}
+
+			if row.method == "" {
+				if output != "" {
+					t.Errorf("Expected nothing to be sent; got %s", output)
+				}
+				return
+			}
+			var request struct {
+				Method string      `json:"method"`
+				Body   interface{} `json:"body"`
+			}
+			err := json.Unmarshal([]byte(output), &request)
+			if err != nil {
+				t.Fatalf("Could not parse the output: %v (output: %s)", err, output)
+			}
+			if request.Method != row.method {
+				t.Errorf("Expected method %s; got %s", row.method, request.Method)
+			}
+			if row.body != nil && !reflect.DeepEqual(request.Body, row.body) {
+				t.Errorf("Expected body %v; got %v", row.body, request.Body)
+			}
+			if location, ok := request.Body.(map[string]interface{}); ok && row.method == http.MethodPut {
+				// The location is replaced as a whole, so everything but the change has to be kept.
+				if location["city"] != "Shelbyville" || location["streetName"] != "Main" {
+					t.Errorf("Expected the changed city and the original street; got %v", location)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doEMSExport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	nemsis3XML, _ := cmd.Flags().GetBool("nemsis3-xml")
+	if !nemsis3XML {
+		logrus.Errorf("Missing export format (--nemsis3-xml)")
+		os.Exit(1)
+	}
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	options := emergencyreporting.NEMSIS3Options{
+		AgencyStateID:   cmd.Flag("agency-state-id").Value.String(),
+		AgencyNumber:    cmd.Flag("agency-number").Value.String(),
+		AgencyName:      cmd.Flag("agency-name").Value.String(),
+		State:           cmd.Flag("state").Value.String(),
+		SoftwareCreator: cmd.Flag("software-creator").Value.String(),
+		SoftwareName:    cmd.Flag("software-name").Value.String(),
+		SoftwareVersion: cmd.Flag("software-version").Value.String(),
+		UnitCapability:  cmd.Flag("unit-capability").Value.String(),
+		UnitLevelOfCare: cmd.Flag("unit-level-of-care").Value.String(),
+		Location:        location,
+	}
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	reports, err := client.GetAllPatientCareReports(ctx, map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	})
+	if err != nil {
+		logrus.Errorf("Could not get patient care reports: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	// Only the report IDs are logged; everything else is protected health information.
+	reportsByID := map[string]*emergencyreporting.PatientCareReport{}
+	var pcrIDs []string
+	for _, report := range reports {
+		pcrIDs = append(pcrIDs, report.PCRID)
+		reportsByID[report.PCRID] = report
+	}
+
+	results := runBulk(cmd, "export patient care report", pcrIDs, func(ctx context.Context, pcrID string) (interface{}, error) {
+		return client.GetNEMSIS3PatientCareReport(ctx, reportsByID[pcrID], options)
+	})
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+
+	var nemsis3Reports []*emergencyreporting.NEMSIS3PatientCareReport
+	for _, value := range results.Values() {
+		nemsis3Reports = append(nemsis3Reports, value.(*emergencyreporting.NEMSIS3PatientCareReport))
+	}
+	err = emergencyreporting.WriteNEMSIS3XML(os.Stdout, emergencyreporting.NewNEMSIS3DataSet(nemsis3Reports, options))
+	if err != nil {
+		logrus.Errorf("Could not write XML: [%T] %v", err, err)
+		os.Exit(1)
+	}
+}
+
+func doEMSGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	reportResponse, err := client.GetPatientCareReport(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get patient care report: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, reportResponse.PatientCareReport)
+}
+
+func doEMSList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		reports, err := client.GetAllPatientCareReports(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get patient care reports: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, reports)
+		return
+	}
+
+	reportsResponse, err := client.GetPatientCareReports(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get patient care reports: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, reportsResponse.PatientCareReports)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+func TestEMSList(t *testing.T) {
+	server := ertest.NewServer()
+	defer server.Close()
+	var pcrIDs []string
+	for _, report := range []emergencyreporting.PatientCareReport{
+		{PCRNumber: "PCR-1", IncidentID: "7", Patient: &emergencyreporting.EMSPatient{LastName: "Smith"}},
+		{PCRNumber: "PCR-2", IncidentID: "8"},
+		{PCRNumber: "PCR-3", IncidentID: "9"},
+	} {
+		pcrIDs = append(pcrIDs, server.AddPatientCareReport(report))
+	}
+
+	rows := []struct {
+		description string
+		args        []string
+		expected    []string // The "pcrID,pcrNumber" lines.
+	}{
+		{
+			description: "First page",
+			args:        []string{"--limit", "2"},
+			expected:    []string{pcrIDs[0] + ",PCR-1", pcrIDs[1] + ",PCR-2"},
+		},
+		{
+			description: "All pages",
+			args:        []string{"--limit", "2", "--all"},
+			expected:    []string{pcrIDs[0] + ",PCR-1", pcrIDs[1] + ",PCR-2", pcrIDs[2] + ",PCR-3"},
+		},
+		{
+			description: "Filter",
+			args:        []string{"incidentID eq '8'"},
+			expected:    []string{pcrIDs[1] + ",PCR-2"},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			args := append([]string{"ems", "list", "--output", "csv", "--fields", "pcrID,pcrNumber"}, row.args...)
+			output, exitCode := runMain(t, server, args...)
+			if exitCode != 0 {
+				t.Fatalf("Expected exit code 0; got %d (output: %s)", exitCode, output)
+			}
+			expected := strings.Join(append([]string{"pcrID,pcrNumber"}, row.expected...), "\n") + "\n"
+			if output != expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doEventAttendeeAdd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	attendee := emergencyreporting.EventAttendee{
+		UserID:       args[1],
+		Hours:        cmd.Flag("hours").Value.String(),
+		PaygradeName: cmd.Flag("paygrade-name").Value.String(),
+		PaygradeRate: cmd.Flag("paygrade-rate").Value.String(),
+		Status:       cmd.Flag("status").Value.String(),
+	}
+
+	postAttendeeResponse, err := client.PostEventAttendee(ctx, args[0], attendee)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not add attendee: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postAttendeeResponse)
+}
+
+func doEventAttendeeList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	attendeesResponse, err := client.GetEventAttendees(ctx, args[0], options)
+	if err != nil {
+		logrus.Errorf("Could not get attendees: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, attendeesResponse.Attendees)
+}
+
+func doEventAttendeeUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	eventID := args[0]
+	attendeeID := args[1]
+
+	var patchAttendeeRequest emergencyreporting.PatchEventAttendeeRequest
+	err := json.Unmarshal([]byte(args[2]), &patchAttendeeRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	attendeesResponse, err := client.GetEventAttendees(ctx, eventID, nil)
+	if err != nil {
+		logrus.Errorf("Could not get attendees: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	var attendee *emergencyreporting.EventAttendee
+	for _, a := range attendeesResponse.Attendees {
+		if a.AttendeeID == attendeeID {
+			attendee = a
+			break
+		}
+	}
+	if attendee == nil {
+		logrus.Errorf("Attendee not found")
+		os.Exit(1)
+	}
+
+	patchAttendeeResponse, err := client.PatchEventAttendee(ctx, eventID, attendeeID, attendee.RowVersion, patchAttendeeRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update attendee: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchAttendeeResponse)
+}
+
+func doEventCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var event emergencyreporting.Event
+	err := json.Unmarshal([]byte(args[0]), &event)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postEventResponse, err := client.PostEvent(ctx, event)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create event: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postEventResponse)
+}
+
+func doEventGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	eventResponse, err := client.GetEvent(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get event: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, eventResponse.Event)
+}
+
+func doEventList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		events, err := client.GetAllEvents(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get events: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, events)
+		return
+	}
+
+	eventsResponse, err := client.GetEvents(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get events: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, eventsResponse.Events)
+}
+
+func doEventUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	eventID := args[0]
+
+	var patchEventRequest emergencyreporting.PatchEventRequest
+	err := json.Unmarshal([]byte(args[1]), &patchEventRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	eventResponse, err := client.GetEvent(ctx, eventID)
+	if err != nil {
+		logrus.Errorf("Could not get event: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if eventResponse.Event == nil {
+		logrus.Errorf("Event not found")
+		os.Exit(1)
+	}
+
+	patchEventResponse, err := client.PatchEvent(ctx, eventID, eventResponse.Event.RowVersion, patchEventRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update event: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchEventResponse)
+}
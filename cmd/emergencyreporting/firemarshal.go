@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doFireMarshalInspectionCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var inspection emergencyreporting.FireMarshalInspection
+	err := json.Unmarshal([]byte(args[0]), &inspection)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postInspectionResponse, err := client.PostFireMarshalInspection(ctx, inspection)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postInspectionResponse)
+}
+
+func doFireMarshalInspectionGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	inspectionResponse, err := client.GetFireMarshalInspection(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, inspectionResponse.Inspection)
+}
+
+func doFireMarshalInspectionList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		inspections, err := client.GetAllFireMarshalInspections(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get inspections: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, inspections)
+		return
+	}
+
+	inspectionsResponse, err := client.GetFireMarshalInspections(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get inspections: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, inspectionsResponse.Inspections)
+}
+
+func doFireMarshalInspectionUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	inspectionID := args[0]
+
+	var patchInspectionRequest emergencyreporting.PatchFireMarshalInspectionRequest
+	err := json.Unmarshal([]byte(args[1]), &patchInspectionRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	inspectionResponse, err := client.GetFireMarshalInspection(ctx, inspectionID)
+	if err != nil {
+		logrus.Errorf("Could not get inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if inspectionResponse.Inspection == nil {
+		logrus.Errorf("Inspection not found")
+		os.Exit(1)
+	}
+
+	patchInspectionResponse, err := client.PatchFireMarshalInspection(ctx, inspectionID, inspectionResponse.Inspection.RowVersion, patchInspectionRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchInspectionResponse)
+}
+
+func doFireMarshalOverdue(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	asOf := time.Now().In(location)
+	if value := cmd.Flag("as-of").Value.String(); value != "" {
+		asOf, err = time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			logrus.Errorf("Could not parse the date: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	}
+
+	overdue, err := client.GetOverdueReInspections(ctx, asOf)
+	if err != nil {
+		logrus.Errorf("Could not get overdue re-inspections: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, overdue)
+}
+
+func doFireMarshalReInspectionCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var reInspection emergencyreporting.FireMarshalReInspection
+	err := json.Unmarshal([]byte(args[1]), &reInspection)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postReInspectionResponse, err := client.PostFireMarshalReInspection(ctx, args[0], reInspection)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create re-inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postReInspectionResponse)
+}
+
+func doFireMarshalReInspectionGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	reInspectionResponse, err := client.GetFireMarshalReInspection(ctx, args[0], args[1])
+	if err != nil {
+		logrus.Errorf("Could not get re-inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, reInspectionResponse.ReInspection)
+}
+
+func doFireMarshalReInspectionList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	reInspectionsResponse, err := client.GetFireMarshalReInspections(ctx, args[0], options)
+	if err != nil {
+		logrus.Errorf("Could not get re-inspections: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, reInspectionsResponse.ReInspections)
+}
+
+func doFireMarshalReInspectionUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	inspectionID := args[0]
+	reInspectionID := args[1]
+
+	var patchReInspectionRequest emergencyreporting.PatchFireMarshalReInspectionRequest
+	err := json.Unmarshal([]byte(args[2]), &patchReInspectionRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	reInspectionResponse, err := client.GetFireMarshalReInspection(ctx, inspectionID, reInspectionID)
+	if err != nil {
+		logrus.Errorf("Could not get re-inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if reInspectionResponse.ReInspection == nil {
+		logrus.Errorf("Re-inspection not found")
+		os.Exit(1)
+	}
+
+	patchReInspectionResponse, err := client.PatchFireMarshalReInspection(ctx, inspectionID, reInspectionID, reInspectionResponse.ReInspection.RowVersion, patchReInspectionRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update re-inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchReInspectionResponse)
+}
+
+func doFireMarshalViolationCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var violation emergencyreporting.FireMarshalViolation
+	err := json.Unmarshal([]byte(args[1]), &violation)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postViolationResponse, err := client.PostFireMarshalViolation(ctx, args[0], violation)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create violation: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postViolationResponse)
+}
+
+func doFireMarshalViolationGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	violationResponse, err := client.GetFireMarshalViolation(ctx, args[0], args[1])
+	if err != nil {
+		logrus.Errorf("Could not get violation: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, violationResponse.Violation)
+}
+
+func doFireMarshalViolationList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	violationsResponse, err := client.GetFireMarshalViolations(ctx, args[0], options)
+	if err != nil {
+		logrus.Errorf("Could not get violations: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, violationsResponse.Violations)
+}
+
+func doFireMarshalViolationUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	inspectionID := args[0]
+	violationID := args[1]
+
+	var patchViolationRequest emergencyreporting.PatchFireMarshalViolationRequest
+	err := json.Unmarshal([]byte(args[2]), &patchViolationRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	violationResponse, err := client.GetFireMarshalViolation(ctx, inspectionID, violationID)
+	if err != nil {
+		logrus.Errorf("Could not get violation: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if violationResponse.Violation == nil {
+		logrus.Errorf("Violation not found")
+		os.Exit(1)
+	}
+
+	patchViolationResponse, err := client.PatchFireMarshalViolation(ctx, inspectionID, violationID, violationResponse.Violation.RowVersion, patchViolationRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update violation: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchViolationResponse)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doHydrantCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var hydrant emergencyreporting.Hydrant
+	err := json.Unmarshal([]byte(args[0]), &hydrant)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postHydrantResponse, err := client.PostHydrant(ctx, hydrant)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create hydrant: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postHydrantResponse)
+}
+
+func doHydrantExport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	geoJSON, _ := cmd.Flags().GetBool("geojson")
+	if !geoJSON {
+		logrus.Errorf("Missing export format (--geojson)")
+		os.Exit(1)
+	}
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	hydrants, err := client.GetAllHydrants(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get hydrants: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	collection, err := emergencyreporting.HydrantsGeoJSON(hydrants)
+	if err != nil {
+		logrus.Errorf("Could not convert hydrants: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if skipped := len(hydrants) - len(collection.Features); skipped > 0 {
+		logrus.Warnf("Left out %d of %d hydrants without coordinates.", skipped, len(hydrants))
+	}
+	printOutput(cmd, collection)
+}
+
+func doHydrantFlowTestCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantID := args[0]
+
+	var flowTest emergencyreporting.HydrantFlowTest
+	err := json.Unmarshal([]byte(args[1]), &flowTest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postFlowTestResponse, err := client.PostHydrantFlowTest(ctx, hydrantID, flowTest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create flow test: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postFlowTestResponse)
+}
+
+func doHydrantFlowTestList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantID := args[0]
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	flowTestsResponse, err := client.GetHydrantFlowTests(ctx, hydrantID, options)
+	if err != nil {
+		logrus.Errorf("Could not get flow tests: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, flowTestsResponse.FlowTests)
+}
+
+func doHydrantGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantResponse, err := client.GetHydrant(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get hydrant: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, hydrantResponse.Hydrant)
+}
+
+func doHydrantInspectionCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantID := args[0]
+
+	var inspection emergencyreporting.HydrantInspection
+	err := json.Unmarshal([]byte(args[1]), &inspection)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postInspectionResponse, err := client.PostHydrantInspection(ctx, hydrantID, inspection)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create inspection: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postInspectionResponse)
+}
+
+func doHydrantInspectionList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantID := args[0]
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	inspectionsResponse, err := client.GetHydrantInspections(ctx, hydrantID, options)
+	if err != nil {
+		logrus.Errorf("Could not get inspections: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, inspectionsResponse.Inspections)
+}
+
+func doHydrantList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		hydrants, err := client.GetAllHydrants(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get hydrants: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, hydrants)
+		return
+	}
+
+	hydrantsResponse, err := client.GetHydrants(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get hydrants: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, hydrantsResponse.Hydrants)
+}
+
+func doHydrantUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hydrantID := args[0]
+
+	var patchHydrantRequest emergencyreporting.PatchHydrantRequest
+	err := json.Unmarshal([]byte(args[1]), &patchHydrantRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	hydrantResponse, err := client.GetHydrant(ctx, hydrantID)
+	if err != nil {
+		logrus.Errorf("Could not get hydrant: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if hydrantResponse.Hydrant == nil {
+		logrus.Errorf("Hydrant not found")
+		os.Exit(1)
+	}
+
+	patchHydrantResponse, err := client.PatchHydrant(ctx, hydrantID, hydrantResponse.Hydrant.RowVersion, patchHydrantRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update hydrant: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchHydrantResponse)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doInventoryItemCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var item emergencyreporting.InventoryItem
+	err := json.Unmarshal([]byte(args[0]), &item)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postItemResponse, err := client.PostInventoryItem(ctx, item)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create item: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postItemResponse)
+}
+
+func doInventoryItemGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	itemResponse, err := client.GetInventoryItem(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get item: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, itemResponse.Item)
+}
+
+func doInventoryItemList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		items, err := client.GetAllInventoryItems(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get items: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, items)
+		return
+	}
+
+	itemsResponse, err := client.GetInventoryItems(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get items: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, itemsResponse.Items)
+}
+
+func doInventoryItemUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	itemID := args[0]
+
+	var patchItemRequest emergencyreporting.PatchInventoryItemRequest
+	err := json.Unmarshal([]byte(args[1]), &patchItemRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	itemResponse, err := client.GetInventoryItem(ctx, itemID)
+	if err != nil {
+		logrus.Errorf("Could not get item: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if itemResponse.Item == nil {
+		logrus.Errorf("Item not found")
+		os.Exit(1)
+	}
+
+	patchItemResponse, err := client.PatchInventoryItem(ctx, itemID, itemResponse.Item.RowVersion, patchItemRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update item: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchItemResponse)
+}
+
+func doInventoryLocationCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var location emergencyreporting.InventoryLocation
+	err := json.Unmarshal([]byte(args[0]), &location)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postLocationResponse, err := client.PostInventoryLocation(ctx, location)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create location: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postLocationResponse)
+}
+
+func doInventoryLocationList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	locationsResponse, err := client.GetInventoryLocations(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get locations: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, locationsResponse.Locations)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doExpirations(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	days, _ := cmd.Flags().GetInt("days")
+	includeExpired, _ := cmd.Flags().GetBool("include-expired")
+
+	options := emergencyreporting.LicenseExpirationOptions{
+		AsOf:           time.Now().In(location),
+		Days:           days,
+		IncludeExpired: includeExpired,
+	}
+	expirations, err := client.GetLicenseExpirations(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get expirations: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, expirations)
+}
+
+func doUserLicenseList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	licensesResponse, err := client.GetUserLicenses(ctx, args[0], nil)
+	if err != nil {
+		logrus.Errorf("Could not get licenses: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, licensesResponse.Licenses)
+}
+
+func doUserLicenseUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	userID := args[0]
+	licenseID := args[1]
+
+	var patchLicenseRequest emergencyreporting.PatchUserLicenseRequest
+	err := json.Unmarshal([]byte(args[2]), &patchLicenseRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	licensesResponse, err := client.GetUserLicenses(ctx, userID, nil)
+	if err != nil {
+		logrus.Errorf("Could not get licenses: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	var currentLicense *emergencyreporting.UserLicense
+	for _, license := range licensesResponse.Licenses {
+		if license.LicenseID == licenseID {
+			currentLicense = license
+		}
+	}
+	if currentLicense == nil {
+		logrus.Errorf("License not found")
+		os.Exit(1)
+	}
+
+	patchLicenseResponse, err := client.PatchUserLicense(ctx, userID, licenseID, currentLicense.RowVersion, patchLicenseRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update license: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchLicenseResponse)
+}
+
+func doUserCertificationList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	certifications, err := client.GetAllUserCertifications(ctx, args[0], nil)
+	if err != nil {
+		logrus.Errorf("Could not get certifications: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, certifications)
+}
+
+func doUserCertificationUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	userID := args[0]
+	certificationID := args[1]
+
+	var patchCertificationRequest emergencyreporting.PatchUserCertificationRequest
+	err := json.Unmarshal([]byte(args[2]), &patchCertificationRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	certifications, err := client.GetAllUserCertifications(ctx, userID, nil)
+	if err != nil {
+		logrus.Errorf("Could not get certifications: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	var currentCertification *emergencyreporting.UserCertification
+	for _, certification := range certifications {
+		if certification.CertificationID == certificationID {
+			currentCertification = certification
+		}
+	}
+	if currentCertification == nil {
+		logrus.Errorf("Certification not found")
+		os.Exit(1)
+	}
+
+	patchCertificationResponse, err := client.PatchUserCertification(ctx, userID, certificationID, currentCertification.RowVersion, patchCertificationRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update certification: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchCertificationResponse)
+}
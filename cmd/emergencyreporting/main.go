@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/cadimport"
+	"github.com/tekkamanendless/emergencyreporting/cassette"
+	"github.com/tekkamanendless/emergencyreporting/erlogrus"
 )
 
 func main() {
@@ -23,6 +28,12 @@ This tool talks to the EmergencyReporting REST API.
 `,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// Set up any global stuff here that needs to run for every command.
+			level, err := logrus.ParseLevel(cmd.Flag("log-level").Value.String())
+			if err != nil {
+				logrus.Errorf("Invalid log level: %v", err)
+				os.Exit(1)
+			}
+			logrus.SetLevel(level)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
@@ -30,8 +41,72 @@ This tool talks to the EmergencyReporting REST API.
 		},
 	}
 	rootCommand.PersistentFlags().String("config", "config.json", "Path to the configuration file with the client credentials.")
+	rootCommand.PersistentFlags().String("profile", "", "The profile to use from the configuration file.  If this is not set, then $ER_PROFILE or the file's default profile is used.")
+	rootCommand.PersistentFlags().String("log-level", "info", `The log level: debug, info, warn, or error.  Request and response bodies (with personal information redacted) are only logged at "debug".`)
+	rootCommand.PersistentFlags().Int("concurrency", emergencyreporting.DefaultBulkConcurrency, "The number of requests to run at once for commands that take several IDs.")
 	rootCommand.PersistentFlags().Int("limit", 100, "The page size for any queries.")
 	rootCommand.PersistentFlags().String("token", "", "The Emergency Reporting token to use.  If this is not set, then this will attempt to log in and get a token.")
+	rootCommand.PersistentFlags().String("output", outputJSON, "The output format: json, ndjson, yaml, csv, table, or template.")
+	rootCommand.PersistentFlags().StringSlice("fields", nil, `The fields to output, such as "incidentID,incidentNumber".  Nested fields use dots, such as "location.city".`)
+	rootCommand.PersistentFlags().String("record", "", "Record every request and response to cassette files in this directory (with credentials scrubbed).")
+	rootCommand.PersistentFlags().String("replay", "", "Replay the requests from the cassette files in this directory instead of using the network.")
+	rootCommand.PersistentFlags().Bool("dry-run", false, "Write the requests that would change something instead of sending them.  Reads (such as the row version lookups) still go through.")
+	rootCommand.PersistentFlags().String("template", "", `The Go text/template to use with "--output template", such as "{{.incidentID}}".  Lists use the template once per item.`)
+
+	{
+		command := &cobra.Command{
+			Use:   "config",
+			Short: "Configuration sub-command",
+			Long: `
+The configuration file may hold several named profiles:
+
+	{
+		"default_profile": "production",
+		"host": "https://data.emergencyreporting.com",
+		"profiles": {
+			"production": {"username": "...", "password_command": "pass show er/production", ...},
+			"staging": {"tenant_host": "emergencyreportingb2crc.b2clogin.com", ...}
+		}
+	}
+
+Top-level fields apply to every profile.  Every field may be overridden with an
+environment variable named "ER_" plus the upper-case field name, such as ER_CLIENT_ID.
+
+If "password" or "client_secret" is empty, then "password_command" or
+"client_secret_command" is run with the shell and its output is used instead.
+`,
+			Run: nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "init",
+			Short: "Add a profile to the configuration file",
+			Long:  ``,
+			Args:  cobra.NoArgs,
+			Run:   doConfigInit,
+		}
+		subCommand.Flags().Bool("force", false, "Replace the profile if it already exists.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "show",
+			Short: "Show the configuration, with secrets redacted",
+			Long:  ``,
+			Args:  cobra.NoArgs,
+			Run:   doConfigShow,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "validate",
+			Short: "Validate the configuration",
+			Long:  `This runs any secret commands and checks that all of the required fields are present.`,
+			Args:  cobra.NoArgs,
+			Run:   doConfigValidate,
+		}
+		command.AddCommand(subCommand)
+	}
 
 	{
 		command := &cobra.Command{
@@ -100,6 +175,234 @@ This tool talks to the EmergencyReporting REST API.
 		}
 		command.AddCommand(subCommand)
 	}
+	{
+		command := &cobra.Command{
+			Use:   "batch",
+			Short: "Batch sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "run <file>",
+			Short: "Run the operations in an NDJSON file",
+			Long: `
+Each line of the file (or "-" for stdin) is a JSON object naming an operation, its
+arguments, and its payload:
+
+  {"op": "incident.create", "payload": {"incidentNumber": "2024-001"}}
+  {"op": "incident-exposure.patch", "args": ["$1.incidentID", "123"], "payload": {...}}
+
+Blank lines and lines starting with "#" are ignored.  A string such as "$1.incidentID"
+refers to a field in the result of the first operation; operations are numbered from 1
+without counting the blank lines and comments.  Each result has both its operation
+"number" and its "line" in the file.
+
+Operations: incident.create, incident.get, incident.delete, incident.patch,
+incident.upsert (with an optional "key"), incident-exposure.create,
+incident-exposure.get, incident-exposure.delete, incident-exposure.patch,
+exposure-location.get, exposure-location.put, exposure-apparatus.create, user.get,
+user.patch.  The patch operations take an optional row version as their last argument;
+if it is not given, the current one is looked up.
+
+Each line's result is written as a line of NDJSON.  With "--dry-run", the reads still
+run, and each change is written as the request that would have been sent.
+			`,
+			Args: cobra.ExactArgs(1),
+			Run:  doBatchRun,
+		}
+		subCommand.Flags().Bool("continue-on-error", false, "Keep going after a line fails; lines that refer to it are skipped.")
+		subCommand.Flags().String("results", "-", `The file to write the NDJSON results to ("-" for stdout).`)
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "ems",
+			Short: "EMS sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "export [<filter>]",
+			Short: "Export patient care reports",
+			Long: `
+Writes every patient care report (following the pages) as a NEMSIS 3.5 EMS data set for
+submission to the state.  Times that a report is missing are taken from its unit on the
+exposure, and required elements that still have no value are sent as "Not Recorded".
+The required sections that Emergency Reporting has no data for (such as eSituation and
+eArrest) are sent with only "Not Recorded" values.
+
+Example filter: "incidentNumber eq '2021-0002'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doEMSExport,
+		}
+		subCommand.Flags().Bool("nemsis3-xml", false, "Export as NEMSIS 3 XML.")
+		subCommand.Flags().String("agency-state-id", "", "The agency's unique ID from the state EMS office.")
+		subCommand.Flags().String("agency-number", "", "The agency's number from the state EMS office.")
+		subCommand.Flags().String("agency-name", "", "The agency's name.")
+		subCommand.Flags().String("state", "", "The ANSI code of the state (such as \"17\" for Illinois).")
+		subCommand.Flags().String("software-creator", "", "The company that wrote the software, as registered with the state.")
+		subCommand.Flags().String("software-name", "emergencyreporting", "The name of the software, as registered with the state.")
+		subCommand.Flags().String("software-version", "", "The version of the software, as registered with the state.")
+		subCommand.Flags().String("unit-capability", "", "The NEMSIS code for the units' transport and equipment capability (eResponse.07).")
+		subCommand.Flags().String("unit-level-of-care", "", "The NEMSIS code for the units' level of care (eResponse.15).")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the report date/times are in.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <pcr-id>",
+			Short: "Get a patient care report",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doEMSGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List patient care reports",
+			Long: `
+Example filter: "incidentID eq '1'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doEMSList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of patient care reports instead of just the first.")
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "event",
+			Short: "Event sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create an event",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doEventCreate,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <event-id>",
+			Short: "Get an event",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doEventGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List events",
+			Long: `
+Example filter: "eventType eq 'Standby'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doEventList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of events instead of just the first.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <event-id> <json>",
+			Short: "Update an event",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doEventUpdate,
+		}
+		command.AddCommand(subCommand)
+
+		attendeeCommand := &cobra.Command{
+			Use:   "attendee",
+			Short: "Event attendee sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(attendeeCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "add <event-id> <user-id>",
+			Short: "Add an attendee to an event",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doEventAttendeeAdd,
+		}
+		subCommand.Flags().String("hours", "", "The hours that the attendee gets; the default is the event hours.")
+		subCommand.Flags().String("paygrade-name", "", "The pay grade; the default is the user's default event pay grade.")
+		subCommand.Flags().String("paygrade-rate", "", "The hourly rate; the default is the user's default event pay rate.")
+		subCommand.Flags().String("status", emergencyreporting.EventAttendeeStatusAttended, "The attendance status.")
+		attendeeCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <event-id> [<filter>]",
+			Short: "List the attendees of an event",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doEventAttendeeList,
+		}
+		attendeeCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <event-id> <attendee-id> <json>",
+			Short: "Update an attendee",
+			Long:  ``,
+			Args:  cobra.ExactArgs(3),
+			Run:   doEventAttendeeUpdate,
+		}
+		attendeeCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "expirations",
+			Short: "List the licenses and certifications that are expiring",
+			Long: `
+Lists every user's licenses and certifications that expire within --days days, soonest first.
+Each one's "kind" is "license" or "certification".  The licenses and certifications are looked
+up for each user, so this makes a couple of requests per user.
+			`,
+			Args: cobra.NoArgs,
+			Run:  doExpirations,
+		}
+		command.Flags().Int("days", 30, "Include the licenses that expire within this many days.")
+		command.Flags().Bool("include-expired", false, "Include the licenses that have already expired.")
+		command.Flags().String("timezone", "Local", "The time zone that the expiration dates are in.")
+		rootCommand.AddCommand(command)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "export",
+			Short: "Export sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "neris <incident-id> [...]",
+			Short: "Export incidents in the NERIS incident format",
+			Long: `
+Maps each incident (with its exposures, locations, apparatuses, and crew members)
+to the NERIS incident JSON schema.
+
+Each result lists the fields that are missing or that could not be mapped.
+			`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  doExportNERIS,
+		}
+		subCommand.Flags().String("department-id", "", "The NERIS ID of the department.")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the incident date/times are in.")
+		command.AddCommand(subCommand)
+	}
 	{
 		command := &cobra.Command{
 			Use:   "exposure",
@@ -174,6 +477,19 @@ Example filter: 'incidentID eq 1234'
 			Run:   doIncidentExposurePatch,
 		}
 		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "edit <incident-id> <exposure-id>",
+			Short: "Edit an exposure in $EDITOR",
+			Long: `
+Opens the exposure in $VISUAL or $EDITOR; when the editor is closed, the fields that
+changed are shown and, once confirmed, patched.
+			`,
+			Args: cobra.ExactArgs(2),
+			Run:  doIncidentExposureEdit,
+		}
+		addEditFlags(subCommand)
+		command.AddCommand(subCommand)
 	}
 	{
 		command := &cobra.Command{
@@ -192,6 +508,19 @@ Example filter: 'incidentID eq 1234'
 			Run:   doExposureLocationGet,
 		}
 		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "edit <exposure-id>",
+			Short: "Edit an exposure location in $EDITOR",
+			Long: `
+Opens the exposure location in $VISUAL or $EDITOR; when the editor is closed, the
+fields that changed are shown and, once confirmed, the location is updated.
+			`,
+			Args: cobra.ExactArgs(1),
+			Run:  doExposureLocationEdit,
+		}
+		addEditFlags(subCommand)
+		command.AddCommand(subCommand)
 	}
 	{
 		command := &cobra.Command{
@@ -240,114 +569,1225 @@ Example filter: 'incidentID eq 1234'
 	}
 	{
 		command := &cobra.Command{
-			Use:   "incident",
-			Short: "Incident sub-command",
+			Use:   "firemarshal",
+			Short: "Fire marshal sub-command",
 			Long:  ``,
 			Run:   nil,
 		}
 		rootCommand.AddCommand(command)
 
 		subCommand := &cobra.Command{
-			Use:   "create <json>",
-			Short: "Create an incident",
-			Long:  ``,
-			Args:  cobra.ExactArgs(1),
-			Run:   doIncidentCreate,
+			Use:   "overdue",
+			Short: "Report the overdue re-inspections by occupancy",
+			Long: `
+Lists the inspections that needed a re-inspection before the given date but have not passed one,
+grouped by occupancy.  An inspection is due when its latest pending re-inspection is scheduled or,
+if none is, at its re-inspection due date.
+			`,
+			Args: cobra.NoArgs,
+			Run:  doFireMarshalOverdue,
 		}
+		subCommand.Flags().String("as-of", "", "The date (YYYY-MM-DD) to check against; the default is now.")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the date/times are in.")
 		command.AddCommand(subCommand)
 
+		inspectionCommand := &cobra.Command{
+			Use:   "inspection",
+			Short: "Fire marshal inspection sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(inspectionCommand)
+
 		subCommand = &cobra.Command{
-			Use:   "delete <id> [...]",
-			Short: "Delete an incident",
+			Use:   "create <json>",
+			Short: "Create an inspection",
 			Long:  ``,
-			Args:  cobra.MinimumNArgs(1),
-			Run:   doIncidentDelete,
+			Args:  cobra.ExactArgs(1),
+			Run:   doFireMarshalInspectionCreate,
 		}
-		command.AddCommand(subCommand)
+		inspectionCommand.AddCommand(subCommand)
 
 		subCommand = &cobra.Command{
-			Use:   "get <incident-id>",
-			Short: "Get an incident",
+			Use:   "get <inspection-id>",
+			Short: "Get an inspection",
 			Long:  ``,
 			Args:  cobra.ExactArgs(1),
-			Run:   doIncidentGet,
+			Run:   doFireMarshalInspectionGet,
 		}
-		command.AddCommand(subCommand)
+		inspectionCommand.AddCommand(subCommand)
 
 		subCommand = &cobra.Command{
 			Use:   "list [<filter>]",
-			Short: "List all incidents",
+			Short: "List inspections",
 			Long: `
-Example filter: 'dispatchRunNumber eq 1234'
+Example filter: 'occupancyID eq 1234'
 			`,
-			Run: doIncidentList,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doFireMarshalInspectionList,
 		}
-		command.AddCommand(subCommand)
+		subCommand.Flags().Bool("all", false, "Get every page of inspections instead of just the first.")
+		inspectionCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <inspection-id> <json>",
+			Short: "Update an inspection",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doFireMarshalInspectionUpdate,
+		}
+		inspectionCommand.AddCommand(subCommand)
+
+		reInspectionCommand := &cobra.Command{
+			Use:   "reinspection",
+			Short: "Fire marshal re-inspection sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(reInspectionCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <inspection-id> <json>",
+			Short: "Schedule a re-inspection",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doFireMarshalReInspectionCreate,
+		}
+		reInspectionCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <inspection-id> <reinspection-id>",
+			Short: "Get a re-inspection",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doFireMarshalReInspectionGet,
+		}
+		reInspectionCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <inspection-id> [<filter>]",
+			Short: "List the re-inspections for an inspection",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doFireMarshalReInspectionList,
+		}
+		reInspectionCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <inspection-id> <reinspection-id> <json>",
+			Short: "Update a re-inspection",
+			Long: `
+To record a passing re-inspection:
+
+	{"completedDateTime": "2020-01-02T03:04:05Z", "passed": "1"}
+			`,
+			Args: cobra.ExactArgs(3),
+			Run:  doFireMarshalReInspectionUpdate,
+		}
+		reInspectionCommand.AddCommand(subCommand)
+
+		violationCommand := &cobra.Command{
+			Use:   "violation",
+			Short: "Fire marshal violation sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(violationCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <inspection-id> <json>",
+			Short: "Add a violation to an inspection",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doFireMarshalViolationCreate,
+		}
+		violationCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <inspection-id> <violation-id>",
+			Short: "Get a violation",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doFireMarshalViolationGet,
+		}
+		violationCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <inspection-id> [<filter>]",
+			Short: "List the violations for an inspection",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doFireMarshalViolationList,
+		}
+		violationCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <inspection-id> <violation-id> <json>",
+			Short: "Update a violation",
+			Long:  ``,
+			Args:  cobra.ExactArgs(3),
+			Run:   doFireMarshalViolationUpdate,
+		}
+		violationCommand.AddCommand(subCommand)
 	}
 	{
 		command := &cobra.Command{
-			Use:   "station",
-			Short: "Station sub-command",
+			Use:   "hydrant",
+			Short: "Hydrant sub-command",
 			Long:  ``,
 			Run:   nil,
 		}
 		rootCommand.AddCommand(command)
 
 		subCommand := &cobra.Command{
-			Use:   "get <station-id>",
-			Short: "Get a station",
+			Use:   "create <json>",
+			Short: "Create a hydrant",
 			Long:  ``,
 			Args:  cobra.ExactArgs(1),
-			Run:   doStationGet,
+			Run:   doHydrantCreate,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "export [<filter>]",
+			Short: "Export hydrants",
+			Long: `
+Writes every hydrant (following the pages) as a GeoJSON feature collection of points, which
+can be loaded into most mapping tools.  Hydrants without coordinates are left out.
+The collection is written with the usual output flags, so "--output yaml" and "--template"
+work as they do everywhere else; the default (JSON) is what mapping tools expect.
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doHydrantExport,
+		}
+		subCommand.Flags().Bool("geojson", false, "Export as GeoJSON.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <hydrant-id>",
+			Short: "Get a hydrant",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doHydrantGet,
 		}
 		command.AddCommand(subCommand)
 
 		subCommand = &cobra.Command{
 			Use:   "list [<filter>]",
-			Short: "List all stations",
+			Short: "List hydrants",
 			Long: `
-Example filter: 'stationNumber eq 2'
+Example filter: 'outOfService eq 1'
 			`,
-			Run: doStationList,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doHydrantList,
 		}
+		subCommand.Flags().Bool("all", false, "Get every page of hydrants instead of just the first.")
 		command.AddCommand(subCommand)
-	}
-	{
+
+		subCommand = &cobra.Command{
+			Use:   "update <hydrant-id> <json>",
+			Short: "Update a hydrant",
+			Long: `
+Only the fields in the JSON are changed.  To take a hydrant out of service:
+
+	{"outOfService": "1", "outOfServiceReason": "Broken stem", "outOfServiceDateTime": "2020-01-02T03:04:05Z"}
+			`,
+			Args: cobra.ExactArgs(2),
+			Run:  doHydrantUpdate,
+		}
+		command.AddCommand(subCommand)
+
+		flowTestCommand := &cobra.Command{
+			Use:   "flow-test",
+			Short: "Hydrant flow test sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(flowTestCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <hydrant-id> <json>",
+			Short: "Add a flow test to a hydrant",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doHydrantFlowTestCreate,
+		}
+		flowTestCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <hydrant-id> [<filter>]",
+			Short: "List the flow tests for a hydrant",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doHydrantFlowTestList,
+		}
+		flowTestCommand.AddCommand(subCommand)
+
+		inspectionCommand := &cobra.Command{
+			Use:   "inspection",
+			Short: "Hydrant inspection sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(inspectionCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <hydrant-id> <json>",
+			Short: "Add an inspection to a hydrant",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doHydrantInspectionCreate,
+		}
+		inspectionCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <hydrant-id> [<filter>]",
+			Short: "List the inspections for a hydrant",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doHydrantInspectionList,
+		}
+		inspectionCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "import",
+			Short: "Import sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "cad <mapping-file> [<file> ...]",
+			Short: "Import CAD files",
+			Long: `
+Creates or updates incidents from CAD (JSON or XML) files using a YAML mapping file.
+
+Records are matched to existing incidents using the mapping's key field, so importing
+the same file twice will update the incident instead of creating a duplicate.
+
+With --watch, this will keep importing files as they appear in the given directory.
+			`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  doImportCAD,
+		}
+		subCommand.Flags().String("watch", "", "Watch this directory for new CAD files.")
+		subCommand.Flags().Duration("interval", 10*time.Second, "How often to check the watched directory.")
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "incident",
+			Short: "Incident sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create an incident",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doIncidentCreate,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "delete <id> [...]",
+			Short: "Delete incidents",
+			Long:  ``,
+			Args:  cobra.MinimumNArgs(1),
+			Run:   doIncidentDelete,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "edit <incident-id>",
+			Short: "Edit an incident in $EDITOR",
+			Long: `
+Opens the incident in $VISUAL or $EDITOR; when the editor is closed, the fields that
+changed are shown and, once confirmed, patched.
+			`,
+			Args: cobra.ExactArgs(1),
+			Run:  doIncidentEdit,
+		}
+		addEditFlags(subCommand)
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <incident-id> [...]",
+			Short: "Get incidents",
+			Long: `
+With one ID, the incident itself is written; with more, a list of incidents is written.
+			`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  doIncidentGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "upsert <json>",
+			Short: "Create an incident, or update the existing one",
+			Long: `
+Looks up the incident by its key field; if found, only the fields that differ are
+patched; otherwise, the incident is created.
+
+The key must be one of: incidentNumber, partnerIncidentNumber, dispatchRunNumber.
+			`,
+			Args: cobra.ExactArgs(1),
+			Run:  doIncidentUpsert,
+		}
+		subCommand.Flags().String("key", emergencyreporting.IncidentKeyIncidentNumber, "The incident field used to find the existing incident.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List all incidents",
+			Long: `
+Example filter: 'dispatchRunNumber eq 1234'
+			`,
+			Run: doIncidentList,
+		}
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "inventory",
+			Short: "Inventory sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		itemCommand := &cobra.Command{
+			Use:   "item",
+			Short: "Inventory item sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(itemCommand)
+
+		subCommand := &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create an item",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doInventoryItemCreate,
+		}
+		itemCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <item-id>",
+			Short: "Get an item",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doInventoryItemGet,
+		}
+		itemCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List items",
+			Long: `
+Example filter: "category eq 'SCBA'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doInventoryItemList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of items instead of just the first.")
+		itemCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <item-id> <json>",
+			Short: "Update an item",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doInventoryItemUpdate,
+		}
+		itemCommand.AddCommand(subCommand)
+
+		locationCommand := &cobra.Command{
+			Use:   "location",
+			Short: "Inventory location sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(locationCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create a location",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doInventoryLocationCreate,
+		}
+		locationCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List locations",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doInventoryLocationList,
+		}
+		locationCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "maintenance",
+			Short: "Maintenance sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "due",
+			Short: "List the scheduled maintenance that is coming due",
+			Long: `
+Lists the scheduled maintenance (by apparatus) that is due within --days days, soonest first.  Overdue
+maintenance is always included and has a negative "daysRemaining".
+			`,
+			Args: cobra.NoArgs,
+			Run:  doMaintenanceDue,
+		}
+		subCommand.Flags().String("as-of", "", "The date (YYYY-MM-DD) to count from; the default is now.")
+		subCommand.Flags().Int("days", 30, "Include the maintenance that is due within this many days.")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the dates are in.")
+		command.AddCommand(subCommand)
+
+		recordCommand := &cobra.Command{
+			Use:   "record",
+			Short: "Maintenance record sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(recordCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create a record",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doMaintenanceRecordCreate,
+		}
+		recordCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <record-id>",
+			Short: "Get a record",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doMaintenanceRecordGet,
+		}
+		recordCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List records",
+			Long: `
+Example filter: "departmentApparatusID eq 1234 and status eq 'Open'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doMaintenanceRecordList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of records instead of just the first.")
+		recordCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <record-id> <json>",
+			Short: "Update a record",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doMaintenanceRecordUpdate,
+		}
+		recordCommand.AddCommand(subCommand)
+
+		scheduleCommand := &cobra.Command{
+			Use:   "schedule",
+			Short: "Maintenance schedule sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(scheduleCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create a schedule",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doMaintenanceScheduleCreate,
+		}
+		scheduleCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <schedule-id>",
+			Short: "Get a schedule",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doMaintenanceScheduleGet,
+		}
+		scheduleCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List schedules",
+			Long: `
+Example filter: "departmentApparatusID eq 1234"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doMaintenanceScheduleList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of schedules instead of just the first.")
+		scheduleCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <schedule-id> <json>",
+			Short: "Update a schedule",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doMaintenanceScheduleUpdate,
+		}
+		scheduleCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "occupancy",
+			Short: "Occupancy sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create an occupancy",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doOccupancyCreate,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <occupancy-id>",
+			Short: "Get an occupancy",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doOccupancyGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List occupancies",
+			Long: `
+Example filter: 'zipCode eq 62701'
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doOccupancyList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of occupancies instead of just the first.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "match <exposure-id> [...]",
+			Short: "Link exposure locations to occupancies",
+			Long: `
+Finds the occupancy for each exposure's location: an occupancy with the same address wins;
+otherwise, the nearest occupancy within --max-distance meters is used (if both have coordinates).
+			`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  doOccupancyMatch,
+		}
+		subCommand.Flags().Float64("max-distance", 100, "The farthest (in meters) that the nearest occupancy can be; 0 only matches addresses.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <occupancy-id> <json>",
+			Short: "Update an occupancy",
+			Long: `
+Only the fields in the JSON are changed.
+			`,
+			Args: cobra.ExactArgs(2),
+			Run:  doOccupancyUpdate,
+		}
+		command.AddCommand(subCommand)
+
+		contactCommand := &cobra.Command{
+			Use:   "contact",
+			Short: "Occupancy contact sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(contactCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <occupancy-id> <json>",
+			Short: "Add a contact to an occupancy",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doOccupancyContactCreate,
+		}
+		contactCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <occupancy-id> [<filter>]",
+			Short: "List the contacts for an occupancy",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doOccupancyContactList,
+		}
+		contactCommand.AddCommand(subCommand)
+
+		hazardCommand := &cobra.Command{
+			Use:   "hazard",
+			Short: "Occupancy hazard sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(hazardCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <occupancy-id> <json>",
+			Short: "Add a hazard to an occupancy",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doOccupancyHazardCreate,
+		}
+		hazardCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <occupancy-id> [<filter>]",
+			Short: "List the hazards for an occupancy",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doOccupancyHazardList,
+		}
+		hazardCommand.AddCommand(subCommand)
+
+		prePlanCommand := &cobra.Command{
+			Use:   "pre-plan",
+			Short: "Occupancy pre-plan sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(prePlanCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <occupancy-id> <json>",
+			Short: "Add a pre-plan to an occupancy",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doOccupancyPrePlanCreate,
+		}
+		prePlanCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <occupancy-id> [<filter>]",
+			Short: "List the pre-plans for an occupancy",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doOccupancyPrePlanList,
+		}
+		prePlanCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "payroll",
+			Short: "Payroll sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "export",
+			Short: "Export the payroll as CSV",
+			Long: `
+Totals each member's event hours in the period by pay grade and writes them (with the hours times the
+rate) as CSV for the payroll system.  The period is a month ("2026-10") or an inclusive range of dates
+("2026-10-01..2026-10-15").  Attendees use the user's default event pay grade unless they have their own;
+a pay grade without a rate gets the rate of the pay grade with that name.  If anyone's hours or rate
+can't be worked out, they are listed and nothing is written.
+			`,
+			Args: cobra.NoArgs,
+			Run:  doPayrollExport,
+		}
+		subCommand.Flags().String("period", "", "The payroll period.")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the date/times are in.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "history [<filter>]",
+			Short: "List the payroll periods that have been exported",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doPayrollHistory,
+		}
+		command.AddCommand(subCommand)
+
+		paygradeCommand := &cobra.Command{
+			Use:   "paygrade",
+			Short: "Pay grade sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(paygradeCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List the pay grades",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doPayrollPaygradeList,
+		}
+		paygradeCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "roster",
+			Short: "Roster sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create a daily roster",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doRosterCreate,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <roster-id>",
+			Short: "Get a daily roster",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doRosterGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List daily rosters",
+			Long: `
+Example filter: "rosterDate eq '2026-10-18'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doRosterList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of rosters instead of just the first.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "on-duty <department-apparatus-id> [<date-time>]",
+			Short: "List who was on duty on an apparatus",
+			Long: `
+Lists the roster assignments for the apparatus at the date/time (such as YYYY-MM-DDTHH:MM, or any
+other form that the API uses), or now if it is not given.  Rosters from the day before are checked
+too, since 24-hour shifts run past midnight.
+			`,
+			Args: cobra.RangeArgs(1, 2),
+			Run:  doRosterOnDuty,
+		}
+		subCommand.Flags().String("timezone", "Local", "The time zone that the date/times are in.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "populate-crew <exposure-id> [...]",
+			Short: "Add the rostered crews to exposures",
+			Long: `
+For each apparatus on the exposure, adds whoever was on duty on it (according to the rosters) at the
+dispatch time as a crew member.  People who are already crew members on the apparatus are skipped,
+so this can be run more than once.
+			`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  doRosterPopulateCrew,
+		}
+		subCommand.Flags().String("timezone", "Local", "The time zone that the date/times are in.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <roster-id> <json>",
+			Short: "Update a daily roster",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doRosterUpdate,
+		}
+		command.AddCommand(subCommand)
+
+		assignmentCommand := &cobra.Command{
+			Use:   "assignment",
+			Short: "Roster assignment sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(assignmentCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "add <roster-id> <json>",
+			Short: "Add an assignment to a roster",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doRosterAssignmentAdd,
+		}
+		assignmentCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <roster-id> [<filter>]",
+			Short: "List the assignments on a roster",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doRosterAssignmentList,
+		}
+		assignmentCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <roster-id> <assignment-id> <json>",
+			Short: "Update an assignment",
+			Long:  ``,
+			Args:  cobra.ExactArgs(3),
+			Run:   doRosterAssignmentUpdate,
+		}
+		assignmentCommand.AddCommand(subCommand)
+
+		positionCommand := &cobra.Command{
+			Use:   "position",
+			Short: "Roster position sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(positionCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List the roster positions",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doRosterPositionList,
+		}
+		positionCommand.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "shift",
+			Short: "Shift sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "calendar [<filter>]",
+			Short: "Show the shift calendar",
+			Long: `
+Lists which shift is on duty each day (following the pages).
+
+Example filter: "shiftDate ge '2026-10-01' and shiftDate le '2026-10-31'"
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doShiftCalendar,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List the shifts",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doShiftList,
+		}
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "station",
+			Short: "Station sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "get <station-id>",
+			Short: "Get a station",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doStationGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List all stations",
+			Long: `
+Example filter: 'stationNumber eq 2'
+			`,
+			Run: doStationList,
+		}
+		command.AddCommand(subCommand)
+	}
+	{
+		command := &cobra.Command{
+			Use:   "training",
+			Short: "Training sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "report [<filter>]",
+			Short: "Report the training hours by member and category",
+			Long: `
+Totals each member's training hours in each category, such as for recertification tracking.
+
+Example filter: 'categoryID eq 3'
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doTrainingReport,
+		}
+		subCommand.Flags().String("from", "", "The first date (YYYY-MM-DD) to include.")
+		subCommand.Flags().String("to", "", "The last date (YYYY-MM-DD) to include.")
+		subCommand.Flags().String("timezone", "Local", "The time zone that the date/times are in.")
+		command.AddCommand(subCommand)
+
+		attendeeCommand := &cobra.Command{
+			Use:   "attendee",
+			Short: "Training class attendee sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(attendeeCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "add <class-id> <user-id>",
+			Short: "Add an attendee to a class",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doTrainingAttendeeAdd,
+		}
+		subCommand.Flags().String("hours", "", "The hours that the attendee gets; the default is the class hours.")
+		subCommand.Flags().String("status", "Attended", "The attendance status.")
+		attendeeCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "import <class-id> <csv-file>",
+			Short: "Add attendees to a class from a CSV file",
+			Long: `
+Adds everyone in the CSV file ("-" for stdin) to the class.  The file must have a header row with an
+"agencyPersonnelID" column, which is matched against each user's personnel ID; "hours" and "status"
+columns are optional.
+
+	agencyPersonnelID,hours
+	1001,2.5
+	1002,
+			`,
+			Args: cobra.ExactArgs(2),
+			Run:  doTrainingAttendeeImport,
+		}
+		attendeeCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list <class-id> [<filter>]",
+			Short: "List the attendees of a class",
+			Long:  ``,
+			Args:  cobra.RangeArgs(1, 2),
+			Run:   doTrainingAttendeeList,
+		}
+		attendeeCommand.AddCommand(subCommand)
+
+		categoryCommand := &cobra.Command{
+			Use:   "category",
+			Short: "Training category sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(categoryCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List the training categories",
+			Long:  ``,
+			Args:  cobra.MaximumNArgs(1),
+			Run:   doTrainingCategoryList,
+		}
+		categoryCommand.AddCommand(subCommand)
+
+		classCommand := &cobra.Command{
+			Use:   "class",
+			Short: "Training class sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(classCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "create <json>",
+			Short: "Create a class",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doTrainingClassCreate,
+		}
+		classCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "get <class-id>",
+			Short: "Get a class",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doTrainingClassGet,
+		}
+		classCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List classes",
+			Long: `
+Example filter: 'categoryID eq 3'
+			`,
+			Args: cobra.MaximumNArgs(1),
+			Run:  doTrainingClassList,
+		}
+		subCommand.Flags().Bool("all", false, "Get every page of classes instead of just the first.")
+		classCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <class-id> <json>",
+			Short: "Update a class",
+			Long:  ``,
+			Args:  cobra.ExactArgs(2),
+			Run:   doTrainingClassUpdate,
+		}
+		classCommand.AddCommand(subCommand)
+	}
+	{
 		command := &cobra.Command{
 			Use:   "user",
 			Short: "User sub-command",
 			Long:  ``,
 			Run:   nil,
 		}
-		rootCommand.AddCommand(command)
+		rootCommand.AddCommand(command)
+
+		subCommand := &cobra.Command{
+			Use:   "get <user-id>",
+			Short: "Get a user",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			Run:   doUserGet,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "list [<filter>]",
+			Short: "List all users",
+			Long: `
+Example filter: 'stationNumber eq 2'
+			`,
+			Run: doUserList,
+		}
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "patch <user-id> [<operation> <path> [<value>]]",
+			Short: "Patch a user",
+			Long: `
+Applies a single JSON Patch (RFC 6902) operation (add, remove, replace, or test) to the
+user, or, with "--file", a whole JSON Patch document:
+
+  [
+    {"op": "test", "path": "/login", "value": "jdoe"},
+    {"op": "replace", "path": "/title", "value": "Captain"},
+    {"op": "remove", "path": "/shift"}
+  ]
+
+The value is sent as a string unless "--json" is given.
+			`,
+			Args: cobra.RangeArgs(1, 4),
+			Run:  doUserPatch,
+		}
+		subCommand.Flags().String("file", "", `Read the JSON Patch document from this file ("-" for stdin).`)
+		subCommand.Flags().Bool("json", false, "Parse the value as JSON.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "edit <user-id>",
+			Short: "Edit a user in $EDITOR",
+			Long: `
+Opens the user in $VISUAL or $EDITOR; when the editor is closed, the fields that
+changed are shown and, once confirmed, patched.
+			`,
+			Args: cobra.ExactArgs(1),
+			Run:  doUserEdit,
+		}
+		addEditFlags(subCommand)
+		command.AddCommand(subCommand)
+
+		licenseCommand := &cobra.Command{
+			Use:   "license",
+			Short: "User license sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(licenseCommand)
 
-		subCommand := &cobra.Command{
-			Use:   "get <user-id>",
-			Short: "Get a user",
+		subCommand = &cobra.Command{
+			Use:   "list <user-id>",
+			Short: "List a user's licenses",
 			Long:  ``,
 			Args:  cobra.ExactArgs(1),
-			Run:   doUserGet,
+			Run:   doUserLicenseList,
 		}
-		command.AddCommand(subCommand)
+		licenseCommand.AddCommand(subCommand)
 
 		subCommand = &cobra.Command{
-			Use:   "list [<filter>]",
-			Short: "List all users",
+			Use:   "update <user-id> <license-id> <json>",
+			Short: "Update a license",
 			Long: `
-Example filter: 'stationNumber eq 2'
+Only the fields in the JSON are changed, such as:
+
+	{"licenseNumber": "P123456", "expirationDate": "2024-03-31"}
 			`,
-			Run: doUserList,
+			Args: cobra.ExactArgs(3),
+			Run:  doUserLicenseUpdate,
 		}
-		command.AddCommand(subCommand)
+		licenseCommand.AddCommand(subCommand)
+
+		certificationCommand := &cobra.Command{
+			Use:   "certification",
+			Short: "User certification sub-command",
+			Long:  ``,
+			Run:   nil,
+		}
+		command.AddCommand(certificationCommand)
 
 		subCommand = &cobra.Command{
-			Use:   "patch <user-id> <operation> <path> <value>",
-			Short: "Patch a user",
+			Use:   "list <user-id>",
+			Short: "List a user's certifications",
 			Long:  ``,
-			Args:  cobra.ExactArgs(4),
-			Run:   doUserPatch,
+			Args:  cobra.ExactArgs(1),
+			Run:   doUserCertificationList,
 		}
-		command.AddCommand(subCommand)
+		certificationCommand.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <user-id> <certification-id> <json>",
+			Short: "Update a certification",
+			Long: `
+Only the fields in the JSON are changed, such as:
+
+	{"certificationNumber": "HM-1234", "expirationDate": "2024-03-31"}
+			`,
+			Args: cobra.ExactArgs(3),
+			Run:  doUserCertificationUpdate,
+		}
+		certificationCommand.AddCommand(subCommand)
 	}
 
 	{
@@ -360,6 +1800,19 @@ Example filter: 'stationNumber eq 2'
 		rootCommand.AddCommand(command)
 
 		subCommand := &cobra.Command{
+			Use:   "export [<user-id>...]",
+			Short: "Export user contact info",
+			Long: `
+Writes a vCard for each user (or everyone, if no user IDs are given) with their phones,
+emails, and addresses, which can be imported into a phone for the phone tree.  Emergency
+contacts go in each vCard's note.
+			`,
+			Run: doUserContactInfoExport,
+		}
+		subCommand.Flags().Bool("vcard", false, "Export as vCards.")
+		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
 			Use:   "id <user-id>",
 			Short: "Get user contact info by user ID",
 			Long:  ``,
@@ -367,6 +1820,17 @@ Example filter: 'stationNumber eq 2'
 			Run:   doUserContactInfoID,
 		}
 		command.AddCommand(subCommand)
+
+		subCommand = &cobra.Command{
+			Use:   "update <user-id> <json>",
+			Short: "Replace user contact info",
+			Long: `
+Replaces the user's contact info with the JSON; anything left out (such as a phone) is removed.
+			`,
+			Args: cobra.ExactArgs(2),
+			Run:  doUserContactInfoUpdate,
+		}
+		command.AddCommand(subCommand)
 	}
 
 	err := rootCommand.Execute()
@@ -380,30 +1844,37 @@ Example filter: 'stationNumber eq 2'
 func makeClient(cmd *cobra.Command) *emergencyreporting.Client {
 	ctx := context.Background()
 
-	var configFile string
-	{
-		flag := cmd.Flag("config")
-		configFile = flag.Value.String()
-	}
-
-	if configFile == "" {
-		fmt.Printf("Missing config file.\n")
-		_ = cmd.Help()
+	config, err := loadConfig(cmd)
+	if err != nil {
+		fmt.Printf("Could not load configuration: %v\n", err)
 		os.Exit(1)
 	}
-
-	configBytes, err := ioutil.ReadFile(configFile)
+	err = resolveSecrets(config)
 	if err != nil {
-		fmt.Printf("Could not read '%s': %v\n", configFile, err)
+		fmt.Printf("Could not resolve secrets: %v\n", err)
 		os.Exit(1)
 	}
+	client := &config.Client
+	client.LeveledLogger = erlogrus.New(logrus.StandardLogger())
+	client.DryRun, _ = cmd.Flags().GetBool("dry-run")
 
-	var client *emergencyreporting.Client
-	err = json.Unmarshal(configBytes, &client)
-	if err != nil {
-		fmt.Printf("Could not parse '%s': %v\n", configFile, err)
+	recordDirectory := cmd.Flag("record").Value.String()
+	replayDirectory := cmd.Flag("replay").Value.String()
+	if recordDirectory != "" && replayDirectory != "" {
+		fmt.Printf("The --record and --replay flags cannot be used together.\n")
 		os.Exit(1)
 	}
+	if recordDirectory != "" {
+		client.SetTransport(&cassette.Recorder{Directory: recordDirectory})
+	}
+	if replayDirectory != "" {
+		replayer, err := cassette.NewReplayer(replayDirectory)
+		if err != nil {
+			fmt.Printf("Could not load cassette: %v\n", err)
+			os.Exit(1)
+		}
+		client.SetTransport(replayer)
+	}
 
 	var token string
 	{
@@ -427,6 +1898,25 @@ func makeClient(cmd *cobra.Command) *emergencyreporting.Client {
 	return client
 }
 
+// dryRunRequest returns the request from a dry-run error, or nil if the error is anything else.
+func dryRunRequest(err error) *emergencyreporting.DryRunRequest {
+	var dryRunError *emergencyreporting.DryRunError
+	if errors.As(err, &dryRunError) {
+		return dryRunError.Request
+	}
+	return nil
+}
+
+// printDryRun writes the request if the error is from a dry run, and returns whether it was.
+func printDryRun(cmd *cobra.Command, err error) bool {
+	request := dryRunRequest(err)
+	if request == nil {
+		return false
+	}
+	printOutput(cmd, request)
+	return true
+}
+
 func doLogin(cmd *cobra.Command, args []string) {
 	client := makeClient(cmd)
 	fmt.Printf("%s\n", client.Token)
@@ -460,17 +1950,15 @@ func doRaw(cmd *cobra.Command, args []string, method string, parameters []string
 	}
 
 	response, err := client.RawOperation(ctx, method, targetURL, optionsMap, headersMap, []byte(contents))
+	if printDryRun(cmd, err) {
+		return
+	}
 	if err != nil {
 		logrus.Errorf("Could not perform raw operation: [%T] %v", err, err)
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(response, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, response)
 }
 
 func doApparatusGet(cmd *cobra.Command, args []string) {
@@ -494,12 +1982,7 @@ func doApparatusGet(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(apparatusResponse.Apparatus, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, apparatusResponse.Apparatus)
 }
 
 func doApparatusList(cmd *cobra.Command, args []string) {
@@ -525,12 +2008,62 @@ func doApparatusList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get apparatuses: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(apparatusesResponse.Apparatuses, "" /*prefix*/, "\t" /*indent*/)
+	printOutput(cmd, apparatusesResponse.Apparatuses)
+}
+
+func doImportCAD(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	mappingFile := args[0]
+	args = args[1:]
+
+	watchDirectory := cmd.Flag("watch").Value.String()
+	if watchDirectory == "" && len(args) == 0 {
+		logrus.Errorf("Missing CAD file")
+		os.Exit(1)
+	}
+
+	mapping, err := cadimport.LoadMapping(mappingFile)
 	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		logrus.Errorf("Could not load mapping: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
+
+	importer := &cadimport.Importer{
+		Client:  client,
+		Mapping: mapping,
+		Logger:  logrus.StandardLogger(),
+	}
+
+	for _, filename := range args {
+		results, err := importer.ImportFile(ctx, filename)
+		for _, result := range results {
+			printOutput(cmd, result)
+		}
+		if err != nil {
+			logrus.Errorf("Could not import '%s': [%T] %v", filename, err, err)
+			os.Exit(1)
+		}
+	}
+
+	if watchDirectory != "" {
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			logrus.Errorf("Invalid interval: [%T] %v", err, err)
+			os.Exit(1)
+		}
+
+		err = importer.Watch(ctx, watchDirectory, interval, func(filename string, results []*cadimport.Result, err error) {
+			for _, result := range results {
+				printOutput(cmd, result)
+			}
+		})
+		if err != nil {
+			logrus.Errorf("Could not watch '%s': [%T] %v", watchDirectory, err, err)
+			os.Exit(1)
+		}
+	}
 }
 
 func doIncidentCreate(cmd *cobra.Command, args []string) {
@@ -555,58 +2088,65 @@ func doIncidentCreate(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 	postIncidentResponse, err := client.PostIncident(ctx, incident)
-	if err != nil {
-		logrus.Errorf("Could not create incident: [%T] %v", err, err)
-		os.Exit(1)
+	if printDryRun(cmd, err) {
+		return
 	}
-	jsonBytes, err := json.MarshalIndent(postIncidentResponse, "" /*prefix*/, "\t" /*indent*/)
 	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		logrus.Errorf("Could not create incident: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, postIncidentResponse)
 }
 
 func doIncidentDelete(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
 	client := makeClient(cmd)
 
-	for _, incidentID := range args {
+	results := runBulk(cmd, "delete incident", args, func(ctx context.Context, incidentID string) (interface{}, error) {
 		err := client.DeleteIncident(ctx, incidentID)
-		if err != nil {
-			logrus.Errorf("Could not delete incident '%s': [%T] %v", incidentID, err, err)
-			os.Exit(1)
+		if request := dryRunRequest(err); request != nil {
+			return request, nil
+		}
+		return nil, err
+	})
+	if client.DryRun {
+		var requests []*emergencyreporting.DryRunRequest
+		for _, value := range results.Values() {
+			requests = append(requests, value.(*emergencyreporting.DryRunRequest))
 		}
+		printOutput(cmd, requests)
+	}
+	if results.Failed() > 0 {
+		os.Exit(1)
 	}
 }
 
 func doIncidentGet(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
 	client := makeClient(cmd)
 
-	if len(args) < 1 {
-		logrus.Errorf("Missing incident ID")
-		os.Exit(1)
-	}
-	incidentID := args[0]
-	args = args[1:]
-	if len(args) > 0 {
-		logrus.Errorf("Too many arguments")
-		os.Exit(1)
-	}
+	results := runBulk(cmd, "get incident", args, func(ctx context.Context, incidentID string) (interface{}, error) {
+		incidentResponse, err := client.GetIncident(ctx, incidentID)
+		if err != nil {
+			return nil, err
+		}
+		return incidentResponse.Incident, nil
+	})
 
-	incidentResponse, err := client.GetIncident(ctx, incidentID)
-	if err != nil {
-		logrus.Errorf("Could not get incident '%s': [%T] %v", incidentID, err, err)
-		os.Exit(1)
+	if len(args) == 1 {
+		if results.Failed() > 0 {
+			os.Exit(1)
+		}
+		printOutput(cmd, results.Results[0].Value)
+		return
 	}
 
-	jsonBytes, err := json.MarshalIndent(incidentResponse.Incident, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+	incidents := []*emergencyreporting.Incident{}
+	for _, value := range results.Values() {
+		incidents = append(incidents, value.(*emergencyreporting.Incident))
+	}
+	printOutput(cmd, incidents)
+	if results.Failed() > 0 {
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
 }
 
 func doIncidentList(cmd *cobra.Command, args []string) {
@@ -633,12 +2173,70 @@ func doIncidentList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get incidents: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(incidentsResponse.Incidents, "" /*prefix*/, "\t" /*indent*/)
+	printOutput(cmd, incidentsResponse.Incidents)
+}
+
+func doExportNERIS(cmd *cobra.Command, args []string) {
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	options := emergencyreporting.NERISOptions{
+		DepartmentNERISID: cmd.Flag("department-id").Value.String(),
+		Location:          location,
+	}
+
+	results := runBulk(cmd, "export incident", args, func(ctx context.Context, incidentID string) (interface{}, error) {
+		incident, err := client.GetIncidentDetails(ctx, incidentID)
+		if err != nil {
+			return nil, err
+		}
+		return emergencyreporting.NERISFromIncident(incident, options), nil
+	})
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+
+	var exports []*emergencyreporting.NERISExport
+	for _, value := range results.Values() {
+		exports = append(exports, value.(*emergencyreporting.NERISExport))
+	}
+	printOutput(cmd, exports)
+}
+
+func doIncidentUpsert(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	if len(args) < 1 {
+		logrus.Errorf("Missing JSON")
+		os.Exit(1)
+	}
+	jsonString := args[0]
+	args = args[1:]
+	if len(args) > 0 {
+		logrus.Errorf("Too many arguments")
+		os.Exit(1)
+	}
+
+	var incident emergencyreporting.Incident
+	err := json.Unmarshal([]byte(jsonString), &incident)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	upsertIncidentResponse, err := client.UpsertIncident(ctx, incident, cmd.Flag("key").Value.String())
+	if printDryRun(cmd, err) {
+		return
+	}
 	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		logrus.Errorf("Could not upsert incident: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, upsertIncidentResponse)
 }
 
 func doIncidentExposureCreate(cmd *cobra.Command, args []string) {
@@ -668,16 +2266,14 @@ func doIncidentExposureCreate(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 	postExposureResponse, err := client.PostIncidentExposure(ctx, incidentID, exposure)
-	if err != nil {
-		logrus.Errorf("Could not create exposure: [%T] %v", err, err)
-		os.Exit(1)
+	if printDryRun(cmd, err) {
+		return
 	}
-	jsonBytes, err := json.MarshalIndent(postExposureResponse, "" /*prefix*/, "\t" /*indent*/)
 	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		logrus.Errorf("Could not create exposure: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, postExposureResponse)
 }
 
 func doIncidentExposureDelete(cmd *cobra.Command, args []string) {
@@ -701,6 +2297,9 @@ func doIncidentExposureDelete(cmd *cobra.Command, args []string) {
 	}
 
 	err := client.DeleteIncidentExposure(ctx, incidentID, exposureID)
+	if printDryRun(cmd, err) {
+		return
+	}
 	if err != nil {
 		logrus.Errorf("Could not delete exposure: [%T] %v", err, err)
 		os.Exit(1)
@@ -732,12 +2331,7 @@ func doExposureList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(exposuresResponse.Exposures, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, exposuresResponse.Exposures)
 }
 
 func doIncidentExposureGet(cmd *cobra.Command, args []string) {
@@ -766,12 +2360,7 @@ func doIncidentExposureGet(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(exposureResponse.Exposure, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, exposureResponse.Exposure)
 }
 
 func doIncidentExposureList(cmd *cobra.Command, args []string) {
@@ -807,12 +2396,7 @@ func doIncidentExposureList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(exposuresResponse.Exposures, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, exposuresResponse.Exposures)
 }
 
 func doIncidentExposurePatch(cmd *cobra.Command, args []string) {
@@ -862,17 +2446,15 @@ func doIncidentExposurePatch(cmd *cobra.Command, args []string) {
 	}
 
 	patchExposureResponse, err := client.PatchIncidentExposure(ctx, incidentID, exposureID, currentExposure.RowVersion, patchExposureRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
 	if err != nil {
 		logrus.Errorf("Error patching exposure: [%T] %v", err, err)
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(patchExposureResponse, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, patchExposureResponse)
 }
 
 func doExposureLocationGet(cmd *cobra.Command, args []string) {
@@ -896,12 +2478,7 @@ func doExposureLocationGet(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(response.Location, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, response.Location)
 }
 
 func doExposureMemberGet(cmd *cobra.Command, args []string) {
@@ -930,12 +2507,7 @@ func doExposureMemberGet(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	jsonBytes, err := json.MarshalIndent(memberResponse.CrewMember, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, memberResponse.CrewMember)
 }
 
 func doExposureMemberList(cmd *cobra.Command, args []string) {
@@ -962,12 +2534,7 @@ func doExposureMemberList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get exposure members: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(membersResponse.CrewMembers, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, membersResponse.CrewMembers)
 }
 
 func doExposureUserRoleList(cmd *cobra.Command, args []string) {
@@ -994,12 +2561,7 @@ func doExposureUserRoleList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get exposure member roles: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(rolesResponse.Roles, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, rolesResponse.Roles)
 }
 
 func doStationGet(cmd *cobra.Command, args []string) {
@@ -1032,12 +2594,7 @@ func doStationGet(cmd *cobra.Command, args []string) {
 		fmt.Printf("Station not found.\n")
 		return
 	} else {
-		jsonBytes, err := json.MarshalIndent(currentStation, "" /*prefix*/, "\t" /*indent*/)
-		if err != nil {
-			logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-			os.Exit(1)
-		}
-		fmt.Println(string(jsonBytes))
+		printOutput(cmd, currentStation)
 	}
 }
 
@@ -1064,12 +2621,7 @@ func doStationList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get stations: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(stationsResponse.Stations, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, stationsResponse.Stations)
 }
 
 func doUserGet(cmd *cobra.Command, args []string) {
@@ -1100,42 +2652,70 @@ func doUserGet(cmd *cobra.Command, args []string) {
 		fmt.Printf("User not found.\n")
 		return
 	}
-	jsonBytes, err := json.MarshalIndent(currentUser, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, currentUser)
 }
 
 func doUserPatch(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 	client := makeClient(cmd)
 
-	if len(args) < 1 {
-		logrus.Errorf("Missing user ID")
-		os.Exit(1)
-	}
 	userID := args[0]
+	args = args[1:]
 
-	if len(args) < 2 {
-		logrus.Errorf("Missing operation")
-		os.Exit(1)
-	}
-	operation := args[1]
-	if len(args) < 3 {
-		logrus.Errorf("Missing path")
-		os.Exit(1)
-	}
-	path := args[2]
-	if len(args) < 4 {
-		logrus.Errorf("Missing value")
-		os.Exit(1)
+	var patchUserRequest emergencyreporting.PatchUserRequest
+	if filename := cmd.Flag("file").Value.String(); filename != "" {
+		if len(args) > 0 {
+			logrus.Errorf("Too many arguments")
+			os.Exit(1)
+		}
+		var contents []byte
+		var err error
+		if filename == "-" {
+			contents, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			contents, err = ioutil.ReadFile(filename)
+		}
+		if err != nil {
+			logrus.Errorf("Could not read patch: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		err = json.Unmarshal(contents, &patchUserRequest)
+		if err != nil {
+			logrus.Errorf("Could not parse patch: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	} else {
+		if len(args) < 1 {
+			logrus.Errorf("Missing operation")
+			os.Exit(1)
+		}
+		operation := emergencyreporting.PatchOperation{
+			Operation: args[0],
+		}
+		if len(args) < 2 {
+			logrus.Errorf("Missing path")
+			os.Exit(1)
+		}
+		operation.Path = args[1]
+		if len(args) < 3 {
+			if operation.Operation != emergencyreporting.PatchOperationRemove {
+				logrus.Errorf("Missing value")
+				os.Exit(1)
+			}
+		} else if parseJSON, _ := cmd.Flags().GetBool("json"); parseJSON {
+			err := json.Unmarshal([]byte(args[2]), &operation.Value)
+			if err != nil {
+				logrus.Errorf("Could not parse value: [%T] %v", err, err)
+				os.Exit(1)
+			}
+		} else {
+			operation.Value = args[2]
+		}
+		patchUserRequest = append(patchUserRequest, operation)
 	}
-	value := args[3]
-	args = args[4:]
-	if len(args) > 0 {
-		logrus.Errorf("Too many arguments")
+	err := patchUserRequest.Validate()
+	if err != nil {
+		logrus.Errorf("Invalid patch: %v", err)
 		os.Exit(1)
 	}
 
@@ -1153,24 +2733,15 @@ func doUserPatch(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	patchUserRequest := emergencyreporting.PatchUserRequest{
-		{
-			Operation: operation,
-			Path:      path,
-			Value:     value,
-		},
-	}
 	patchResponse, err := client.PatchUser(ctx, currentUser.UserID, currentUser.RowVersion, patchUserRequest)
-	if err != nil {
-		logrus.Errorf("Could not patch user: [%T] %v", err, err)
-		os.Exit(1)
+	if printDryRun(cmd, err) {
+		return
 	}
-	jsonBytes, err := json.MarshalIndent(patchResponse, "" /*prefix*/, "\t" /*indent*/)
 	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
+		logrus.Errorf("Could not patch user: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, patchResponse)
 }
 
 func doUserList(cmd *cobra.Command, args []string) {
@@ -1196,12 +2767,7 @@ func doUserList(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get users: [%T] %v", err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(usersResponse.Users, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, usersResponse.Users)
 }
 
 func doUserContactInfoID(cmd *cobra.Command, args []string) {
@@ -1224,10 +2790,5 @@ func doUserContactInfoID(cmd *cobra.Command, args []string) {
 		logrus.Errorf("Could not get user contact info for user ID %s: [%T] %v", userID, err, err)
 		os.Exit(1)
 	}
-	jsonBytes, err := json.MarshalIndent(getUserContactInfoResponse.ContactInfo, "" /*prefix*/, "\t" /*indent*/)
-	if err != nil {
-		logrus.Errorf("Error writing JSON: [%T] %v", err, err)
-		os.Exit(1)
-	}
-	fmt.Println(string(jsonBytes))
+	printOutput(cmd, getUserContactInfoResponse.ContactInfo)
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+// mainProcessVariable tells the test binary to run `main` instead of the tests; see `runMain`.
+const mainProcessVariable = "ER_TEST_MAIN_PROCESS"
+
+// TestMainProcess is not a real test; it runs `main` with the arguments after "--" when `runMain` asks it to.
+func TestMainProcess(t *testing.T) {
+	if os.Getenv(mainProcessVariable) == "" {
+		return
+	}
+	args := os.Args
+	for index, arg := range args {
+		if arg == "--" {
+			args = args[index+1:]
+			break
+		}
+	}
+	os.Args = append([]string{"emergencyreporting"}, args...)
+	main()
+}
+
+// runMain runs the program (in a separate process, since it calls `os.Exit`) against the server, returning
+// its output and exit code.
+func runMain(t *testing.T, server *ertest.Server, args ...string) (string, int) {
+	directory, err := ioutil.TempDir("", "emergencyreporting")
+	if err != nil {
+		t.Fatalf("Could not create a directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	configBytes, err := json.Marshal(server.Client())
+	if err != nil {
+		t.Fatalf("Could not encode the configuration: %v", err)
+	}
+	configFilename := filepath.Join(directory, "config.json")
+	err = ioutil.WriteFile(configFilename, configBytes, 0600)
+	if err != nil {
+		t.Fatalf("Could not write the configuration: %v", err)
+	}
+
+	command := exec.Command(os.Args[0], append([]string{"-test.run=^TestMainProcess$", "--", "--config", configFilename}, args...)...)
+	command.Env = append(os.Environ(), mainProcessVariable+"=1")
+	var stdout bytes.Buffer
+	command.Stdout = &stdout
+	err = command.Run()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return stdout.String(), exitError.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("Could not run the program: %v", err)
+	}
+	return stdout.String(), 0
+}
+
+func TestDryRun(t *testing.T) {
+	server := ertest.NewServer()
+	defer server.Close()
+	incidentIDs := []string{
+		server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2026-0001"}),
+		server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2026-0002"}),
+	}
+	title := "Firefighter"
+	userID := server.AddUser(emergencyreporting.User{FullName: "Jane Doe", Title: &title})
+
+	rows := []struct {
+		description string
+		args        []string
+		reads       int      // The number of GET requests that are expected (such as the row version lookups).
+		methods     []string // The methods of the requests in the output.
+	}{
+		{
+			description: "Delete",
+			args:        append([]string{"incident", "delete"}, incidentIDs...),
+			methods:     []string{http.MethodDelete, http.MethodDelete},
+		},
+		{
+			description: "Patch",
+			args:        []string{"user", "patch", userID, "replace", "/title", "Captain"},
+			reads:       1,
+			methods:     []string{http.MethodPatch},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			before := len(server.Requests())
+			output, exitCode := runMain(t, server, append([]string{"--dry-run", "--log-level", "error"}, row.args...)...)
+			if exitCode != 0 {
+				t.Fatalf("Expected exit code 0; got %d (output: %s)", exitCode, output)
+			}
+
+			var requests []emergencyreporting.DryRunRequest
+			err := json.Unmarshal([]byte(output), &requests)
+			if err != nil {
+				// A single request is written by itself.
+				var request emergencyreporting.DryRunRequest
+				err = json.Unmarshal([]byte(output), &request)
+				requests = append(requests, request)
+			}
+			if err != nil {
+				t.Fatalf("Could not parse the output: %v (output: %s)", err, output)
+			}
+			if len(requests) != len(row.methods) {
+				t.Fatalf("Expected %d requests in the output; got %d (output: %s)", len(row.methods), len(requests), output)
+			}
+			for index, request := range requests {
+				if request.Method != row.methods[index] {
+					t.Errorf("Request %d: expected method %s; got %s", index, row.methods[index], request.Method)
+				}
+				if value := request.Header.Get("Authorization"); value != emergencyreporting.RedactedValue {
+					t.Errorf("Request %d: the token was not redacted: %q", index, value)
+				}
+			}
+
+			reads := 0
+			for _, request := range server.Requests()[before:] {
+				switch {
+				case strings.HasSuffix(request.Path, "/token"):
+				case request.Method == http.MethodGet:
+					reads++
+				default:
+					t.Errorf("The server got a %s request for %s", request.Method, request.Path)
+				}
+			}
+			if reads != row.reads {
+				t.Errorf("Expected %d reads; got %d", row.reads, reads)
+			}
+		})
+	}
+
+	for _, incidentID := range incidentIDs {
+		if server.Incident(incidentID) == nil {
+			t.Errorf("Incident %s was deleted", incidentID)
+		}
+	}
+	if user := server.User(userID); user == nil || user.Title == nil || *user.Title != "Firefighter" {
+		t.Errorf("The user was changed: %+v", user)
+	}
+}
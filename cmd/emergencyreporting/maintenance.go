@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doMaintenanceDue(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	asOf := time.Now().In(location)
+	if value := cmd.Flag("as-of").Value.String(); value != "" {
+		asOf, err = time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			logrus.Errorf("Could not parse the date: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	}
+	days, _ := cmd.Flags().GetInt("days")
+
+	options := emergencyreporting.MaintenanceDueOptions{
+		AsOf: asOf,
+		Days: days,
+	}
+	due, err := client.GetMaintenanceDue(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get the maintenance that is due: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, due)
+}
+
+func doMaintenanceRecordCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var record emergencyreporting.MaintenanceRecord
+	err := json.Unmarshal([]byte(args[0]), &record)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postRecordResponse, err := client.PostMaintenanceRecord(ctx, record)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create record: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postRecordResponse)
+}
+
+func doMaintenanceRecordGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	recordResponse, err := client.GetMaintenanceRecord(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get record: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, recordResponse.Record)
+}
+
+func doMaintenanceRecordList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		records, err := client.GetAllMaintenanceRecords(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get records: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, records)
+		return
+	}
+
+	recordsResponse, err := client.GetMaintenanceRecords(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get records: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, recordsResponse.Records)
+}
+
+func doMaintenanceRecordUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	recordID := args[0]
+
+	var patchRecordRequest emergencyreporting.PatchMaintenanceRecordRequest
+	err := json.Unmarshal([]byte(args[1]), &patchRecordRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	recordResponse, err := client.GetMaintenanceRecord(ctx, recordID)
+	if err != nil {
+		logrus.Errorf("Could not get record: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if recordResponse.Record == nil {
+		logrus.Errorf("Record not found")
+		os.Exit(1)
+	}
+
+	patchRecordResponse, err := client.PatchMaintenanceRecord(ctx, recordID, recordResponse.Record.RowVersion, patchRecordRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update record: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchRecordResponse)
+}
+
+func doMaintenanceScheduleCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var schedule emergencyreporting.MaintenanceSchedule
+	err := json.Unmarshal([]byte(args[0]), &schedule)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postScheduleResponse, err := client.PostMaintenanceSchedule(ctx, schedule)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create schedule: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postScheduleResponse)
+}
+
+func doMaintenanceScheduleGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	scheduleResponse, err := client.GetMaintenanceSchedule(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get schedule: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, scheduleResponse.Schedule)
+}
+
+func doMaintenanceScheduleList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		schedules, err := client.GetAllMaintenanceSchedules(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get schedules: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, schedules)
+		return
+	}
+
+	schedulesResponse, err := client.GetMaintenanceSchedules(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get schedules: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, schedulesResponse.Schedules)
+}
+
+func doMaintenanceScheduleUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	scheduleID := args[0]
+
+	var patchScheduleRequest emergencyreporting.PatchMaintenanceScheduleRequest
+	err := json.Unmarshal([]byte(args[1]), &patchScheduleRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	scheduleResponse, err := client.GetMaintenanceSchedule(ctx, scheduleID)
+	if err != nil {
+		logrus.Errorf("Could not get schedule: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if scheduleResponse.Schedule == nil {
+		logrus.Errorf("Schedule not found")
+		os.Exit(1)
+	}
+
+	patchScheduleResponse, err := client.PatchMaintenanceSchedule(ctx, scheduleID, scheduleResponse.Schedule.RowVersion, patchScheduleRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update schedule: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchScheduleResponse)
+}
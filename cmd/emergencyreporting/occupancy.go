@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// occupancyListOptions returns the options for the occupancy list commands, which take the occupancy ID
+// and then an optional filter.
+func occupancyListOptions(cmd *cobra.Command, args []string) map[string]string {
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+	return map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+}
+
+func doOccupancyContactCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var contact emergencyreporting.OccupancyContact
+	err := json.Unmarshal([]byte(args[1]), &contact)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postContactResponse, err := client.PostOccupancyContact(ctx, args[0], contact)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create contact: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postContactResponse)
+}
+
+func doOccupancyContactList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	contactsResponse, err := client.GetOccupancyContacts(ctx, args[0], occupancyListOptions(cmd, args))
+	if err != nil {
+		logrus.Errorf("Could not get contacts: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, contactsResponse.Contacts)
+}
+
+func doOccupancyCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var occupancy emergencyreporting.Occupancy
+	err := json.Unmarshal([]byte(args[0]), &occupancy)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postOccupancyResponse, err := client.PostOccupancy(ctx, occupancy)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create occupancy: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postOccupancyResponse)
+}
+
+func doOccupancyGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	occupancyResponse, err := client.GetOccupancy(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get occupancy: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, occupancyResponse.Occupancy)
+}
+
+func doOccupancyHazardCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var hazard emergencyreporting.OccupancyHazard
+	err := json.Unmarshal([]byte(args[1]), &hazard)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postHazardResponse, err := client.PostOccupancyHazard(ctx, args[0], hazard)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create hazard: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postHazardResponse)
+}
+
+func doOccupancyHazardList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	hazardsResponse, err := client.GetOccupancyHazards(ctx, args[0], occupancyListOptions(cmd, args))
+	if err != nil {
+		logrus.Errorf("Could not get hazards: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, hazardsResponse.Hazards)
+}
+
+func doOccupancyList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		occupancies, err := client.GetAllOccupancies(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get occupancies: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, occupancies)
+		return
+	}
+
+	occupanciesResponse, err := client.GetOccupancies(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get occupancies: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, occupanciesResponse.Occupancies)
+}
+
+func doOccupancyMatch(cmd *cobra.Command, args []string) {
+	client := makeClient(cmd)
+
+	maximumDistance, _ := cmd.Flags().GetFloat64("max-distance")
+
+	var occupancies []*emergencyreporting.Occupancy
+	{
+		var err error
+		occupancies, err = client.GetAllOccupancies(context.Background(), map[string]string{"limit": cmd.Flag("limit").Value.String()})
+		if err != nil {
+			logrus.Errorf("Could not get occupancies: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	}
+
+	results := runBulk(cmd, "match exposure", args, func(ctx context.Context, exposureID string) (interface{}, error) {
+		locationResponse, err := client.GetExposureLocation(ctx, exposureID)
+		if err != nil {
+			return nil, err
+		}
+		match := emergencyreporting.MatchOccupancy(locationResponse.Location, occupancies, maximumDistance)
+		if match == nil {
+			logrus.Warnf("No occupancy matches exposure %s", exposureID)
+		}
+		return match, nil
+	})
+
+	if len(args) == 1 {
+		if results.Failed() > 0 {
+			os.Exit(1)
+		}
+		printOutput(cmd, results.Results[0].Value)
+		return
+	}
+
+	matches := []*emergencyreporting.OccupancyMatch{}
+	for _, value := range results.Values() {
+		if match, ok := value.(*emergencyreporting.OccupancyMatch); ok && match != nil {
+			matches = append(matches, match)
+		}
+	}
+	printOutput(cmd, matches)
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func doOccupancyPrePlanCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var prePlan emergencyreporting.OccupancyPrePlan
+	err := json.Unmarshal([]byte(args[1]), &prePlan)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postPrePlanResponse, err := client.PostOccupancyPrePlan(ctx, args[0], prePlan)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create pre-plan: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postPrePlanResponse)
+}
+
+func doOccupancyPrePlanList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	prePlansResponse, err := client.GetOccupancyPrePlans(ctx, args[0], occupancyListOptions(cmd, args))
+	if err != nil {
+		logrus.Errorf("Could not get pre-plans: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, prePlansResponse.PrePlans)
+}
+
+func doOccupancyUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	occupancyID := args[0]
+
+	var patchOccupancyRequest emergencyreporting.PatchOccupancyRequest
+	err := json.Unmarshal([]byte(args[1]), &patchOccupancyRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	occupancyResponse, err := client.GetOccupancy(ctx, occupancyID)
+	if err != nil {
+		logrus.Errorf("Could not get occupancy: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if occupancyResponse.Occupancy == nil {
+		logrus.Errorf("Occupancy not found")
+		os.Exit(1)
+	}
+
+	patchOccupancyResponse, err := client.PatchOccupancy(ctx, occupancyID, occupancyResponse.Occupancy.RowVersion, patchOccupancyRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update occupancy: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchOccupancyResponse)
+}
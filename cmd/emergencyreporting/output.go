@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats.
+const (
+	outputJSON     = "json"
+	outputNDJSON   = "ndjson"
+	outputYAML     = "yaml"
+	outputCSV      = "csv"
+	outputTable    = "table"
+	outputTemplate = "template"
+)
+
+// defaultTableColumns are the columns shown by the "table" output format for each type.
+//
+// Types that are not listed here show all of their (flattened) fields.
+var defaultTableColumns = map[string][]string{
+	"Apparatus":               {"apparatusID", "departmentApparatusID", "departmentApparatusName", "apparatusTypeName", "stationName", "inService"},
+	"CrewMember":              {"exposureUserID", "userID", "apparatusID", "exposureID"},
+	"CrewMemberRole":          {"exposureUserRoleID", "exposureID", "nfirsCode"},
+	"DryRunRequest":           {"method", "url"},
+	"Event":                   {"eventID", "eventName", "eventType", "startDateTime", "hours", "isPaid"},
+	"EventAttendee":           {"attendeeID", "eventID", "userID", "hours", "paygradeName", "status"},
+	"Exposure":                {"exposureID", "incidentID", "incidentType", "shiftsOrPlatoon", "completedDateTime"},
+	"ExposureApparatus":       {"apparatusID", "agencyApparatusID", "dispatchDateTime", "arrivedDateTime", "wasCancelled"},
+	"ExposureLocation":        {"exposureID", "streetName", "city", "state", "zipCode", "propertyUse"},
+	"FireMarshalInspection":   {"inspectionID", "occupancyID", "inspectionType", "inspectionDateTime", "status", "passed", "reInspectionDueDateTime"},
+	"FireMarshalReInspection": {"reInspectionID", "inspectionID", "scheduledDateTime", "completedDateTime", "passed"},
+	"FireMarshalViolation":    {"violationID", "inspectionID", "codeReference", "severity", "status", "correctByDateTime"},
+	"Hydrant":                 {"hydrantID", "hydrantNumber", "streetAddress", "city", "flowRate", "colorCode", "outOfService"},
+	"HydrantFlowTest":         {"flowTestID", "hydrantID", "testDateTime", "staticPressure", "residualPressure", "flowRate"},
+	"HydrantInspection":       {"inspectionID", "hydrantID", "inspectionDateTime", "passed", "deficiencies"},
+	"Incident":                {"incidentID", "incidentNumber", "incidentDateTime", "dispatchRunNumber", "stationID", "isComplete"},
+	"InventoryItem":           {"itemID", "itemName", "category", "serialNumber", "quantity", "locationName", "status"},
+	"InventoryLocation":       {"locationID", "locationName", "stationID", "departmentApparatusID"},
+	"LicenseExpiration":       {"userID", "fullName", "kind", "licenseType", "level", "licenseNumber", "expirationDate", "daysRemaining"},
+	"MaintenanceDueItem":      {"departmentApparatusID", "departmentApparatusName", "maintenanceType", "lastCompletedDate", "dueDate", "daysRemaining"},
+	"MaintenanceRecord":       {"recordID", "departmentApparatusID", "workOrderNumber", "maintenanceType", "status", "openedDateTime", "completedDateTime"},
+	"MaintenanceSchedule":     {"scheduleID", "departmentApparatusID", "maintenanceType", "intervalDays", "lastCompletedDate", "nextDueDate"},
+	"MemberTrainingHours":     {"userID", "fullName", "agencyPersonnelID", "categoryName", "classes", "hours"},
+	"NERISExport":             {"incidentID", "incident.base.incident_number", "incident.incident_types.0.type"},
+	"Occupancy":               {"occupancyID", "occupancyNumber", "occupancyName", "streetNumber", "streetName", "city", "propertyUse"},
+	"OccupancyContact":        {"occupancyContactID", "occupancyID", "contactType", "firstName", "lastName", "phone"},
+	"OccupancyHazard":         {"occupancyHazardID", "occupancyID", "hazardType", "description", "location"},
+	"OccupancyMatch":          {"occupancy.occupancyID", "occupancy.occupancyName", "matchType", "distance"},
+	"OccupancyPrePlan":        {"prePlanID", "occupancyID", "prePlanDateTime", "reviewDateTime"},
+	"OverdueReInspection":     {"occupancyID", "occupancyName", "inspectionID", "reInspectionID", "dueDateTime", "daysOverdue"},
+	"PatientCareReport":       {"pcrID", "pcrNumber", "incidentNumber", "departmentApparatusID", "times.unitNotifiedDateTime", "disposition.unitDispositionNemsis3"},
+	"Paygrade":                {"paygradeID", "paygradeName", "rate", "isActive"},
+	"PayrollExport":           {"exportID", "periodStartDate", "periodEndDate", "exportedDateTime", "totalHours", "totalAmount"},
+	"PayrollLine":             {"userID", "fullName", "agencyPersonnelID", "paygradeName", "rate", "hours", "amount"},
+	"Roster":                  {"rosterID", "rosterDate", "shiftName", "stationID", "startDateTime", "endDateTime"},
+	"RosterAssignment":        {"assignmentID", "rosterID", "userID", "apparatusID", "positionName", "startDateTime", "endDateTime"},
+	"RosterPosition":          {"positionID", "positionName", "abbreviation", "isActive"},
+	"Shift":                   {"shiftID", "shiftName", "shiftCode", "isActive"},
+	"ShiftCalendarDay":        {"shiftDate", "shiftName", "startDateTime", "endDateTime"},
+	"Station":                 {"stationID", "stationNumber", "stationName", "city", "state"},
+	"TrainingAttendee":        {"attendeeID", "classID", "userID", "hours", "status"},
+	"TrainingCategory":        {"categoryID", "categoryName", "isActive"},
+	"TrainingClass":           {"classID", "className", "categoryName", "startDateTime", "hours", "isComplete"},
+	"User":                    {"userID", "fullName", "login", "roleName", "primaryEmail", "station", "shift"},
+	"UserLicense":             {"licenseID", "licenseType", "level", "issuingBody", "licenseNumber", "expirationDate"},
+}
+
+// printOutput writes the value to stdout in the format chosen by the "--output" flag.
+//
+// This exits the program if the value could not be written.
+func printOutput(cmd *cobra.Command, value interface{}) {
+	err := writeOutput(os.Stdout, cmd, value)
+	if err != nil {
+		logrus.Errorf("Error writing output: [%T] %v", err, err)
+		os.Exit(1)
+	}
+}
+
+// writeOutput writes the value in the format chosen by the "--output" flag.
+func writeOutput(writer io.Writer, cmd *cobra.Command, value interface{}) error {
+	format := cmd.Flag("output").Value.String()
+	fields, err := cmd.Flags().GetStringSlice("fields")
+	if err != nil {
+		return err
+	}
+
+	if format == outputTemplate {
+		return writeTemplate(writer, cmd.Flag("template").Value.String(), value)
+	}
+
+	document, err := toOrdered(value)
+	if err != nil {
+		return err
+	}
+
+	// Lists are written as rows; anything else is a single row.
+	var rows []interface{}
+	list, isList := document.([]interface{})
+	if isList {
+		rows = list
+	} else {
+		rows = []interface{}{document}
+	}
+
+	if len(fields) > 0 {
+		for index, row := range rows {
+			rows[index] = project(row, fields)
+		}
+		if isList {
+			document = rows
+		} else {
+			document = rows[0]
+		}
+	}
+
+	switch format {
+	case outputJSON:
+		var buffer bytes.Buffer
+		err = writeOrderedJSON(&buffer, document)
+		if err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		err = json.Indent(&indented, buffer.Bytes(), "" /*prefix*/, "\t" /*indent*/)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, indented.String())
+		return err
+	case outputNDJSON:
+		for _, row := range rows {
+			err = writeOrderedJSON(writer, row)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(writer)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputYAML:
+		contents, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(contents)
+		return err
+	case outputCSV, outputTable:
+		columns := fields
+		if len(columns) == 0 && format == outputTable {
+			columns = defaultTableColumns[typeName(value)]
+		}
+		if len(columns) == 0 {
+			columns = columnNames(rows)
+		}
+
+		var records [][]string
+		records = append(records, columns)
+		for _, row := range rows {
+			flattened := map[string]interface{}{}
+			flatten("", row, flattened)
+			record := make([]string, len(columns))
+			for index, column := range columns {
+				if value := flattened[column]; value != nil {
+					record[index] = fmt.Sprintf("%v", value)
+				}
+			}
+			records = append(records, record)
+		}
+
+		if format == outputCSV {
+			csvWriter := csv.NewWriter(writer)
+			err = csvWriter.WriteAll(records)
+			if err != nil {
+				return err
+			}
+			return csvWriter.Error()
+		}
+
+		tableWriter := tabwriter.NewWriter(writer, 0 /*minwidth*/, 8 /*tabwidth*/, 2 /*padding*/, ' ', 0 /*flags*/)
+		for _, record := range records {
+			_, err = fmt.Fprintln(tableWriter, strings.Join(record, "\t"))
+			if err != nil {
+				return err
+			}
+		}
+		return tableWriter.Flush()
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// writeTemplate executes the template against the value's JSON representation.
+//
+// Lists execute the template once per item, with a newline after each one.
+func writeTemplate(writer io.Writer, text string, value interface{}) error {
+	if text == "" {
+		return fmt.Errorf("the template output format requires --template")
+	}
+	tmpl, err := template.New("output").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("could not parse template: %w", err)
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	err = json.Unmarshal(contents, &document)
+	if err != nil {
+		return err
+	}
+
+	rows, isList := document.([]interface{})
+	if !isList {
+		rows = []interface{}{document}
+	}
+	for _, row := range rows {
+		err = tmpl.Execute(writer, row)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeName returns the name of the value's type, looking through pointers and slices.
+func typeName(value interface{}) string {
+	t := reflect.TypeOf(value)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
+
+// toOrdered converts the value to its JSON representation, keeping the field order.
+//
+// Objects become `yaml.MapSlice` values so that they can be written as YAML directly.
+func toOrdered(value interface{}) (interface{}, error) {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	return decodeOrdered(decoder)
+}
+
+// decodeOrdered decodes the next JSON value from the decoder.
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch typedToken := token.(type) {
+	case json.Delim:
+		switch typedToken {
+		case '{':
+			result := yaml.MapSlice{}
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(decoder)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, yaml.MapItem{Key: keyToken, Value: value})
+			}
+			_, err = decoder.Token()
+			return result, err
+		case '[':
+			result := []interface{}{}
+			for decoder.More() {
+				value, err := decodeOrdered(decoder)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, value)
+			}
+			_, err = decoder.Token()
+			return result, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter: %v", typedToken)
+	case json.Number:
+		if i, err := typedToken.Int64(); err == nil {
+			return i, nil
+		}
+		return typedToken.Float64()
+	default:
+		return typedToken, nil
+	}
+}
+
+// writeOrderedJSON writes an ordered value as compact JSON.
+func writeOrderedJSON(writer io.Writer, value interface{}) error {
+	switch typedValue := value.(type) {
+	case yaml.MapSlice:
+		_, err := io.WriteString(writer, "{")
+		if err != nil {
+			return err
+		}
+		for index, item := range typedValue {
+			if index > 0 {
+				_, err = io.WriteString(writer, ",")
+				if err != nil {
+					return err
+				}
+			}
+			key, err := json.Marshal(item.Key)
+			if err != nil {
+				return err
+			}
+			_, err = writer.Write(append(key, ':'))
+			if err != nil {
+				return err
+			}
+			err = writeOrderedJSON(writer, item.Value)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(writer, "}")
+		return err
+	case []interface{}:
+		_, err := io.WriteString(writer, "[")
+		if err != nil {
+			return err
+		}
+		for index, item := range typedValue {
+			if index > 0 {
+				_, err = io.WriteString(writer, ",")
+				if err != nil {
+					return err
+				}
+			}
+			err = writeOrderedJSON(writer, item)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(writer, "]")
+		return err
+	default:
+		contents, err := json.Marshal(typedValue)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(contents)
+		return err
+	}
+}
+
+// flatten converts a nested value into dotted keys, such as "location.city" or "units.0.id".
+func flatten(prefix string, value interface{}, result map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch typedValue := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range typedValue {
+			flatten(join(fmt.Sprintf("%v", item.Key)), item.Value, result)
+		}
+	case []interface{}:
+		for index, item := range typedValue {
+			flatten(join(strconv.Itoa(index)), item, result)
+		}
+	default:
+		result[prefix] = typedValue
+	}
+}
+
+// columnNames returns all of the flattened keys in the rows, in the order that they are first seen.
+func columnNames(rows []interface{}) []string {
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		var keys []string
+		collectKeys("", row, &keys)
+		for _, key := range keys {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			columns = append(columns, key)
+		}
+	}
+	return columns
+}
+
+// collectKeys appends the flattened keys of the value, in order.
+func collectKeys(prefix string, value interface{}, keys *[]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch typedValue := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range typedValue {
+			collectKeys(join(fmt.Sprintf("%v", item.Key)), item.Value, keys)
+		}
+	case []interface{}:
+		for index, item := range typedValue {
+			collectKeys(join(strconv.Itoa(index)), item, keys)
+		}
+	default:
+		*keys = append(*keys, prefix)
+	}
+}
+
+// project returns only the given (dotted) fields of the value.
+func project(value interface{}, fields []string) interface{} {
+	flattened := map[string]interface{}{}
+	flatten("", value, flattened)
+
+	result := yaml.MapSlice{}
+	for _, field := range fields {
+		result = append(result, yaml.MapItem{Key: field, Value: flattened[field]})
+	}
+	return result
+}
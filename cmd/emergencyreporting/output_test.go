@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// newOutputCommand returns a command with the output flags set.
+func newOutputCommand(t *testing.T, format string, fields []string, template string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", outputJSON, "")
+	cmd.Flags().StringSlice("fields", nil, "")
+	cmd.Flags().String("template", "", "")
+	for name, value := range map[string]string{"output": format, "fields": strings.Join(fields, ","), "template": template} {
+		if value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("Could not set --%s: %v", name, err)
+		}
+	}
+	return cmd
+}
+
+func TestWriteOutput(t *testing.T) {
+	type location struct {
+		City  string `json:"city"`
+		State string `json:"state"`
+	}
+	type record struct {
+		ID       string    `json:"id"`
+		Name     string    `json:"name"`
+		Location *location `json:"location,omitempty"`
+		Tags     []string  `json:"tags,omitempty"`
+	}
+	records := []*record{
+		{ID: "1", Name: "Smith, Jane", Location: &location{City: "Springfield", State: "IL"}},
+		{ID: "2", Name: `Say "hi"`, Tags: []string{"a", "b"}},
+	}
+
+	rows := []struct {
+		description string
+		value       interface{}
+		format      string
+		fields      []string
+		template    string
+		expected    string
+		err         string // If set, the error must contain this.
+	}{
+		{
+			description: "JSON keeps the field order",
+			value:       records[0],
+			format:      outputJSON,
+			expected:    "{\n\t\"id\": \"1\",\n\t\"name\": \"Smith, Jane\",\n\t\"location\": {\n\t\t\"city\": \"Springfield\",\n\t\t\"state\": \"IL\"\n\t}\n}\n",
+		},
+		{
+			description: "JSON with fields",
+			value:       records[0],
+			format:      outputJSON,
+			fields:      []string{"location.city", "id", "missing"},
+			expected:    "{\n\t\"location.city\": \"Springfield\",\n\t\"id\": \"1\",\n\t\"missing\": null\n}\n",
+		},
+		{
+			description: "NDJSON with fields",
+			value:       records,
+			format:      outputNDJSON,
+			fields:      []string{"id", "tags.1"},
+			expected:    "{\"id\":\"1\",\"tags.1\":null}\n{\"id\":\"2\",\"tags.1\":\"b\"}\n",
+		},
+		{
+			description: "CSV quoting and columns from every row",
+			value:       records,
+			format:      outputCSV,
+			expected:    "id,name,location.city,location.state,tags.0,tags.1\n1,\"Smith, Jane\",Springfield,IL,,\n2,\"Say \"\"hi\"\"\",,,a,b\n",
+		},
+		{
+			description: "CSV with fields",
+			value:       records,
+			format:      outputCSV,
+			fields:      []string{"name", "location.city"},
+			expected:    "name,location.city\n\"Smith, Jane\",Springfield\n\"Say \"\"hi\"\"\",\n",
+		},
+		{
+			description: "Table with the default columns",
+			value: []*emergencyreporting.Station{
+				{StationID: "1", StationNumber: "1", StationName: "Main", City: "Springfield", State: "IL"},
+				{StationID: "12", StationNumber: "2", StationName: "North", City: "Shelbyville", State: "IL"},
+			},
+			format:   outputTable,
+			expected: "stationID  stationNumber  stationName  city         state\n1          1              Main         Springfield  IL\n12         2              North        Shelbyville  IL\n",
+		},
+		{
+			description: "Table with the dry-run request columns",
+			value: []*emergencyreporting.DryRunRequest{
+				{Method: "DELETE", URL: "/a/1"},
+				{Method: "PATCH", URL: "/a/22"},
+			},
+			format:   outputTable,
+			expected: "method  url\nDELETE  /a/1\nPATCH   /a/22\n",
+		},
+		{
+			description: "Table with fields",
+			value:       records,
+			format:      outputTable,
+			fields:      []string{"id", "location.city"},
+			expected:    "id  location.city\n1   Springfield\n2   \n",
+		},
+		{
+			description: "Template",
+			value:       records,
+			format:      outputTemplate,
+			template:    "{{.id}}: {{.name}}",
+			expected:    "1: Smith, Jane\n2: Say \"hi\"\n",
+		},
+		{
+			description: "Template without a template",
+			value:       records,
+			format:      outputTemplate,
+			err:         "requires --template",
+		},
+		{
+			description: "Unknown format",
+			value:       records,
+			format:      "xml",
+			err:         "unknown output format: xml",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := writeOutput(&buffer, newOutputCommand(t, row.format, row.fields, row.template), row.value)
+			if row.err != "" {
+				if err == nil || !strings.Contains(err.Error(), row.err) {
+					t.Errorf("Expected an error with %q; got %v", row.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buffer.String() != row.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", row.expected, buffer.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// parsePayrollPeriod parses a payroll period, which is either a month ("2026-10") or an inclusive range of
+// dates ("2026-10-01..2026-10-15").
+//
+// The end that is returned is exclusive.
+func parsePayrollPeriod(value string, location *time.Location) (time.Time, time.Time, error) {
+	if parts := strings.SplitN(value, "..", 2); len(parts) == 2 {
+		from, err := time.ParseInLocation("2006-01-02", parts[0], location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+		}
+		to, err := time.ParseInLocation("2006-01-02", parts[1], location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", err)
+		}
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("the end date is before the start date")
+		}
+		return from, to.AddDate(0, 0, 1), nil
+	}
+	from, err := time.ParseInLocation("2006-01", value, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period (expected YYYY-MM or YYYY-MM-DD..YYYY-MM-DD): %s", value)
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+func doPayrollExport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	period := cmd.Flag("period").Value.String()
+	if period == "" {
+		logrus.Errorf("Missing payroll period (--period)")
+		os.Exit(1)
+	}
+	var options emergencyreporting.PayrollOptions
+	options.From, options.To, err = parsePayrollPeriod(period, location)
+	if err != nil {
+		logrus.Errorf("Could not parse the period: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	lines, err := client.GetPayroll(ctx, options)
+	var payrollError *emergencyreporting.PayrollError
+	if errors.As(err, &payrollError) {
+		// A payroll that leaves people out is worse than none, so nothing is written.
+		for _, problem := range payrollError.Problems {
+			logrus.Errorf("Could not pay user %s for event %s: %v", problem.UserID, problem.EventID, problem.Err)
+		}
+		os.Exit(1)
+	}
+	if err != nil {
+		logrus.Errorf("Could not get the payroll: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	for _, line := range lines {
+		if line.Rate == 0 && line.Hours > 0 {
+			logrus.Warnf("User %s (%s) has %.2f hours but no pay rate.", line.UserID, line.FullName, line.Hours)
+		}
+	}
+
+	err = emergencyreporting.WritePayrollCSV(os.Stdout, lines)
+	if err != nil {
+		logrus.Errorf("Could not write CSV: [%T] %v", err, err)
+		os.Exit(1)
+	}
+}
+
+func doPayrollHistory(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	exportsResponse, err := client.GetPayrollExports(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get payroll exports: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, exportsResponse.Exports)
+}
+
+func doPayrollPaygradeList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	paygradesResponse, err := client.GetPaygrades(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get pay grades: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, paygradesResponse.Paygrades)
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doRosterAssignmentAdd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var assignment emergencyreporting.RosterAssignment
+	err := json.Unmarshal([]byte(args[1]), &assignment)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postAssignmentResponse, err := client.PostRosterAssignment(ctx, args[0], assignment)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not add assignment: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postAssignmentResponse)
+}
+
+func doRosterAssignmentList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	assignmentsResponse, err := client.GetRosterAssignments(ctx, args[0], options)
+	if err != nil {
+		logrus.Errorf("Could not get assignments: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, assignmentsResponse.Assignments)
+}
+
+func doRosterAssignmentUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	rosterID := args[0]
+	assignmentID := args[1]
+
+	var patchAssignmentRequest emergencyreporting.PatchRosterAssignmentRequest
+	err := json.Unmarshal([]byte(args[2]), &patchAssignmentRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	assignmentsResponse, err := client.GetRosterAssignments(ctx, rosterID, nil)
+	if err != nil {
+		logrus.Errorf("Could not get assignments: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	var assignment *emergencyreporting.RosterAssignment
+	for _, a := range assignmentsResponse.Assignments {
+		if a.AssignmentID == assignmentID {
+			assignment = a
+			break
+		}
+	}
+	if assignment == nil {
+		logrus.Errorf("Assignment not found")
+		os.Exit(1)
+	}
+
+	patchAssignmentResponse, err := client.PatchRosterAssignment(ctx, rosterID, assignmentID, assignment.RowVersion, patchAssignmentRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update assignment: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchAssignmentResponse)
+}
+
+func doRosterCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var roster emergencyreporting.Roster
+	err := json.Unmarshal([]byte(args[0]), &roster)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postRosterResponse, err := client.PostRoster(ctx, roster)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create roster: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postRosterResponse)
+}
+
+func doRosterGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	rosterResponse, err := client.GetRoster(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get roster: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, rosterResponse.Roster)
+}
+
+func doRosterList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		rosters, err := client.GetAllRosters(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get rosters: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, rosters)
+		return
+	}
+
+	rostersResponse, err := client.GetRosters(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get rosters: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, rostersResponse.Rosters)
+}
+
+func doRosterOnDuty(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	at := time.Now().In(location)
+	if len(args) > 1 {
+		at, err = emergencyreporting.ParseDateTime(args[1], location)
+		if err != nil {
+			logrus.Errorf("Could not parse the date/time: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	}
+
+	assignments, err := client.GetOnDuty(ctx, args[0], at)
+	if err != nil {
+		logrus.Errorf("Could not get who was on duty: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, assignments)
+}
+
+func doRosterPopulateCrew(cmd *cobra.Command, args []string) {
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	results := runBulk(cmd, "populate crew", args, func(ctx context.Context, exposureID string) (interface{}, error) {
+		crewMembers, err := client.GetRosterCrew(ctx, exposureID, location)
+		if err != nil {
+			return nil, err
+		}
+
+		var values []interface{}
+		for _, crewMember := range crewMembers {
+			postMemberResponse, err := client.PostExposureMember(ctx, exposureID, *crewMember)
+			if request := dryRunRequest(err); request != nil {
+				values = append(values, request)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("user %s: %w", crewMember.UserID, err)
+			}
+			crewMember.ExposureUserID = postMemberResponse.ExposureUserID
+			values = append(values, crewMember)
+		}
+		return values, nil
+	})
+
+	if client.DryRun {
+		requests := []*emergencyreporting.DryRunRequest{}
+		for _, value := range results.Values() {
+			for _, item := range value.([]interface{}) {
+				requests = append(requests, item.(*emergencyreporting.DryRunRequest))
+			}
+		}
+		printOutput(cmd, requests)
+	} else {
+		crewMembers := []*emergencyreporting.CrewMember{}
+		for _, value := range results.Values() {
+			for _, item := range value.([]interface{}) {
+				crewMembers = append(crewMembers, item.(*emergencyreporting.CrewMember))
+			}
+		}
+		printOutput(cmd, crewMembers)
+	}
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func doRosterPositionList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	positionsResponse, err := client.GetRosterPositions(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get positions: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, positionsResponse.Positions)
+}
+
+func doRosterUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	rosterID := args[0]
+
+	var patchRosterRequest emergencyreporting.PatchRosterRequest
+	err := json.Unmarshal([]byte(args[1]), &patchRosterRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	rosterResponse, err := client.GetRoster(ctx, rosterID)
+	if err != nil {
+		logrus.Errorf("Could not get roster: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if rosterResponse.Roster == nil {
+		logrus.Errorf("Roster not found")
+		os.Exit(1)
+	}
+
+	patchRosterResponse, err := client.PatchRoster(ctx, rosterID, rosterResponse.Roster.RowVersion, patchRosterRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update roster: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchRosterResponse)
+}
+
+func doShiftCalendar(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	days, err := client.GetAllShiftCalendar(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get the shift calendar: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, days)
+}
+
+func doShiftList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	shiftsResponse, err := client.GetShifts(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get shifts: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, shiftsResponse.Shifts)
+}
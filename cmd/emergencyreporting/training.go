@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+func doTrainingAttendeeAdd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	attendee := emergencyreporting.TrainingAttendee{
+		UserID: args[1],
+		Hours:  cmd.Flag("hours").Value.String(),
+		Status: cmd.Flag("status").Value.String(),
+	}
+
+	postAttendeeResponse, err := client.PostTrainingClassAttendee(ctx, args[0], attendee)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not add attendee: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postAttendeeResponse)
+}
+
+func doTrainingAttendeeImport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	classID := args[0]
+
+	var reader io.Reader = os.Stdin
+	if args[1] != "-" {
+		file, err := os.Open(args[1])
+		if err != nil {
+			logrus.Errorf("Could not open file: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		reader = file
+	}
+	records, err := emergencyreporting.ReadTrainingAttendanceCSV(reader)
+	if err != nil {
+		logrus.Errorf("Could not read attendance: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	users, err := client.GetAllUsers(ctx, map[string]string{"limit": cmd.Flag("limit").Value.String()})
+	if err != nil {
+		logrus.Errorf("Could not get users: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	userIDs := emergencyreporting.UserIDsByPersonnelID(users)
+
+	// Each person is only added once, using the last row for them.
+	var personnelIDs []string
+	recordsByPersonnelID := map[string]*emergencyreporting.TrainingAttendanceRecord{}
+	for _, record := range records {
+		if existing, ok := recordsByPersonnelID[record.AgencyPersonnelID]; ok {
+			logrus.Warnf("Personnel ID '%s' is on lines %d and %d; using line %d.", record.AgencyPersonnelID, existing.Line, record.Line, record.Line)
+		} else {
+			personnelIDs = append(personnelIDs, record.AgencyPersonnelID)
+		}
+		recordsByPersonnelID[record.AgencyPersonnelID] = record
+	}
+
+	results := runBulk(cmd, "add attendee", personnelIDs, func(ctx context.Context, personnelID string) (interface{}, error) {
+		record := recordsByPersonnelID[personnelID]
+		userID, ok := userIDs[personnelID]
+		if !ok {
+			return nil, fmt.Errorf("line %d: no user has this personnel ID", record.Line)
+		}
+
+		attendee := record.TrainingAttendee(userID)
+		attendee.ClassID = classID
+		postAttendeeResponse, err := client.PostTrainingClassAttendee(ctx, classID, attendee)
+		if request := dryRunRequest(err); request != nil {
+			return request, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", record.Line, err)
+		}
+		attendee.AttendeeID = postAttendeeResponse.AttendeeID
+		return &attendee, nil
+	})
+
+	if client.DryRun {
+		var requests []*emergencyreporting.DryRunRequest
+		for _, value := range results.Values() {
+			requests = append(requests, value.(*emergencyreporting.DryRunRequest))
+		}
+		printOutput(cmd, requests)
+	} else {
+		attendees := []*emergencyreporting.TrainingAttendee{}
+		for _, value := range results.Values() {
+			attendees = append(attendees, value.(*emergencyreporting.TrainingAttendee))
+		}
+		printOutput(cmd, attendees)
+	}
+	if results.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func doTrainingAttendeeList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 1 {
+		filter = args[1]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	attendeesResponse, err := client.GetTrainingClassAttendees(ctx, args[0], options)
+	if err != nil {
+		logrus.Errorf("Could not get attendees: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, attendeesResponse.Attendees)
+}
+
+func doTrainingCategoryList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	categoriesResponse, err := client.GetTrainingCategories(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get categories: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, categoriesResponse.Categories)
+}
+
+func doTrainingClassCreate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	var class emergencyreporting.TrainingClass
+	err := json.Unmarshal([]byte(args[0]), &class)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	postClassResponse, err := client.PostTrainingClass(ctx, class)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not create class: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, postClassResponse)
+}
+
+func doTrainingClassGet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	classResponse, err := client.GetTrainingClass(ctx, args[0])
+	if err != nil {
+		logrus.Errorf("Could not get class: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, classResponse.Class)
+}
+
+func doTrainingClassList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	options := map[string]string{
+		"filter": filter,
+		"limit":  cmd.Flag("limit").Value.String(),
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		classes, err := client.GetAllTrainingClasses(ctx, options)
+		if err != nil {
+			logrus.Errorf("Could not get classes: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		printOutput(cmd, classes)
+		return
+	}
+
+	classesResponse, err := client.GetTrainingClasses(ctx, options)
+	if err != nil {
+		logrus.Errorf("Could not get classes: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, classesResponse.Classes)
+}
+
+func doTrainingClassUpdate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	classID := args[0]
+
+	var patchClassRequest emergencyreporting.PatchTrainingClassRequest
+	err := json.Unmarshal([]byte(args[1]), &patchClassRequest)
+	if err != nil {
+		logrus.Errorf("Could not parse JSON: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	classResponse, err := client.GetTrainingClass(ctx, classID)
+	if err != nil {
+		logrus.Errorf("Could not get class: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	if classResponse.Class == nil {
+		logrus.Errorf("Class not found")
+		os.Exit(1)
+	}
+
+	patchClassResponse, err := client.PatchTrainingClass(ctx, classID, classResponse.Class.RowVersion, patchClassRequest)
+	if printDryRun(cmd, err) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Could not update class: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	printOutput(cmd, patchClassResponse)
+}
+
+func doTrainingReport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	client := makeClient(cmd)
+
+	location, err := time.LoadLocation(cmd.Flag("timezone").Value.String())
+	if err != nil {
+		logrus.Errorf("Could not load time zone: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	var options emergencyreporting.TrainingHoursReportOptions
+	if value := cmd.Flag("from").Value.String(); value != "" {
+		options.From, err = time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			logrus.Errorf("Could not parse the date: [%T] %v", err, err)
+			os.Exit(1)
+		}
+	}
+	if value := cmd.Flag("to").Value.String(); value != "" {
+		options.To, err = time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			logrus.Errorf("Could not parse the date: [%T] %v", err, err)
+			os.Exit(1)
+		}
+		// The "to" date is inclusive.
+		options.To = options.To.AddDate(0, 0, 1)
+	}
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+	limit := cmd.Flag("limit").Value.String()
+
+	hours, err := client.GetAllTrainingHours(ctx, map[string]string{"filter": filter, "limit": limit})
+	if err != nil {
+		logrus.Errorf("Could not get training hours: [%T] %v", err, err)
+		os.Exit(1)
+	}
+	users, err := client.GetAllUsers(ctx, map[string]string{"limit": limit})
+	if err != nil {
+		logrus.Errorf("Could not get users: [%T] %v", err, err)
+		os.Exit(1)
+	}
+
+	printOutput(cmd, emergencyreporting.TrainingHoursReport(hours, users, options))
+}
@@ -0,0 +1,135 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Address types.
+const (
+	AddressTypeHome    = "Home"
+	AddressTypeMailing = "Mailing"
+	AddressTypeWork    = "Work"
+)
+
+// Phone types.
+const (
+	PhoneTypeMobile = "Mobile"
+	PhoneTypeHome   = "Home"
+	PhoneTypeWork   = "Work"
+	PhoneTypePager  = "Pager"
+	PhoneTypeFax    = "Fax"
+)
+
+// Email types.
+const (
+	EmailTypePersonal = "Personal"
+	EmailTypeWork     = "Work"
+)
+
+// UserContactInfo is how to get in touch with a user.
+type UserContactInfo struct {
+	UserID            string                  `json:"userID,omitempty"` // Not used for updating contact info.
+	FirstName         string                  `json:"firstName"`
+	MiddleName        string                  `json:"middleName"`
+	LastName          string                  `json:"lastName"`
+	Addresses         []*UserAddress          `json:"addresses"`
+	Phones            []*UserPhone            `json:"phones"`
+	Emails            []*UserEmail            `json:"emails"`
+	EmergencyContacts []*UserEmergencyContact `json:"emergencyContacts"`
+	RowVersion        string                  `json:"rowVersion,omitempty"`
+}
+
+type UserAddress struct {
+	AddressType    string `json:"addressType"` // One of the `AddressType*` constants.
+	StreetAddress  string `json:"streetAddress"`
+	StreetAddress2 string `json:"streetAddress2"`
+	City           string `json:"city"`
+	State          string `json:"state"`
+	ZipCode        string `json:"zipCode"`
+	Country        string `json:"country"`
+	IsPrimary      bool   `json:"isPrimary"`
+}
+
+type UserPhone struct {
+	PhoneType   string `json:"phoneType"` // One of the `PhoneType*` constants.
+	PhoneNumber string `json:"phoneNumber"`
+	Extension   string `json:"extension"`
+	IsPrimary   bool   `json:"isPrimary"`
+}
+
+type UserEmail struct {
+	EmailType    string `json:"emailType"` // One of the `EmailType*` constants.
+	EmailAddress string `json:"emailAddress"`
+	IsPrimary    bool   `json:"isPrimary"`
+}
+
+// UserEmergencyContact is someone to call when something happens to the user.
+type UserEmergencyContact struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"` // Such as "Spouse" or "Parent".
+	PhoneNumber  string `json:"phoneNumber"`
+	PhoneType    string `json:"phoneType"` // One of the `PhoneType*` constants.
+	EmailAddress string `json:"emailAddress"`
+}
+
+type GetUserContactInfoResponse struct {
+	ContactInfo *UserContactInfo `json:"contactInfo"`
+}
+
+type PutUserContactInfoResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// PrimaryPhone returns the primary phone, or the first one if none of them are marked as primary.
+//
+// This returns nil if there are no phones.
+func (u *UserContactInfo) PrimaryPhone() *UserPhone {
+	for _, phone := range u.Phones {
+		if phone.IsPrimary {
+			return phone
+		}
+	}
+	if len(u.Phones) > 0 {
+		return u.Phones[0]
+	}
+	return nil
+}
+
+// PutUserContactInfo replaces the contact info for a user.
+//
+// The addresses, phones, emails, and emergency contacts are replaced as a whole, so anything left out is removed.
+func (c *Client) PutUserContactInfo(ctx context.Context, userID string, contactInfo UserContactInfo) (*PutUserContactInfoResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyusers/users/{userID}/contactinfo
+
+	targetURL := "/agencyusers/users/" + url.PathEscape(userID) + "/contactinfo"
+
+	rowVersion := contactInfo.RowVersion
+	contactInfo.UserID = ""
+	contactInfo.RowVersion = ""
+
+	jsonInput, err := json.Marshal(contactInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PutUserContactInfoResponse
+
+	err = c.internalRequest(ctx, http.MethodPut, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not put the user contact info: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
@@ -0,0 +1,59 @@
+package emergencyreporting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts are the layouts that Emergency Reporting date/times and dates have been seen in.
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDateTime parses a date/time (or date) in any of the layouts that Emergency Reporting uses, such as
+// "2006-01-02T15:04:05" or RFC 3339; values without a time zone are in the given location.
+//
+// This is handy for parsing user input the same way that the library parses the API's values.
+func ParseDateTime(value string, location *time.Location) (time.Time, error) {
+	t, ok := parseDateTime(value, location)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date/time: %q", value)
+	}
+	return t, nil
+}
+
+// parseDateTime parses an Emergency Reporting date/time (or date); values without a time zone are in the given location.
+//
+// This returns false if the value is empty or cannot be parsed.
+func parseDateTime(value string, location *time.Location) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	for _, layout := range dateTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// daysRemaining returns the whole number of days in the duration.
+//
+// Negative durations round toward the past, so that something that was due an hour ago is "-1".
+func daysRemaining(remaining time.Duration) int {
+	if remaining < 0 {
+		return -int((-remaining + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	return int(remaining / (24 * time.Hour))
+}
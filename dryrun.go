@@ -0,0 +1,77 @@
+package emergencyreporting
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DryRunRequest is a request that was not sent because the client is in dry-run mode.
+type DryRunRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"headers"` // The credentials are replaced by `RedactedValue`.
+	Body   []byte      `json:"-"`       // The body, exactly as it would have been sent.
+}
+
+// MarshalJSON includes the body as JSON if it is JSON, and as a string otherwise.
+func (r DryRunRequest) MarshalJSON() ([]byte, error) {
+	type plain DryRunRequest
+	output := struct {
+		plain
+		Body interface{} `json:"body,omitempty"`
+	}{
+		plain: plain(r),
+	}
+	if len(r.Body) > 0 {
+		if json.Valid(r.Body) {
+			output.Body = json.RawMessage(r.Body)
+		} else {
+			output.Body = string(r.Body)
+		}
+	}
+	return json.Marshal(output)
+}
+
+// DryRunError is returned instead of sending a request that would change something.
+//
+// It matches `ErrorDryRun` with `errors.Is`; use `errors.As` to get the request.
+type DryRunError struct {
+	Request *DryRunRequest
+}
+
+// Error returns a summary of the request.
+func (e *DryRunError) Error() string {
+	return "dry run: " + e.Request.Method + " " + e.Request.URL
+}
+
+// Is returns whether the target is `ErrorDryRun`.
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrorDryRun
+}
+
+// readOnlyMethod returns whether the method only reads data; these go through in dry-run mode.
+func readOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// dryRun logs the request and returns it as a `*DryRunError`.
+func (c *Client) dryRun(request *http.Request, body []byte) error {
+	header := request.Header.Clone()
+	for _, key := range []string{"Authorization", "Ocp-Apim-Subscription-Key"} {
+		if header.Get(key) != "" {
+			header.Set(key, RedactedValue)
+		}
+	}
+	dryRunRequest := &DryRunRequest{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: header,
+		Body:   body,
+	}
+	c.log().Info("Dry run", "method", dryRunRequest.Method, "url", dryRunRequest.URL, "body", c.redact(body))
+	return &DryRunError{Request: dryRunRequest}
+}
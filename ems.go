@@ -0,0 +1,183 @@
+package emergencyreporting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PatientCareReport is an EMS patient care report (PCR).
+//
+// The coded fields hold NEMSIS 3 code values, such as "2205001" for an emergency response.
+// Everything about the patient is protected health information; see `DefaultRedactedFields`.
+type PatientCareReport struct {
+	PCRID                 string           `json:"pcrID"`
+	PCRNumber             string           `json:"pcrNumber"`
+	IncidentID            string           `json:"incidentID"`
+	IncidentNumber        string           `json:"incidentNumber"`
+	ExposureID            string           `json:"exposureID"`
+	DepartmentApparatusID string           `json:"departmentApparatusID"`
+	ResponseNumber        string           `json:"responseNumber"`
+	TypeOfService         string           `json:"typeOfServiceNemsis3"`
+	Times                 *EMSTimes        `json:"times"`
+	Patient               *EMSPatient      `json:"patient"`
+	Vitals                []*EMSVitals     `json:"vitals"`
+	Procedures            []*EMSProcedure  `json:"procedures"`
+	Medications           []*EMSMedication `json:"medications"`
+	Disposition           *EMSDisposition  `json:"disposition"`
+	Narrative             string           `json:"narrative"`
+	RowVersion            string           `json:"rowVersion"`
+}
+
+// EMSTimes are the times of the unit's response.
+//
+// Any that are empty are taken from the exposure apparatus, when it is known.
+type EMSTimes struct {
+	PSAPCallDateTime              string `json:"psapCallDateTime"`
+	UnitNotifiedDateTime          string `json:"unitNotifiedDateTime"`
+	UnitEnrouteDateTime           string `json:"unitEnrouteDateTime"`
+	UnitArrivedOnSceneDateTime    string `json:"unitArrivedOnSceneDateTime"`
+	ArrivedAtPatientDateTime      string `json:"arrivedAtPatientDateTime"`
+	UnitLeftSceneDateTime         string `json:"unitLeftSceneDateTime"`
+	AtDestinationDateTime         string `json:"atDestinationDateTime"`
+	TransferOfPatientCareDateTime string `json:"transferOfPatientCareDateTime"`
+	UnitBackInServiceDateTime     string `json:"unitBackInServiceDateTime"`
+}
+
+// EMSPatient is the patient's demographics.
+type EMSPatient struct {
+	FirstName     string `json:"firstName"`
+	MiddleName    string `json:"middleName"`
+	LastName      string `json:"lastName"`
+	StreetAddress string `json:"streetAddress"`
+	City          string `json:"city"`
+	County        string `json:"county"`
+	State         string `json:"state"`
+	ZipCode       string `json:"zip"`
+	Gender        string `json:"genderNemsis3"`
+	Race          string `json:"raceNemsis3"`
+	Age           string `json:"age"`
+	AgeUnits      string `json:"ageUnitsNemsis3"`
+	DateOfBirth   string `json:"dateOfBirth"`
+	Phone         string `json:"phone"`
+}
+
+// EMSVitals is a single set of vital signs.
+type EMSVitals struct {
+	VitalsDateTime     string `json:"vitalsDateTime"`
+	SystolicBP         string `json:"systolicBloodPressure"`
+	DiastolicBP        string `json:"diastolicBloodPressure"`
+	HeartRate          string `json:"heartRate"`
+	PulseOximetry      string `json:"pulseOximetry"`
+	RespiratoryRate    string `json:"respiratoryRate"`
+	BloodGlucose       string `json:"bloodGlucose"`
+	GCSEye             string `json:"gcsEye"`
+	GCSVerbal          string `json:"gcsVerbal"`
+	GCSMotor           string `json:"gcsMotor"`
+	GCSTotal           string `json:"gcsTotal"`
+	TemperatureC       string `json:"temperatureCelsius"`
+	PainScale          string `json:"painScale"`
+	PerformedBeforeEMS string `json:"performedBeforeEMS"` // "1" if taken before this unit's care.
+}
+
+// EMSProcedure is a procedure that was performed on the patient.
+type EMSProcedure struct {
+	ProcedureDateTime  string `json:"procedureDateTime"`
+	ProcedureCode      string `json:"procedureCode"` // The SNOMED CT code.
+	ProcedureName      string `json:"procedureName"`
+	Attempts           string `json:"attempts"`
+	Successful         string `json:"successful"` // "1" if the procedure was successful.
+	PerformedByUserID  string `json:"performedByUserID"`
+	PerformedBeforeEMS string `json:"performedBeforeEMS"` // "1" if done before this unit's care.
+}
+
+// EMSMedication is a medication that was given to the patient.
+type EMSMedication struct {
+	MedicationDateTime string `json:"medicationDateTime"`
+	MedicationCode     string `json:"medicationCode"` // The RxNorm code.
+	MedicationName     string `json:"medicationName"`
+	Route              string `json:"routeNemsis3"`
+	Dose               string `json:"dose"`
+	DoseUnits          string `json:"doseUnitsNemsis3"`
+	Response           string `json:"responseNemsis3"`
+	GivenByUserID      string `json:"givenByUserID"`
+	PerformedBeforeEMS string `json:"performedBeforeEMS"` // "1" if given before this unit's care.
+}
+
+// EMSDisposition is what happened to the patient, and where they were taken.
+type EMSDisposition struct {
+	UnitDisposition        string `json:"unitDispositionNemsis3"`
+	PatientEvaluationCare  string `json:"patientEvaluationCareNemsis3"`
+	CrewDisposition        string `json:"crewDispositionNemsis3"`
+	TransportDisposition   string `json:"transportDispositionNemsis3"`
+	DestinationName        string `json:"destinationName"`
+	DestinationCode        string `json:"destinationCode"`
+	DestinationAddress     string `json:"destinationAddress"`
+	DestinationCity        string `json:"destinationCity"`
+	DestinationState       string `json:"destinationState"`
+	DestinationZipCode     string `json:"destinationZip"`
+	DestinationType        string `json:"destinationTypeNemsis3"`
+	TransportMethod        string `json:"transportMethodNemsis3"` // If empty, then the apparatus' transport method.
+	TransportModeFromScene string `json:"transportModeFromSceneNemsis3"`
+}
+
+type GetPatientCareReportsResponse struct {
+	TotalRows          string               `json:"totalRows"`
+	PatientCareReports []*PatientCareReport `json:"patientCareReports"`
+}
+
+type GetPatientCareReportResponse struct {
+	PatientCareReport *PatientCareReport `json:"patientCareReport"`
+}
+
+// GetPatientCareReports gets a page of EMS patient care reports.
+func (c *Client) GetPatientCareReports(ctx context.Context, options map[string]string) (*GetPatientCareReportsResponse, error) {
+	// https://data.emergencyreporting.com/agencyems/patientcarereports[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyems/patientcarereports"
+
+	var parsedResponse GetPatientCareReportsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the patient care reports: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllPatientCareReports gets every page of EMS patient care reports.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllPatientCareReports(ctx context.Context, options map[string]string) ([]*PatientCareReport, error) {
+	var reports []*PatientCareReport
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetPatientCareReports(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		reports = append(reports, response.PatientCareReports...)
+		return len(response.PatientCareReports), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetPatientCareReport gets an EMS patient care report.
+func (c *Client) GetPatientCareReport(ctx context.Context, pcrID string) (*GetPatientCareReportResponse, error) {
+	// https://data.emergencyreporting.com/agencyems/patientcarereports/{pcrID}
+
+	targetURL := "/agencyems/patientcarereports/" + url.PathEscape(pcrID)
+
+	var parsedResponse GetPatientCareReportResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the patient care report: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
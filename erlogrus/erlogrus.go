@@ -0,0 +1,56 @@
+// Package erlogrus adapts logrus to the Emergency Reporting client's leveled logger.
+//
+//	client.LeveledLogger = erlogrus.New(logrus.StandardLogger())
+package erlogrus
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is an `emergencyreporting.LeveledLogger` that writes to logrus.
+type Logger struct {
+	logger logrus.FieldLogger
+}
+
+// New returns a leveled logger that writes to the given logrus logger (or entry).
+func New(logger logrus.FieldLogger) *Logger {
+	return &Logger{
+		logger: logger,
+	}
+}
+
+// Debug logs a message at the debug level.
+func (l *Logger) Debug(message string, keysAndValues ...interface{}) {
+	l.logger.WithFields(fields(keysAndValues)).Debug(message)
+}
+
+// Info logs a message at the info level.
+func (l *Logger) Info(message string, keysAndValues ...interface{}) {
+	l.logger.WithFields(fields(keysAndValues)).Info(message)
+}
+
+// Warn logs a message at the warn level.
+func (l *Logger) Warn(message string, keysAndValues ...interface{}) {
+	l.logger.WithFields(fields(keysAndValues)).Warn(message)
+}
+
+// Error logs a message at the error level.
+func (l *Logger) Error(message string, keysAndValues ...interface{}) {
+	l.logger.WithFields(fields(keysAndValues)).Error(message)
+}
+
+// fields converts the key/value pairs into logrus fields.
+func fields(keysAndValues []interface{}) logrus.Fields {
+	result := logrus.Fields{}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprintf("%v", keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			result[key] = keysAndValues[i+1]
+		} else {
+			result[key] = "(MISSING)"
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+// Package erotel connects the Emergency Reporting client to OpenTelemetry.
+//
+//	client.Tracer = erotel.NewTracer(otel.GetTracerProvider())
+//	client.Metrics, err = erotel.NewMetrics(otel.GetMeterProvider())
+//
+// This is a separate module so that the client itself does not depend on OpenTelemetry.
+package erotel
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer and meter.
+const instrumentationName = "github.com/tekkamanendless/emergencyreporting"
+
+// Tracer is an `emergencyreporting.Tracer` that creates OpenTelemetry spans.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a tracer that uses the given provider.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: provider.Tracer(instrumentationName),
+	}
+}
+
+// StartSpan starts a client span named after the operation.
+func (t *Tracer) StartSpan(ctx context.Context, operation emergencyreporting.Operation) (context.Context, emergencyreporting.Span) {
+	ctx, span := t.tracer.Start(ctx, operation.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(operationAttributes(operation)...),
+	)
+	return ctx, &Span{span: span}
+}
+
+// Span is an `emergencyreporting.Span` for an OpenTelemetry span.
+type Span struct {
+	span trace.Span
+}
+
+// End sets the result attributes and status, and then ends the span.
+func (s *Span) End(result emergencyreporting.OperationResult) {
+	if result.StatusCode != 0 {
+		s.span.SetAttributes(attribute.Int("http.response.status_code", result.StatusCode))
+	}
+	s.span.SetAttributes(
+		attribute.Int64("http.request.body.size", result.RequestBytes),
+		attribute.Int64("http.response.body.size", result.ResponseBytes),
+	)
+	if result.Err != nil {
+		s.span.RecordError(result.Err)
+		s.span.SetStatus(codes.Error, result.Err.Error())
+	} else if result.Failed() {
+		s.span.SetStatus(codes.Error, "status code "+strconv.Itoa(result.StatusCode))
+	}
+	s.span.End()
+}
+
+// Metrics is an `emergencyreporting.Metrics` that records OpenTelemetry metrics.
+type Metrics struct {
+	duration      metric.Float64Histogram
+	requestBytes  metric.Int64Counter
+	responseBytes metric.Int64Counter
+	retries       metric.Int64Counter
+	errors        metric.Int64Counter
+}
+
+// NewMetrics creates the instruments with the given provider.
+func NewMetrics(provider metric.MeterProvider) (*Metrics, error) {
+	meter := provider.Meter(instrumentationName)
+
+	var m Metrics
+	var err error
+	m.duration, err = meter.Float64Histogram("emergencyreporting.client.operation.duration", metric.WithUnit("s"), metric.WithDescription("The duration of each operation."))
+	if err != nil {
+		return nil, err
+	}
+	m.requestBytes, err = meter.Int64Counter("emergencyreporting.client.request.size", metric.WithUnit("By"), metric.WithDescription("The number of request body bytes sent."))
+	if err != nil {
+		return nil, err
+	}
+	m.responseBytes, err = meter.Int64Counter("emergencyreporting.client.response.size", metric.WithUnit("By"), metric.WithDescription("The number of response body bytes received."))
+	if err != nil {
+		return nil, err
+	}
+	m.retries, err = meter.Int64Counter("emergencyreporting.client.retries", metric.WithDescription("The number of retried operations."))
+	if err != nil {
+		return nil, err
+	}
+	m.errors, err = meter.Int64Counter("emergencyreporting.client.errors", metric.WithDescription("The number of failed operations."))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ObserveOperation records the duration and sizes.
+func (m *Metrics) ObserveOperation(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	ctx := context.Background()
+	attributes := metric.WithAttributes(append(operationAttributes(operation), attribute.Int("http.response.status_code", result.StatusCode))...)
+	m.duration.Record(ctx, result.Duration.Seconds(), attributes)
+	if result.RequestBytes > 0 {
+		m.requestBytes.Add(ctx, result.RequestBytes, attributes)
+	}
+	m.responseBytes.Add(ctx, result.ResponseBytes, attributes)
+}
+
+// ObserveRetry counts a retry.
+func (m *Metrics) ObserveRetry(operation emergencyreporting.Operation) {
+	m.retries.Add(context.Background(), 1, metric.WithAttributes(operationAttributes(operation)...))
+}
+
+// ObserveError counts an error.
+func (m *Metrics) ObserveError(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	m.errors.Add(context.Background(), 1, metric.WithAttributes(append(operationAttributes(operation), attribute.Int("http.response.status_code", result.StatusCode))...))
+}
+
+// operationAttributes returns the attributes that describe the operation.
+func operationAttributes(operation emergencyreporting.Operation) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("emergencyreporting.operation", operation.Name),
+		attribute.String("http.request.method", operation.Method),
+		attribute.String("http.route", operation.Route),
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/tekkamanendless/emergencyreporting/erotel
+
+go 1.23.0
+
+require (
+	github.com/tekkamanendless/emergencyreporting v0.0.0
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/metric v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+replace github.com/tekkamanendless/emergencyreporting => ../
@@ -0,0 +1,123 @@
+// Package erprometheus records Emergency Reporting client metrics with Prometheus.
+//
+//	metrics := erprometheus.NewMetrics("")
+//	prometheus.MustRegister(metrics)
+//	client.Metrics = metrics
+//
+// This is a separate module so that the client itself does not depend on Prometheus.
+package erprometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// DefaultNamespace is the metric namespace if none is given.
+const DefaultNamespace = "emergencyreporting"
+
+// Metrics is an `emergencyreporting.Metrics` that is also a `prometheus.Collector`.
+type Metrics struct {
+	duration      *prometheus.HistogramVec
+	requestBytes  *prometheus.CounterVec
+	responseBytes *prometheus.CounterVec
+	retries       *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics with the given namespace (or `DefaultNamespace` if empty).
+//
+// The metrics still need to be registered.
+func NewMetrics(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	labels := []string{"operation", "method", "route", "status"}
+	return &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "The duration of each operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		requestBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_bytes_total",
+			Help:      "The number of request body bytes sent.",
+		}, labels),
+		responseBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "response_bytes_total",
+			Help:      "The number of response body bytes received.",
+		}, labels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "The number of retried operations.",
+		}, []string{"operation", "method", "route"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "The number of failed operations.",
+		}, labels),
+	}
+}
+
+// Describe implements `prometheus.Collector`.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.requestBytes.Describe(ch)
+	m.responseBytes.Describe(ch)
+	m.retries.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+// Collect implements `prometheus.Collector`.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.requestBytes.Collect(ch)
+	m.responseBytes.Collect(ch)
+	m.retries.Collect(ch)
+	m.errors.Collect(ch)
+}
+
+// ObserveOperation records the duration and sizes.
+func (m *Metrics) ObserveOperation(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	labels := resultLabels(operation, result)
+	m.duration.With(labels).Observe(result.Duration.Seconds())
+	if result.RequestBytes > 0 {
+		m.requestBytes.With(labels).Add(float64(result.RequestBytes))
+	}
+	m.responseBytes.With(labels).Add(float64(result.ResponseBytes))
+}
+
+// ObserveRetry counts a retry.
+func (m *Metrics) ObserveRetry(operation emergencyreporting.Operation) {
+	m.retries.With(prometheus.Labels{
+		"operation": operation.Name,
+		"method":    operation.Method,
+		"route":     operation.Route,
+	}).Inc()
+}
+
+// ObserveError counts an error.
+func (m *Metrics) ObserveError(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	m.errors.With(resultLabels(operation, result)).Inc()
+}
+
+// resultLabels returns the labels for the operation and its result.
+//
+// Operations without a response have a status of "error".
+func resultLabels(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) prometheus.Labels {
+	status := "error"
+	if result.StatusCode != 0 {
+		status = strconv.Itoa(result.StatusCode)
+	}
+	return prometheus.Labels{
+		"operation": operation.Name,
+		"method":    operation.Method,
+		"route":     operation.Route,
+		"status":    status,
+	}
+}
@@ -0,0 +1,41 @@
+// Package erslog adapts "log/slog" to the Emergency Reporting client's leveled logger.
+//
+//	client.LeveledLogger = erslog.New(slog.Default())
+package erslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger is an `emergencyreporting.LeveledLogger` that writes to slog.
+type Logger struct {
+	logger *slog.Logger
+}
+
+// New returns a leveled logger that writes to the given slog logger.
+func New(logger *slog.Logger) *Logger {
+	return &Logger{
+		logger: logger,
+	}
+}
+
+// Debug logs a message at the debug level.
+func (l *Logger) Debug(message string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelDebug, message, keysAndValues...)
+}
+
+// Info logs a message at the info level.
+func (l *Logger) Info(message string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelInfo, message, keysAndValues...)
+}
+
+// Warn logs a message at the warn level.
+func (l *Logger) Warn(message string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelWarn, message, keysAndValues...)
+}
+
+// Error logs a message at the error level.
+func (l *Logger) Error(message string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelError, message, keysAndValues...)
+}
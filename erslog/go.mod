@@ -0,0 +1,3 @@
+module github.com/tekkamanendless/emergencyreporting/erslog
+
+go 1.21
@@ -0,0 +1,29 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddPatientCareReport adds an EMS patient care report and returns its ID.
+func (s *Server) AddPatientCareReport(report emergencyreporting.PatientCareReport) string {
+	return s.add(&s.patientCareReports, "", report)
+}
+
+// serveEMS handles the EMS endpoints, returning false if the path is not one of them.
+//
+// Patient care reports are read-only.
+//
+// The caller must hold the mutex.
+func (s *Server) serveEMS(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyems", "patientcarereports"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.patientCareReports.children(""), wrap("patientCareReports"))
+		return true
+	}
+	if params, ok := match(parts, "agencyems", "patientcarereports", "*"); ok && r.Method == http.MethodGet {
+		s.handleItem(w, r, body, s.patientCareReports.find(params[0]), "patientCareReport")
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddEvent adds an event and returns its ID.
+func (s *Server) AddEvent(event emergencyreporting.Event) string {
+	return s.add(&s.events, "", event)
+}
+
+// AddEventAttendee adds an attendee to an event and returns its ID.
+func (s *Server) AddEventAttendee(eventID string, attendee emergencyreporting.EventAttendee) string {
+	attendee.EventID = eventID
+	return s.add(&s.eventAttendees, eventID, attendee)
+}
+
+// AddPaygrade adds a pay grade and returns its ID.
+func (s *Server) AddPaygrade(paygrade emergencyreporting.Paygrade) string {
+	return s.add(&s.paygrades, "", paygrade)
+}
+
+// serveEvents handles the event and payroll endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyevents", "events"); ok {
+		s.handleCollection(w, r, body, &s.events, "", "", "events")
+		return true
+	}
+	if params, ok := match(parts, "agencyevents", "events", "*"); ok {
+		s.handleItem(w, r, body, s.events.find(params[0]), "event")
+		return true
+	}
+	if params, ok := match(parts, "agencyevents", "events", "*", "attendees"); ok {
+		if s.events.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleCollection(w, r, body, &s.eventAttendees, params[0], "eventID", "attendees")
+		return true
+	}
+	if params, ok := match(parts, "agencyevents", "events", "*", "attendees", "*"); ok {
+		s.handleItem(w, r, body, s.eventAttendees.findChild(params[0], params[1]), "attendee")
+		return true
+	}
+	if _, ok := match(parts, "agencypayroll", "paygrades"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.paygrades.children(""), wrap("paygrades"))
+		return true
+	}
+	if _, ok := match(parts, "agencypayroll", "exports"); ok {
+		s.handleCollection(w, r, body, &s.payrollExports, "", "", "exports")
+		return true
+	}
+	return false
+}
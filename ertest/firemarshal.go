@@ -0,0 +1,71 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddFireMarshalInspection adds a fire marshal inspection and returns its ID.
+func (s *Server) AddFireMarshalInspection(inspection emergencyreporting.FireMarshalInspection) string {
+	return s.add(&s.fireMarshalInspections, "", inspection)
+}
+
+// AddFireMarshalViolation adds a violation to a fire marshal inspection and returns its ID.
+func (s *Server) AddFireMarshalViolation(inspectionID string, violation emergencyreporting.FireMarshalViolation) string {
+	violation.InspectionID = inspectionID
+	return s.add(&s.fireMarshalViolations, inspectionID, violation)
+}
+
+// AddFireMarshalReInspection adds a re-inspection to a fire marshal inspection and returns its ID.
+func (s *Server) AddFireMarshalReInspection(inspectionID string, reInspection emergencyreporting.FireMarshalReInspection) string {
+	reInspection.InspectionID = inspectionID
+	return s.add(&s.fireMarshalReInspections, inspectionID, reInspection)
+}
+
+// FireMarshalInspection returns the current state of a fire marshal inspection, or nil if it does not exist.
+func (s *Server) FireMarshalInspection(inspectionID string) *emergencyreporting.FireMarshalInspection {
+	var inspection *emergencyreporting.FireMarshalInspection
+	if !s.get(&s.fireMarshalInspections, inspectionID, &inspection) {
+		return nil
+	}
+	return inspection
+}
+
+// serveFireMarshal handles the fire marshal endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveFireMarshal(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyfiremarshal", "inspections"); ok {
+		s.handleCollection(w, r, body, &s.fireMarshalInspections, "", "", "inspections")
+		return true
+	}
+	if params, ok := match(parts, "agencyfiremarshal", "inspections", "*"); ok {
+		s.handleItem(w, r, body, s.fireMarshalInspections.find(params[0]), "inspection")
+		return true
+	}
+	children := []struct {
+		segment    string
+		collection *collection
+		listKey    string
+		itemKey    string
+	}{
+		{"violations", &s.fireMarshalViolations, "violations", "violation"},
+		{"reinspections", &s.fireMarshalReInspections, "reInspections", "reInspection"},
+	}
+	for _, child := range children {
+		if params, ok := match(parts, "agencyfiremarshal", "inspections", "*", child.segment); ok {
+			if s.fireMarshalInspections.find(params[0]) == nil {
+				s.writeNotFound(w)
+				return true
+			}
+			s.handleCollection(w, r, body, child.collection, params[0], "inspectionID", child.listKey)
+			return true
+		}
+		if params, ok := match(parts, "agencyfiremarshal", "inspections", "*", child.segment, "*"); ok {
+			s.handleItem(w, r, body, child.collection.findChild(params[0], params[1]), child.itemKey)
+			return true
+		}
+	}
+	return false
+}
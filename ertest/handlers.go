@@ -0,0 +1,648 @@
+package ertest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// serveHTTP handles every request to the server.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		s.writeError(w, f.status, f.errorType, f.message)
+		return
+	}
+
+	if strings.HasSuffix(r.URL.Path, "/B2C_1A_PasswordGrant/oauth2/v2.0/token") {
+		s.handleToken(w, r, body)
+		return
+	}
+
+	if r.Header.Get("Ocp-Apim-Subscription-Key") != s.SubscriptionKey {
+		s.writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid subscription key.")
+		return
+	}
+	if r.Header.Get("Authorization") != s.Token {
+		s.writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid token.")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if _, ok := match(parts, "agencystations", "stations"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.stations.children(""), func(items []map[string]interface{}) interface{} {
+			return map[string]interface{}{"totalRows": strconv.Itoa(len(items)), "stations": items}
+		})
+		return
+	}
+	if _, ok := match(parts, "agencyincidents", "incidents", "exposures"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.exposures.children(""), wrap("exposures"))
+		return
+	}
+	if _, ok := match(parts, "agencyincidents", "incidents"); ok {
+		switch r.Method {
+		case http.MethodGet:
+			s.handleList(w, r, s.incidents.children(""), wrap("incidents"))
+		case http.MethodPost:
+			s.handlePostIncident(w, body)
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "incidents", "*"); ok {
+		incident := s.incidents.find(params[0])
+		if incident == nil {
+			s.writeNotFound(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"incident": incident.fields})
+		case http.MethodPatch:
+			s.handleMergePatch(w, r, incident, body)
+		case http.MethodDelete:
+			if !s.checkRowVersion(w, r, incident) {
+				return
+			}
+			s.incidents.remove(params[0])
+			for _, exposure := range s.exposures.children(params[0]) {
+				s.removeExposure(fieldString(exposure.fields["exposureID"]))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "incidents", "*", "exposures"); ok {
+		if s.incidents.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.handleList(w, r, s.exposures.children(params[0]), wrap("exposures"))
+		case http.MethodPost:
+			fields, ok := s.decodeObject(w, body)
+			if !ok {
+				return
+			}
+			fields["incidentID"] = params[0]
+			id := s.insert(&s.exposures, params[0], fields)
+			s.writeJSON(w, http.StatusCreated, map[string]interface{}{"exposureID": id})
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "incidents", "*", "exposures", "*"); ok {
+		exposure := s.exposures.find(params[1])
+		if exposure == nil || exposure.parent != params[0] {
+			s.writeNotFound(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"exposure": exposure.fields})
+		case http.MethodPatch:
+			s.handleMergePatch(w, r, exposure, body)
+		case http.MethodDelete:
+			if !s.checkRowVersion(w, r, exposure) {
+				return
+			}
+			s.removeExposure(params[1])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "exposures", "*", "location"); ok {
+		if s.exposures.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return
+		}
+		location := s.locations.find(params[0])
+		switch r.Method {
+		case http.MethodGet:
+			if location == nil {
+				s.writeNotFound(w)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"exposureLocation": location.fields})
+		case http.MethodPut:
+			if location != nil && !s.checkRowVersion(w, r, location) {
+				return
+			}
+			fields, ok := s.decodeObject(w, body)
+			if !ok {
+				return
+			}
+			fields["exposureID"] = params[0]
+			fields["rowVersion"] = s.nextRowVersion()
+			if location == nil {
+				s.locations.insert(&record{parent: params[0], fields: fields})
+			} else {
+				location.fields = fields
+			}
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"rowVersion": fields["rowVersion"]})
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "exposures", "*", "fire"); ok && r.Method == http.MethodGet {
+		fire := s.fires.find(params[0])
+		if fire == nil {
+			s.writeNotFound(w)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{"exposureFire": fire.fields})
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "exposures", "*", "apparatuses"); ok {
+		exposure := s.exposures.find(params[0])
+		if exposure == nil {
+			s.writeNotFound(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.handleList(w, r, s.apparatuses.children(params[0]), wrap("exposureApparatuses"))
+		case http.MethodPost:
+			fields, ok := s.decodeObject(w, body)
+			if !ok {
+				return
+			}
+			fields["exposureID"] = params[0]
+			fields["incidentID"] = exposure.parent
+			delete(fields, "apparatusID")
+			id := s.insert(&s.apparatuses, params[0], fields)
+			s.writeJSON(w, http.StatusCreated, map[string]interface{}{"apparatusID": id})
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "exposures", "*", "crewmembers"); ok {
+		if s.exposures.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return
+		}
+		s.handleCollection(w, r, body, &s.crewMembers, params[0], "exposureID", "crewMembers")
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "exposures", "*", "crewmembers", "*"); ok && r.Method == http.MethodGet {
+		member := s.crewMembers.find(params[1])
+		if member == nil || member.parent != params[0] {
+			s.writeNotFound(w)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{"crewMember": member.fields})
+		return
+	}
+	if params, ok := match(parts, "agencyincidents", "crewmembers", "*", "roles"); ok && r.Method == http.MethodGet {
+		if s.crewMembers.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return
+		}
+		s.handleList(w, r, s.crewMemberRoles.children(params[0]), wrap("roles"))
+		return
+	}
+	if _, ok := match(parts, "agencyusers", "users"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.users.children(""), wrap("users"))
+		return
+	}
+	if params, ok := match(parts, "agencyusers", "users", "*"); ok {
+		user := s.users.find(params[0])
+		if user == nil {
+			s.writeNotFound(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"user": user.fields})
+		case http.MethodPatch:
+			s.handleJSONPatch(w, r, user, body)
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if params, ok := match(parts, "agencyusers", "users", "*", "contactinfo"); ok {
+		contactInfo := s.contactInfo.find(params[0])
+		switch r.Method {
+		case http.MethodGet:
+			if contactInfo == nil {
+				s.writeNotFound(w)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"contactInfo": contactInfo.fields})
+		case http.MethodPut:
+			if s.users.find(params[0]) == nil {
+				s.writeNotFound(w)
+				return
+			}
+			if contactInfo != nil && !s.checkRowVersion(w, r, contactInfo) {
+				return
+			}
+			fields, ok := s.decodeObject(w, body)
+			if !ok {
+				return
+			}
+			fields["userID"] = params[0]
+			fields["rowVersion"] = s.nextRowVersion()
+			if contactInfo == nil {
+				s.contactInfo.insert(&record{parent: params[0], fields: fields})
+			} else {
+				contactInfo.fields = fields
+			}
+			s.writeJSON(w, http.StatusOK, map[string]interface{}{"rowVersion": fields["rowVersion"]})
+		default:
+			s.writeMethodNotAllowed(w)
+		}
+		return
+	}
+	if _, ok := match(parts, "agencyapparatus", "apparatus"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.agencyApparatus.children(""), wrap("apparatus"))
+		return
+	}
+	if params, ok := match(parts, "agencyapparatus", "apparatus", "*"); ok && r.Method == http.MethodGet {
+		apparatus := s.agencyApparatus.find(params[0])
+		if apparatus == nil {
+			s.writeNotFound(w)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{"apparatus": apparatus.fields})
+		return
+	}
+	if s.serveUserLicenses(w, r, parts, body) {
+		return
+	}
+	if s.serveHydrants(w, r, parts, body) {
+		return
+	}
+	if s.serveOccupancies(w, r, parts, body) {
+		return
+	}
+	if s.serveFireMarshal(w, r, parts, body) {
+		return
+	}
+	if s.serveTraining(w, r, parts, body) {
+		return
+	}
+	if s.serveRosters(w, r, parts, body) {
+		return
+	}
+	if s.serveEvents(w, r, parts, body) {
+		return
+	}
+	if s.serveMaintenance(w, r, parts, body) {
+		return
+	}
+	if s.serveEMS(w, r, parts, body) {
+		return
+	}
+
+	s.writeNotFound(w)
+}
+
+// handleToken handles the B2C password grant.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request, body []byte) {
+	if r.Method != http.MethodPost {
+		s.writeMethodNotAllowed(w)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+	if form.Get("grant_type") != "password" ||
+		form.Get("username") != s.Username ||
+		form.Get("password") != s.Password ||
+		form.Get("client_id") != s.ClientID ||
+		form.Get("client_secret") != s.ClientSecret ||
+		form.Get("er_aid") != s.AccountID ||
+		form.Get("er_uid") != s.UserID {
+		s.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant", "error_description": "The credentials are not valid."})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": s.Token,
+		"token_type":   "Bearer",
+		"expires_in":   "3600",
+	})
+}
+
+// handleList writes the matching records using the given wrapper.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, records []*record, wrapper func([]map[string]interface{}) interface{}) {
+	results, err := query(records, r.URL.Query())
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "InvalidQuery", err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, wrapper(results))
+}
+
+// handlePostIncident creates a new incident.
+//
+// Incident numbers must be unique.
+func (s *Server) handlePostIncident(w http.ResponseWriter, body []byte) {
+	fields, ok := s.decodeObject(w, body)
+	if !ok {
+		return
+	}
+	delete(fields, "incidentID")
+	if incidentNumber := fieldString(fields["incidentNumber"]); incidentNumber != "" {
+		for _, existing := range s.incidents.records {
+			if fieldString(existing.fields["incidentNumber"]) == incidentNumber {
+				s.writeError(w, http.StatusConflict, "Duplicate", "An incident with this incident number already exists.")
+				return
+			}
+		}
+	}
+	id := s.insert(&s.incidents, "", fields)
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{"incidentID": id})
+}
+
+// handleMergePatch updates the record with the fields in the body.
+func (s *Server) handleMergePatch(w http.ResponseWriter, r *http.Request, existing *record, body []byte) {
+	if !s.checkRowVersion(w, r, existing) {
+		return
+	}
+	fields, ok := s.decodeObject(w, body)
+	if !ok {
+		return
+	}
+	for key, value := range fields {
+		if value == nil {
+			continue
+		}
+		existing.fields[key] = value
+	}
+	existing.fields["rowVersion"] = s.nextRowVersion()
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"rowVersion": existing.fields["rowVersion"]})
+}
+
+// handleJSONPatch updates the record with the JSON Patch operations in the body.
+//
+// The operations are all-or-nothing; if any of them fail, then the record is left alone.
+func (s *Server) handleJSONPatch(w http.ResponseWriter, r *http.Request, existing *record, body []byte) {
+	if !s.checkRowVersion(w, r, existing) {
+		return
+	}
+	var operations []struct {
+		Operation string      `json:"op"`
+		Path      string      `json:"path"`
+		Value     interface{} `json:"value"`
+	}
+	err := json.Unmarshal(body, &operations)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "InvalidJSON", err.Error())
+		return
+	}
+
+	contents, _ := json.Marshal(existing.fields)
+	document := map[string]interface{}{}
+	_ = json.Unmarshal(contents, &document)
+
+	var result interface{} = document
+	for _, operation := range operations {
+		if !strings.HasPrefix(operation.Path, "/") {
+			s.writeError(w, http.StatusBadRequest, "InvalidPatch", "Invalid path: "+operation.Path)
+			return
+		}
+		var tokens []string
+		for _, token := range strings.Split(operation.Path[1:], "/") {
+			tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+
+		switch operation.Operation {
+		case "add", "replace", "remove", "test":
+		default:
+			s.writeError(w, http.StatusBadRequest, "InvalidPatch", "Unsupported operation: "+operation.Operation)
+			return
+		}
+
+		var errorType string
+		result, errorType = applyPatch(result, tokens, operation.Operation, operation.Value)
+		switch errorType {
+		case "":
+		case "PatchTestFailed":
+			s.writeError(w, http.StatusConflict, errorType, "Test failed: "+operation.Path)
+			return
+		default:
+			s.writeError(w, http.StatusBadRequest, errorType, "Invalid path: "+operation.Path)
+			return
+		}
+	}
+
+	document = result.(map[string]interface{})
+	existing.fields = document
+	existing.fields["rowVersion"] = s.nextRowVersion()
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"rowVersion": existing.fields["rowVersion"]})
+}
+
+// applyPatch applies a single JSON Patch operation at the path (as tokens) within the value.
+//
+// This returns the updated value, or the type of error if the operation failed.
+func applyPatch(value interface{}, tokens []string, operation string, newValue interface{}) (interface{}, string) {
+	if len(tokens) == 0 {
+		switch operation {
+		case "test":
+			if !reflect.DeepEqual(value, newValue) {
+				return value, "PatchTestFailed"
+			}
+			return value, ""
+		case "remove":
+			return value, "InvalidPatch"
+		}
+		return newValue, ""
+	}
+	token := tokens[0]
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		child, ok := typedValue[token]
+		if len(tokens) == 1 {
+			switch {
+			case !ok && operation != "add":
+				return value, "InvalidPatch"
+			case operation == "remove":
+				delete(typedValue, token)
+				return value, ""
+			}
+		} else if !ok {
+			return value, "InvalidPatch"
+		}
+		child, errorType := applyPatch(child, tokens[1:], operation, newValue)
+		if errorType != "" {
+			return value, errorType
+		}
+		typedValue[token] = child
+		return value, ""
+	case []interface{}:
+		if len(tokens) == 1 && operation == "add" {
+			index := len(typedValue)
+			if token != "-" {
+				var err error
+				index, err = strconv.Atoi(token)
+				if err != nil || index < 0 || index > len(typedValue) {
+					return value, "InvalidPatch"
+				}
+			}
+			result := append([]interface{}{}, typedValue[:index]...)
+			result = append(result, newValue)
+			return append(result, typedValue[index:]...), ""
+		}
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(typedValue) {
+			return value, "InvalidPatch"
+		}
+		if len(tokens) == 1 && operation == "remove" {
+			return append(append([]interface{}{}, typedValue[:index]...), typedValue[index+1:]...), ""
+		}
+		child, errorType := applyPatch(typedValue[index], tokens[1:], operation, newValue)
+		if errorType != "" {
+			return value, errorType
+		}
+		typedValue[index] = child
+		return value, ""
+	}
+	return value, "InvalidPatch"
+}
+
+// removeExposure removes an exposure and everything that belongs to it.
+func (s *Server) removeExposure(exposureID string) {
+	s.exposures.remove(exposureID)
+	s.locations.remove(exposureID)
+	s.fires.remove(exposureID)
+	s.apparatuses.removeChildren(exposureID)
+	for _, member := range s.crewMembers.children(exposureID) {
+		s.crewMemberRoles.removeChildren(fieldString(member.fields["exposureUserID"]))
+	}
+	s.crewMembers.removeChildren(exposureID)
+}
+
+// insert adds the fields to the collection as a new record and returns its ID.
+//
+// The caller must hold the mutex.
+func (s *Server) insert(c *collection, parent string, fields map[string]interface{}) string {
+	id := s.nextID()
+	fields[c.idField] = id
+	fields["rowVersion"] = s.nextRowVersion()
+	c.insert(&record{parent: parent, fields: fields})
+	return id
+}
+
+// checkRowVersion makes sure that the "ETag" header, if present, matches the record's row version.
+//
+// If it does not, then an error is written and this returns false.
+func (s *Server) checkRowVersion(w http.ResponseWriter, r *http.Request, existing *record) bool {
+	rowVersion := r.Header.Get("ETag")
+	if rowVersion == "" || rowVersion == fieldString(existing.fields["rowVersion"]) {
+		return true
+	}
+	s.writeError(w, http.StatusPreconditionFailed, "RowVersionMismatch", "The row version does not match.")
+	return false
+}
+
+// decodeObject decodes the body as a JSON object.
+//
+// If it cannot, then an error is written and this returns false.
+func (s *Server) decodeObject(w http.ResponseWriter, body []byte) (map[string]interface{}, bool) {
+	fields := map[string]interface{}{}
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "InvalidJSON", err.Error())
+		return nil, false
+	}
+	return fields, true
+}
+
+// writeJSON writes the value as JSON.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(contents)
+}
+
+// writeError writes an error in the configured shape.
+//
+// If the error type is empty, then the body is an empty object.
+func (s *Server) writeError(w http.ResponseWriter, status int, errorType string, message string) {
+	if errorType == "" {
+		s.writeJSON(w, status, map[string]interface{}{})
+		return
+	}
+	e := map[string]interface{}{"type": errorType, "message": message}
+	if s.ErrorShape == ErrorShapeBuggy {
+		s.writeJSON(w, status, map[string]interface{}{"errors": e})
+		return
+	}
+	s.writeJSON(w, status, map[string]interface{}{"errors": []interface{}{e}})
+}
+
+// writeNotFound writes a 404 with no error type, which the client turns into `ErrorNotFound`.
+func (s *Server) writeNotFound(w http.ResponseWriter) {
+	s.writeError(w, http.StatusNotFound, "", "")
+}
+
+// writeMethodNotAllowed writes a 405.
+func (s *Server) writeMethodNotAllowed(w http.ResponseWriter) {
+	s.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The method is not allowed.")
+}
+
+// match returns whether the path parts match the pattern, along with the values of the "*" parts.
+func match(parts []string, pattern ...string) ([]string, bool) {
+	if len(parts) != len(pattern) {
+		return nil, false
+	}
+	var params []string
+	for index, part := range pattern {
+		if part == "*" {
+			params = append(params, parts[index])
+			continue
+		}
+		if part != parts[index] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// wrap returns a list wrapper that puts the items under the given key.
+func wrap(key string) func([]map[string]interface{}) interface{} {
+	return func(items []map[string]interface{}) interface{} {
+		return map[string]interface{}{key: items}
+	}
+}
@@ -0,0 +1,64 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddHydrant adds a hydrant and returns its ID.
+func (s *Server) AddHydrant(hydrant emergencyreporting.Hydrant) string {
+	return s.add(&s.hydrants, "", hydrant)
+}
+
+// AddHydrantFlowTest adds a flow test to a hydrant and returns its ID.
+func (s *Server) AddHydrantFlowTest(hydrantID string, flowTest emergencyreporting.HydrantFlowTest) string {
+	flowTest.HydrantID = hydrantID
+	return s.add(&s.hydrantFlowTests, hydrantID, flowTest)
+}
+
+// AddHydrantInspection adds an inspection to a hydrant and returns its ID.
+func (s *Server) AddHydrantInspection(hydrantID string, inspection emergencyreporting.HydrantInspection) string {
+	inspection.HydrantID = hydrantID
+	return s.add(&s.hydrantInspections, hydrantID, inspection)
+}
+
+// Hydrant returns the current state of a hydrant, or nil if it does not exist.
+func (s *Server) Hydrant(hydrantID string) *emergencyreporting.Hydrant {
+	var hydrant *emergencyreporting.Hydrant
+	if !s.get(&s.hydrants, hydrantID, &hydrant) {
+		return nil
+	}
+	return hydrant
+}
+
+// serveHydrants handles the hydrant endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveHydrants(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyhydrants", "hydrants"); ok {
+		s.handleCollection(w, r, body, &s.hydrants, "", "", "hydrants")
+		return true
+	}
+	if params, ok := match(parts, "agencyhydrants", "hydrants", "*"); ok {
+		s.handleItem(w, r, body, s.hydrants.find(params[0]), "hydrant")
+		return true
+	}
+	if params, ok := match(parts, "agencyhydrants", "hydrants", "*", "flowtests"); ok {
+		if s.hydrants.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleCollection(w, r, body, &s.hydrantFlowTests, params[0], "hydrantID", "flowTests")
+		return true
+	}
+	if params, ok := match(parts, "agencyhydrants", "hydrants", "*", "inspections"); ok {
+		if s.hydrants.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleCollection(w, r, body, &s.hydrantInspections, params[0], "hydrantID", "inspections")
+		return true
+	}
+	return false
+}
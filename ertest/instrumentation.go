@@ -0,0 +1,111 @@
+package ertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// RecordedSpan is a span that `Instrumentation` has recorded.
+type RecordedSpan struct {
+	Operation emergencyreporting.Operation
+	Result    emergencyreporting.OperationResult
+}
+
+// Instrumentation records spans and metrics in memory.
+//
+// It implements both `emergencyreporting.Tracer` and `emergencyreporting.Metrics`, so it
+// can be used to check the instrumentation without a collector:
+//
+//	instrumentation := &ertest.Instrumentation{}
+//	client.Tracer = instrumentation
+//	client.Metrics = instrumentation
+type Instrumentation struct {
+	mutex      sync.Mutex
+	spans      []RecordedSpan
+	operations []RecordedSpan
+	retries    map[string]int
+	errors     map[string]int
+}
+
+// recordedSpan is a span that has been started.
+type recordedSpan struct {
+	instrumentation *Instrumentation
+	operation       emergencyreporting.Operation
+}
+
+// StartSpan starts a span.
+func (i *Instrumentation) StartSpan(ctx context.Context, operation emergencyreporting.Operation) (context.Context, emergencyreporting.Span) {
+	return ctx, &recordedSpan{instrumentation: i, operation: operation}
+}
+
+// End records the span.
+func (s *recordedSpan) End(result emergencyreporting.OperationResult) {
+	s.instrumentation.mutex.Lock()
+	defer s.instrumentation.mutex.Unlock()
+
+	s.instrumentation.spans = append(s.instrumentation.spans, RecordedSpan{Operation: s.operation, Result: result})
+}
+
+// ObserveOperation records an operation.
+func (i *Instrumentation) ObserveOperation(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.operations = append(i.operations, RecordedSpan{Operation: operation, Result: result})
+}
+
+// ObserveRetry counts a retry.
+func (i *Instrumentation) ObserveRetry(operation emergencyreporting.Operation) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.retries == nil {
+		i.retries = map[string]int{}
+	}
+	i.retries[operation.Name]++
+}
+
+// ObserveError counts an error.
+func (i *Instrumentation) ObserveError(operation emergencyreporting.Operation, result emergencyreporting.OperationResult) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.errors == nil {
+		i.errors = map[string]int{}
+	}
+	i.errors[operation.Name]++
+}
+
+// Spans returns the finished spans, in order.
+func (i *Instrumentation) Spans() []RecordedSpan {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return append([]RecordedSpan(nil), i.spans...)
+}
+
+// Operations returns the observed operations, in order.
+func (i *Instrumentation) Operations() []RecordedSpan {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return append([]RecordedSpan(nil), i.operations...)
+}
+
+// Retries returns the number of retries for the named operation.
+func (i *Instrumentation) Retries(name string) int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.retries[name]
+}
+
+// Errors returns the number of errors for the named operation.
+func (i *Instrumentation) Errors(name string) int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.errors[name]
+}
@@ -0,0 +1,86 @@
+package ertest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	ctx := context.Background()
+	server := ertest.NewServer()
+	defer server.Close()
+
+	incidentID := server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2021-0001"})
+	client := newClient(t, server)
+
+	instrumentation := &ertest.Instrumentation{}
+	client.Tracer = instrumentation
+	client.Metrics = instrumentation
+
+	_, err := client.GetIncident(ctx, incidentID)
+	if err != nil {
+		t.Fatalf("Could not get the incident: %v", err)
+	}
+	_, err = client.GetIncident(ctx, "999999")
+	if err == nil {
+		t.Fatalf("Expected an error for a missing incident")
+	}
+	_, err = client.RawOperation(ctx, http.MethodGet, "/not/a/route", nil, nil, nil)
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown route")
+	}
+
+	expected := []struct {
+		name       string
+		route      string
+		statusCode int
+	}{
+		{"GetIncident", "/agencyincidents/incidents/{incidentID}", http.StatusOK},
+		{"GetIncident", "/agencyincidents/incidents/{incidentID}", http.StatusNotFound},
+		{"RawOperation", "", http.StatusNotFound},
+	}
+	for _, recorded := range [][]ertest.RecordedSpan{instrumentation.Spans(), instrumentation.Operations()} {
+		if len(recorded) != len(expected) {
+			t.Fatalf("Expected %d spans; got %d", len(expected), len(recorded))
+		}
+		for index, span := range recorded {
+			if span.Operation.Name != expected[index].name || span.Operation.Route != expected[index].route {
+				t.Errorf("Span %d: expected %s %q; got %s %q", index, expected[index].name, expected[index].route, span.Operation.Name, span.Operation.Route)
+			}
+			if span.Operation.Method != http.MethodGet {
+				t.Errorf("Span %d: expected method GET; got %s", index, span.Operation.Method)
+			}
+			if span.Result.StatusCode != expected[index].statusCode {
+				t.Errorf("Span %d: expected status %d; got %d", index, expected[index].statusCode, span.Result.StatusCode)
+			}
+		}
+	}
+	if instrumentation.Spans()[0].Result.ResponseBytes == 0 {
+		t.Errorf("Expected the response size to be counted")
+	}
+
+	if actual := instrumentation.Errors("GetIncident"); actual != 1 {
+		t.Errorf("Expected 1 GetIncident error; got %d", actual)
+	}
+	if actual := instrumentation.Errors("RawOperation"); actual != 1 {
+		t.Errorf("Expected 1 RawOperation error; got %d", actual)
+	}
+	if actual := instrumentation.Retries("GetIncident"); actual != 0 {
+		t.Errorf("Expected no retries; got %d", actual)
+	}
+
+	client.Use(emergencyreporting.Retry(2, time.Millisecond))
+	server.FailNext(http.StatusServiceUnavailable, "Unavailable", "Try again")
+	_, err = client.GetIncident(ctx, incidentID)
+	if err != nil {
+		t.Fatalf("Could not get the incident after a retry: %v", err)
+	}
+	if actual := instrumentation.Retries("GetIncident"); actual != 1 {
+		t.Errorf("Expected 1 retry; got %d", actual)
+	}
+}
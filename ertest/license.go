@@ -0,0 +1,101 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddUserCertification adds a certification to a user and returns its ID.
+//
+// Unlike licenses, certifications are not part of the user.
+func (s *Server) AddUserCertification(userID string, certification emergencyreporting.UserCertification) string {
+	return s.add(&s.certifications, userID, certification)
+}
+
+// serveUserLicenses handles the user license and certification endpoints, returning false if the path is not one of them.
+//
+// The licenses are stored in the user's "licenses" field, so they show up on the user as well.
+//
+// The caller must hold the mutex.
+func (s *Server) serveUserLicenses(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if params, ok := match(parts, "agencyusers", "users", "*", "licenses"); ok {
+		user := s.users.find(params[0])
+		if user == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		if r.Method != http.MethodGet {
+			s.writeMethodNotAllowed(w)
+			return true
+		}
+		var records []*record
+		for _, license := range userLicenses(user) {
+			records = append(records, &record{fields: license})
+		}
+		s.handleList(w, r, records, wrap("licenses"))
+		return true
+	}
+	if params, ok := match(parts, "agencyusers", "users", "*", "licenses", "*"); ok {
+		user := s.users.find(params[0])
+		if user == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		if r.Method != http.MethodPatch {
+			s.writeMethodNotAllowed(w)
+			return true
+		}
+		for _, license := range userLicenses(user) {
+			if fieldString(license["licenseID"]) == params[1] {
+				// The license is updated in place, since the map is shared with the user.
+				s.handleMergePatch(w, r, &record{fields: license}, body)
+				return true
+			}
+		}
+		s.writeNotFound(w)
+		return true
+	}
+	if params, ok := match(parts, "agencyusers", "users", "*", "certifications"); ok {
+		if s.users.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		if r.Method != http.MethodGet {
+			s.writeMethodNotAllowed(w)
+			return true
+		}
+		s.handleList(w, r, s.certifications.children(params[0]), wrap("certifications"))
+		return true
+	}
+	if params, ok := match(parts, "agencyusers", "users", "*", "certifications", "*"); ok {
+		if s.users.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		if r.Method != http.MethodPatch {
+			s.writeMethodNotAllowed(w)
+			return true
+		}
+		existing := s.certifications.findChild(params[0], params[1])
+		if existing == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleMergePatch(w, r, existing, body)
+		return true
+	}
+	return false
+}
+
+// userLicenses returns the licenses in the user's "licenses" field.
+func userLicenses(user *record) []map[string]interface{} {
+	var licenses []map[string]interface{}
+	list, _ := user.fields["licenses"].([]interface{})
+	for _, item := range list {
+		if license, ok := item.(map[string]interface{}); ok {
+			licenses = append(licenses, license)
+		}
+	}
+	return licenses
+}
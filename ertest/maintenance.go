@@ -0,0 +1,62 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddMaintenanceRecord adds a maintenance record and returns its ID.
+func (s *Server) AddMaintenanceRecord(record emergencyreporting.MaintenanceRecord) string {
+	return s.add(&s.maintenanceRecords, "", record)
+}
+
+// AddMaintenanceSchedule adds a maintenance schedule and returns its ID.
+func (s *Server) AddMaintenanceSchedule(schedule emergencyreporting.MaintenanceSchedule) string {
+	return s.add(&s.maintenanceSchedules, "", schedule)
+}
+
+// AddInventoryLocation adds an inventory location and returns its ID.
+func (s *Server) AddInventoryLocation(location emergencyreporting.InventoryLocation) string {
+	return s.add(&s.inventoryLocations, "", location)
+}
+
+// AddInventoryItem adds an inventory item and returns its ID.
+func (s *Server) AddInventoryItem(item emergencyreporting.InventoryItem) string {
+	return s.add(&s.inventoryItems, "", item)
+}
+
+// serveMaintenance handles the maintenance and inventory endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveMaintenance(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencymaintenance", "records"); ok {
+		s.handleCollection(w, r, body, &s.maintenanceRecords, "", "", "records")
+		return true
+	}
+	if params, ok := match(parts, "agencymaintenance", "records", "*"); ok {
+		s.handleItem(w, r, body, s.maintenanceRecords.find(params[0]), "record")
+		return true
+	}
+	if _, ok := match(parts, "agencymaintenance", "schedules"); ok {
+		s.handleCollection(w, r, body, &s.maintenanceSchedules, "", "", "schedules")
+		return true
+	}
+	if params, ok := match(parts, "agencymaintenance", "schedules", "*"); ok {
+		s.handleItem(w, r, body, s.maintenanceSchedules.find(params[0]), "schedule")
+		return true
+	}
+	if _, ok := match(parts, "agencyinventory", "locations"); ok {
+		s.handleCollection(w, r, body, &s.inventoryLocations, "", "", "locations")
+		return true
+	}
+	if _, ok := match(parts, "agencyinventory", "items"); ok {
+		s.handleCollection(w, r, body, &s.inventoryItems, "", "", "items")
+		return true
+	}
+	if params, ok := match(parts, "agencyinventory", "items", "*"); ok {
+		s.handleItem(w, r, body, s.inventoryItems.find(params[0]), "item")
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package ertest
+
+import (
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddOccupancy adds an occupancy and returns its ID.
+func (s *Server) AddOccupancy(occupancy emergencyreporting.Occupancy) string {
+	return s.add(&s.occupancies, "", occupancy)
+}
+
+// AddOccupancyContact adds a contact to an occupancy and returns its ID.
+func (s *Server) AddOccupancyContact(occupancyID string, contact emergencyreporting.OccupancyContact) string {
+	contact.OccupancyID = occupancyID
+	return s.add(&s.occupancyContacts, occupancyID, contact)
+}
+
+// AddOccupancyHazard adds a hazard to an occupancy and returns its ID.
+func (s *Server) AddOccupancyHazard(occupancyID string, hazard emergencyreporting.OccupancyHazard) string {
+	hazard.OccupancyID = occupancyID
+	return s.add(&s.occupancyHazards, occupancyID, hazard)
+}
+
+// AddOccupancyPrePlan adds a pre-plan to an occupancy and returns its ID.
+func (s *Server) AddOccupancyPrePlan(occupancyID string, prePlan emergencyreporting.OccupancyPrePlan) string {
+	prePlan.OccupancyID = occupancyID
+	return s.add(&s.occupancyPrePlans, occupancyID, prePlan)
+}
+
+// Occupancy returns the current state of an occupancy, or nil if it does not exist.
+func (s *Server) Occupancy(occupancyID string) *emergencyreporting.Occupancy {
+	var occupancy *emergencyreporting.Occupancy
+	if !s.get(&s.occupancies, occupancyID, &occupancy) {
+		return nil
+	}
+	return occupancy
+}
+
+// serveOccupancies handles the occupancy endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveOccupancies(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyoccupancies", "occupancies"); ok {
+		s.handleCollection(w, r, body, &s.occupancies, "", "", "occupancies")
+		return true
+	}
+	if params, ok := match(parts, "agencyoccupancies", "occupancies", "*"); ok {
+		s.handleItem(w, r, body, s.occupancies.find(params[0]), "occupancy")
+		return true
+	}
+	children := []struct {
+		segment    string
+		collection *collection
+		listKey    string
+	}{
+		{"contacts", &s.occupancyContacts, "contacts"},
+		{"hazards", &s.occupancyHazards, "hazards"},
+		{"preplans", &s.occupancyPrePlans, "prePlans"},
+	}
+	for _, child := range children {
+		if params, ok := match(parts, "agencyoccupancies", "occupancies", "*", child.segment); ok {
+			if s.occupancies.find(params[0]) == nil {
+				s.writeNotFound(w)
+				return true
+			}
+			s.handleCollection(w, r, body, child.collection, params[0], "occupancyID", child.listKey)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package ertest
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// record is a single stored object.
+type record struct {
+	parent string                 // The ID of the parent object, if any.
+	fields map[string]interface{} // The JSON fields.
+}
+
+// collection is a list of records of the same type.
+type collection struct {
+	idField string // The JSON field that holds the ID.
+	records []*record
+}
+
+// insert adds a record to the collection.
+func (c *collection) insert(r *record) {
+	c.records = append(c.records, r)
+}
+
+// find returns the record with the given ID, or nil.
+func (c *collection) find(id string) *record {
+	for _, r := range c.records {
+		if fieldString(r.fields[c.idField]) == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// findChild returns the record with the given ID and parent, or nil.
+func (c *collection) findChild(parent string, id string) *record {
+	if r := c.find(id); r != nil && r.parent == parent {
+		return r
+	}
+	return nil
+}
+
+// remove removes the record with the given ID and returns whether it was there.
+func (c *collection) remove(id string) bool {
+	for index, r := range c.records {
+		if fieldString(r.fields[c.idField]) == id {
+			c.records = append(c.records[:index], c.records[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// removeChildren removes all of the records with the given parent.
+func (c *collection) removeChildren(parent string) {
+	var records []*record
+	for _, r := range c.records {
+		if r.parent != parent {
+			records = append(records, r)
+		}
+	}
+	c.records = records
+}
+
+// children returns all of the records with the given parent.
+//
+// If the parent is empty, then all of the records are returned.
+func (c *collection) children(parent string) []*record {
+	var records []*record
+	for _, r := range c.records {
+		if parent == "" || r.parent == parent {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// condition is a single comparison in a filter, such as "incidentNumber eq '123'".
+type condition struct {
+	field    string
+	operator string
+	value    string
+}
+
+// query applies the "filter", "orderby", "offset", and "limit" options to the records.
+func query(records []*record, options url.Values) ([]map[string]interface{}, error) {
+	var conditions []condition
+	if filter := options.Get("filter"); filter != "" {
+		var err error
+		conditions, err = parseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var results []map[string]interface{}
+	for _, r := range records {
+		matches := true
+		for _, c := range conditions {
+			if !c.matches(r.fields) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			results = append(results, r.fields)
+		}
+	}
+
+	if orderBy := options.Get("orderby"); orderBy != "" {
+		var keys []string
+		var descending []bool
+		for _, part := range strings.Split(orderBy, ",") {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, fmt.Errorf("invalid orderby: %s", orderBy)
+			}
+			keys = append(keys, words[0])
+			descending = append(descending, len(words) == 2 && strings.EqualFold(words[1], "desc"))
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			for index, key := range keys {
+				comparison := compare(fieldString(results[i][key]), fieldString(results[j][key]))
+				if comparison == 0 {
+					continue
+				}
+				if descending[index] {
+					return comparison > 0
+				}
+				return comparison < 0
+			}
+			return false
+		})
+	}
+
+	if value := options.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset: %s", value)
+		}
+		if offset > len(results) {
+			offset = len(results)
+		}
+		results = results[offset:]
+	}
+	if value := options.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit: %s", value)
+		}
+		if limit < len(results) {
+			results = results[:limit]
+		}
+	}
+
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+	return results, nil
+}
+
+// parseFilter parses a filter such as "incidentNumber eq '123' and stationID eq '4'".
+//
+// Only "and" is supported for combining conditions.
+func parseFilter(filter string) ([]condition, error) {
+	var conditions []condition
+
+	remaining := strings.TrimSpace(filter)
+	for {
+		words := strings.SplitN(remaining, " ", 3)
+		if len(words) < 3 {
+			return nil, fmt.Errorf("invalid filter: %s", filter)
+		}
+		c := condition{field: words[0], operator: strings.ToLower(words[1])}
+		switch c.operator {
+		case "eq", "ne", "gt", "ge", "lt", "le":
+		default:
+			return nil, fmt.Errorf("invalid filter operator: %s", words[1])
+		}
+
+		rest := strings.TrimSpace(words[2])
+		if strings.HasPrefix(rest, "'") {
+			// Quoted values use two single quotes to represent a single quote.
+			var value strings.Builder
+			index := 1
+			for {
+				if index >= len(rest) {
+					return nil, fmt.Errorf("invalid filter: unterminated string: %s", filter)
+				}
+				if rest[index] == '\'' {
+					if index+1 < len(rest) && rest[index+1] == '\'' {
+						value.WriteByte('\'')
+						index += 2
+						continue
+					}
+					break
+				}
+				value.WriteByte(rest[index])
+				index++
+			}
+			c.value = value.String()
+			rest = strings.TrimSpace(rest[index+1:])
+		} else {
+			parts := strings.SplitN(rest, " ", 2)
+			c.value = parts[0]
+			rest = ""
+			if len(parts) > 1 {
+				rest = strings.TrimSpace(parts[1])
+			}
+		}
+		conditions = append(conditions, c)
+
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(strings.ToLower(rest), "and ") {
+			return nil, fmt.Errorf("invalid filter: %s", filter)
+		}
+		remaining = strings.TrimSpace(rest[len("and "):])
+	}
+	return conditions, nil
+}
+
+// matches returns whether the fields satisfy the condition.
+func (c condition) matches(fields map[string]interface{}) bool {
+	comparison := compare(fieldString(fields[c.field]), c.value)
+	switch c.operator {
+	case "eq":
+		return comparison == 0
+	case "ne":
+		return comparison != 0
+	case "gt":
+		return comparison > 0
+	case "ge":
+		return comparison >= 0
+	case "lt":
+		return comparison < 0
+	case "le":
+		return comparison <= 0
+	}
+	return false
+}
+
+// compare compares two values numerically if they are both numbers, and as strings otherwise.
+func compare(a string, b string) int {
+	aNumber, aErr := strconv.ParseFloat(a, 64)
+	bNumber, bErr := strconv.ParseFloat(b, 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// fieldString returns the string form of a JSON field.
+func fieldString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	default:
+		return fmt.Sprintf("%v", typedValue)
+	}
+}
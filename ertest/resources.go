@@ -0,0 +1,46 @@
+package ertest
+
+import (
+	"net/http"
+)
+
+// handleCollection lists (GET) or creates (POST) the records in a collection that belong to the parent.
+//
+// New records get the parent's ID in the parent field (if there is one), and the response has the new ID.
+func (s *Server) handleCollection(w http.ResponseWriter, r *http.Request, body []byte, c *collection, parent string, parentField string, listKey string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleList(w, r, c.children(parent), wrap(listKey))
+	case http.MethodPost:
+		fields, ok := s.decodeObject(w, body)
+		if !ok {
+			return
+		}
+		delete(fields, c.idField)
+		if parentField != "" {
+			fields[parentField] = parent
+		}
+		id := s.insert(c, parent, fields)
+		s.writeJSON(w, http.StatusCreated, map[string]interface{}{c.idField: id})
+	default:
+		s.writeMethodNotAllowed(w)
+	}
+}
+
+// handleItem gets (GET) or updates (PATCH, as a merge patch) a record.
+//
+// If the record is nil, then a 404 is written.
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request, body []byte, existing *record, itemKey string) {
+	if existing == nil {
+		s.writeNotFound(w)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{itemKey: existing.fields})
+	case http.MethodPatch:
+		s.handleMergePatch(w, r, existing, body)
+	default:
+		s.writeMethodNotAllowed(w)
+	}
+}
@@ -0,0 +1,90 @@
+package ertest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddShift adds a shift and returns its ID.
+func (s *Server) AddShift(shift emergencyreporting.Shift) string {
+	return s.add(&s.shifts, "", shift)
+}
+
+// AddShiftCalendarDay adds a shift that is on duty for a day and returns its ID.
+//
+// A day may have more than one shift (such as a day shift and a night shift).
+func (s *Server) AddShiftCalendarDay(day emergencyreporting.ShiftCalendarDay) string {
+	return s.add(&s.shiftCalendar, "", day)
+}
+
+// AddRosterPosition adds a roster position and returns its ID.
+func (s *Server) AddRosterPosition(position emergencyreporting.RosterPosition) string {
+	return s.add(&s.rosterPositions, "", position)
+}
+
+// AddRoster adds a daily roster and returns its ID.
+func (s *Server) AddRoster(roster emergencyreporting.Roster) string {
+	return s.add(&s.rosters, "", roster)
+}
+
+// AddRosterAssignment adds an assignment to a daily roster and returns its ID.
+func (s *Server) AddRosterAssignment(rosterID string, assignment emergencyreporting.RosterAssignment) string {
+	assignment.RosterID = rosterID
+	return s.add(&s.rosterAssignments, rosterID, assignment)
+}
+
+// CrewMembers returns the current crew members of an exposure.
+func (s *Server) CrewMembers(exposureID string) []*emergencyreporting.CrewMember {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var fields []map[string]interface{}
+	for _, r := range s.crewMembers.children(exposureID) {
+		fields = append(fields, r.fields)
+	}
+	contents, _ := json.Marshal(fields)
+	var members []*emergencyreporting.CrewMember
+	_ = json.Unmarshal(contents, &members)
+	return members
+}
+
+// serveRosters handles the shift and roster endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveRosters(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencyshifts", "shifts"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.shifts.children(""), wrap("shifts"))
+		return true
+	}
+	if _, ok := match(parts, "agencyshifts", "calendar"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.shiftCalendar.children(""), wrap("days"))
+		return true
+	}
+	if _, ok := match(parts, "agencyroster", "positions"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.rosterPositions.children(""), wrap("positions"))
+		return true
+	}
+	if _, ok := match(parts, "agencyroster", "rosters"); ok {
+		s.handleCollection(w, r, body, &s.rosters, "", "", "rosters")
+		return true
+	}
+	if params, ok := match(parts, "agencyroster", "rosters", "*"); ok {
+		s.handleItem(w, r, body, s.rosters.find(params[0]), "roster")
+		return true
+	}
+	if params, ok := match(parts, "agencyroster", "rosters", "*", "assignments"); ok {
+		if s.rosters.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleCollection(w, r, body, &s.rosterAssignments, params[0], "rosterID", "assignments")
+		return true
+	}
+	if params, ok := match(parts, "agencyroster", "rosters", "*", "assignments", "*"); ok {
+		s.handleItem(w, r, body, s.rosterAssignments.findChild(params[0], params[1]), "assignment")
+		return true
+	}
+	return false
+}
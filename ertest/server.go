@@ -0,0 +1,417 @@
+// Package ertest provides an in-process fake of the Emergency Reporting API for tests.
+//
+// The fake keeps its state in memory and implements the endpoints that `emergencyreporting.Client`
+// uses, along with the B2C token endpoint:
+//
+//	server := ertest.NewServer()
+//	defer server.Close()
+//
+//	userID := server.AddUser(emergencyreporting.User{FullName: "Jane Doe"})
+//
+//	client := server.Client()
+//	tokenResponse, err := client.GenerateToken(ctx)
+//	client.Token = tokenResponse.AccessToken
+//	response, err := client.GetUser(ctx, userID)
+//
+// Records get a new row version every time they change, and any request that sends an "ETag"
+// header must match the current row version.
+package ertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// Error shapes.
+const (
+	ErrorShapeStandard = "standard" // Errors look like `emergencyreporting.ErrorResponse`.
+	ErrorShapeBuggy    = "buggy"    // Errors look like `emergencyreporting.ErrorResponseBuggy`.
+)
+
+// Request is a request that the server received.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a fake Emergency Reporting API server.
+type Server struct {
+	*httptest.Server
+
+	// These are the credentials that the token endpoint accepts.
+	Username     string
+	Password     string
+	ClientID     string
+	ClientSecret string
+	AccountID    string
+	UserID       string
+
+	SubscriptionKey string // Every API request must have this subscription key.
+	Token           string // The token endpoint issues this token, and every API request must use it.
+	TenantSegment   string // The tenant segment for the token endpoint.
+
+	ErrorShape string // One of the `ErrorShape*` constants.  If empty, the standard shape is used.
+
+	mutex      sync.Mutex
+	lastID     int
+	rowVersion int
+	requests   []Request
+	failures   []failure
+
+	incidents       collection
+	exposures       collection
+	locations       collection
+	fires           collection
+	apparatuses     collection
+	crewMembers     collection
+	crewMemberRoles collection
+	users           collection
+	contactInfo     collection
+	certifications  collection
+	stations        collection
+	agencyApparatus collection
+
+	hydrants           collection
+	hydrantFlowTests   collection
+	hydrantInspections collection
+
+	occupancies       collection
+	occupancyContacts collection
+	occupancyHazards  collection
+	occupancyPrePlans collection
+
+	fireMarshalInspections   collection
+	fireMarshalViolations    collection
+	fireMarshalReInspections collection
+
+	trainingCategories collection
+	trainingClasses    collection
+	trainingAttendees  collection
+
+	shifts            collection
+	shiftCalendar     collection
+	rosterPositions   collection
+	rosters           collection
+	rosterAssignments collection
+
+	events         collection
+	eventAttendees collection
+	paygrades      collection
+	payrollExports collection
+
+	maintenanceRecords   collection
+	maintenanceSchedules collection
+	inventoryLocations   collection
+	inventoryItems       collection
+
+	patientCareReports collection
+}
+
+// failure is an error that the server has been told to return.
+type failure struct {
+	status    int
+	errorType string
+	message   string
+}
+
+// NewServer starts a new fake server.
+//
+// The caller must call `Close` when finished.
+func NewServer() *Server {
+	s := &Server{
+		Username:        "ertest-username",
+		Password:        "ertest-password",
+		ClientID:        "ertest-client-id",
+		ClientSecret:    "ertest-client-secret",
+		AccountID:       "1",
+		UserID:          "1",
+		SubscriptionKey: "ertest-subscription-key",
+		Token:           "ertest-token",
+		TenantSegment:   "ertest.onmicrosoft.com",
+
+		incidents:       collection{idField: "incidentID"},
+		exposures:       collection{idField: "exposureID"},
+		locations:       collection{idField: "exposureID"},
+		fires:           collection{idField: "exposureID"},
+		apparatuses:     collection{idField: "apparatusID"},
+		crewMembers:     collection{idField: "exposureUserID"},
+		crewMemberRoles: collection{idField: "exposureUserRoleID"},
+		users:           collection{idField: "userID"},
+		contactInfo:     collection{idField: "userID"},
+		certifications:  collection{idField: "certificationID"},
+		stations:        collection{idField: "stationID"},
+		agencyApparatus: collection{idField: "departmentApparatusID"},
+
+		hydrants:           collection{idField: "hydrantID"},
+		hydrantFlowTests:   collection{idField: "flowTestID"},
+		hydrantInspections: collection{idField: "inspectionID"},
+
+		occupancies:       collection{idField: "occupancyID"},
+		occupancyContacts: collection{idField: "occupancyContactID"},
+		occupancyHazards:  collection{idField: "occupancyHazardID"},
+		occupancyPrePlans: collection{idField: "prePlanID"},
+
+		fireMarshalInspections:   collection{idField: "inspectionID"},
+		fireMarshalViolations:    collection{idField: "violationID"},
+		fireMarshalReInspections: collection{idField: "reInspectionID"},
+
+		trainingCategories: collection{idField: "categoryID"},
+		trainingClasses:    collection{idField: "classID"},
+		trainingAttendees:  collection{idField: "attendeeID"},
+
+		shifts:            collection{idField: "shiftID"},
+		shiftCalendar:     collection{idField: "calendarID"},
+		rosterPositions:   collection{idField: "positionID"},
+		rosters:           collection{idField: "rosterID"},
+		rosterAssignments: collection{idField: "assignmentID"},
+
+		events:         collection{idField: "eventID"},
+		eventAttendees: collection{idField: "attendeeID"},
+		paygrades:      collection{idField: "paygradeID"},
+		payrollExports: collection{idField: "exportID"},
+
+		maintenanceRecords:   collection{idField: "recordID"},
+		maintenanceSchedules: collection{idField: "scheduleID"},
+		inventoryLocations:   collection{idField: "locationID"},
+		inventoryItems:       collection{idField: "itemID"},
+
+		patientCareReports: collection{idField: "pcrID"},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client that is set up to talk to this server.
+//
+// The client has no token yet; `GenerateToken` gets one from the fake token endpoint.
+func (s *Server) Client() *emergencyreporting.Client {
+	return &emergencyreporting.Client{
+		Username:        s.Username,
+		Password:        s.Password,
+		ClientID:        s.ClientID,
+		ClientSecret:    s.ClientSecret,
+		AccountID:       s.AccountID,
+		UserID:          s.UserID,
+		TenantHost:      s.URL,
+		TenantSegment:   s.TenantSegment,
+		Host:            s.URL,
+		SubscriptionKey: s.SubscriptionKey,
+		Logger:          emergencyreporting.NullLogger{},
+	}
+}
+
+// Requests returns all of the requests that the server has received, in order.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the next request fail with the given status and error.
+//
+// If the error type is empty, then the response has no error body at all (which is how the
+// client tells a 404 apart from other errors).  Calling this more than once queues up failures.
+func (s *Server) FailNext(status int, errorType string, message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures = append(s.failures, failure{status: status, errorType: errorType, message: message})
+}
+
+// AddIncident adds an incident and returns its ID.
+func (s *Server) AddIncident(incident emergencyreporting.Incident) string {
+	return s.add(&s.incidents, "", incident)
+}
+
+// AddExposure adds an exposure to an incident and returns its ID.
+func (s *Server) AddExposure(incidentID string, exposure emergencyreporting.Exposure) string {
+	exposure.IncidentID = incidentID
+	return s.add(&s.exposures, incidentID, exposure)
+}
+
+// SetExposureLocation sets the location of an exposure.
+func (s *Server) SetExposureLocation(exposureID string, location emergencyreporting.ExposureLocation) {
+	location.ExposureID = exposureID
+	s.set(&s.locations, exposureID, location)
+}
+
+// SetExposureFire sets the fire module of an exposure.
+func (s *Server) SetExposureFire(exposureID string, fire emergencyreporting.ExposureFire) {
+	fire.ExposureID = exposureID
+	s.set(&s.fires, exposureID, fire)
+}
+
+// AddExposureApparatus adds an apparatus to an exposure and returns its ID.
+func (s *Server) AddExposureApparatus(exposureID string, apparatus emergencyreporting.ExposureApparatus) string {
+	apparatus.ExposureID = exposureID
+	return s.add(&s.apparatuses, exposureID, apparatus)
+}
+
+// AddCrewMember adds a crew member to an exposure and returns its exposure user ID.
+func (s *Server) AddCrewMember(exposureID string, member emergencyreporting.CrewMember) string {
+	member.ExposureID = exposureID
+	return s.add(&s.crewMembers, exposureID, member)
+}
+
+// AddCrewMemberRole adds a role to a crew member and returns its ID.
+func (s *Server) AddCrewMemberRole(exposureUserID string, role emergencyreporting.CrewMemberRole) string {
+	return s.add(&s.crewMemberRoles, exposureUserID, role)
+}
+
+// AddUser adds a user and returns its ID.
+//
+// Any licenses without an ID are given one.
+func (s *Server) AddUser(user emergencyreporting.User) string {
+	s.mutex.Lock()
+	var licenses []*emergencyreporting.UserLicense
+	for _, license := range user.Licenses {
+		if license == nil {
+			continue
+		}
+		copied := *license
+		if copied.LicenseID == "" {
+			copied.LicenseID = s.nextID()
+		}
+		copied.RowVersion = s.nextRowVersion()
+		licenses = append(licenses, &copied)
+	}
+	user.Licenses = licenses
+	s.mutex.Unlock()
+
+	return s.add(&s.users, "", user)
+}
+
+// SetUserContactInfo sets the contact info for a user.
+func (s *Server) SetUserContactInfo(userID string, contactInfo emergencyreporting.UserContactInfo) {
+	s.set(&s.contactInfo, userID, contactInfo)
+}
+
+// AddStation adds a station and returns its ID.
+func (s *Server) AddStation(station emergencyreporting.Station) string {
+	return s.add(&s.stations, "", station)
+}
+
+// AddApparatus adds an agency apparatus and returns its department apparatus ID.
+func (s *Server) AddApparatus(apparatus emergencyreporting.Apparatus) string {
+	return s.add(&s.agencyApparatus, "", apparatus)
+}
+
+// Incident returns the current state of an incident, or nil if it does not exist.
+func (s *Server) Incident(incidentID string) *emergencyreporting.Incident {
+	var incident *emergencyreporting.Incident
+	if !s.get(&s.incidents, incidentID, &incident) {
+		return nil
+	}
+	return incident
+}
+
+// Exposure returns the current state of an exposure, or nil if it does not exist.
+func (s *Server) Exposure(exposureID string) *emergencyreporting.Exposure {
+	var exposure *emergencyreporting.Exposure
+	if !s.get(&s.exposures, exposureID, &exposure) {
+		return nil
+	}
+	return exposure
+}
+
+// User returns the current state of a user, or nil if it does not exist.
+func (s *Server) User(userID string) *emergencyreporting.User {
+	var user *emergencyreporting.User
+	if !s.get(&s.users, userID, &user) {
+		return nil
+	}
+	return user
+}
+
+// UserContactInfo returns the current contact info for a user, or nil if it does not exist.
+func (s *Server) UserContactInfo(userID string) *emergencyreporting.UserContactInfo {
+	var contactInfo *emergencyreporting.UserContactInfo
+	if !s.get(&s.contactInfo, userID, &contactInfo) {
+		return nil
+	}
+	return contactInfo
+}
+
+// add adds the value to the collection, assigning it an ID and a row version.
+func (s *Server) add(c *collection, parent string, value interface{}) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fields := toFields(value)
+	id, _ := fields[c.idField].(string)
+	if id == "" {
+		id = s.nextID()
+		fields[c.idField] = id
+	}
+	fields["rowVersion"] = s.nextRowVersion()
+	c.insert(&record{parent: parent, fields: fields})
+	return id
+}
+
+// set replaces the value in the collection that has the given ID.
+func (s *Server) set(c *collection, id string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fields := toFields(value)
+	fields[c.idField] = id
+	fields["rowVersion"] = s.nextRowVersion()
+	if existing := c.find(id); existing != nil {
+		existing.fields = fields
+		return
+	}
+	c.insert(&record{parent: id, fields: fields})
+}
+
+// get converts the record with the given ID into the target.
+func (s *Server) get(c *collection, id string, target interface{}) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing := c.find(id)
+	if existing == nil {
+		return false
+	}
+	contents, _ := json.Marshal(existing.fields)
+	return json.Unmarshal(contents, target) == nil
+}
+
+// nextID returns a new, unique ID.
+//
+// The caller must hold the mutex.
+func (s *Server) nextID() string {
+	s.lastID++
+	return strconv.Itoa(s.lastID)
+}
+
+// nextRowVersion returns a new, unique row version.
+//
+// The caller must hold the mutex.
+func (s *Server) nextRowVersion() string {
+	s.rowVersion++
+	return fmt.Sprintf("rv%08d", s.rowVersion)
+}
+
+// toFields converts a value into its JSON fields.
+func toFields(value interface{}) map[string]interface{} {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(contents, &fields)
+	if err != nil {
+		panic(err)
+	}
+	return fields
+}
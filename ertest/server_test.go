@@ -0,0 +1,214 @@
+package ertest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/emergencyreporting"
+	"github.com/tekkamanendless/emergencyreporting/ertest"
+)
+
+// newClient returns a client for the server that has a token.
+func newClient(t *testing.T, server *ertest.Server) *emergencyreporting.Client {
+	client := server.Client()
+	tokenResponse, err := client.GenerateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Could not generate a token: %v", err)
+	}
+	client.Token = tokenResponse.AccessToken
+	return client
+}
+
+func TestRowVersion(t *testing.T) {
+	ctx := context.Background()
+	server := ertest.NewServer()
+	defer server.Close()
+
+	incidentID := server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2021-0001"})
+	client := newClient(t, server)
+
+	incidentResponse, err := client.GetIncident(ctx, incidentID)
+	if err != nil {
+		t.Fatalf("Could not get the incident: %v", err)
+	}
+	rowVersion := incidentResponse.Incident.RowVersion
+	if rowVersion == "" {
+		t.Fatalf("The incident has no row version")
+	}
+
+	stationID := "2"
+	patchResponse, err := client.PatchIncident(ctx, incidentID, rowVersion, emergencyreporting.PatchIncidentRequest{StationID: &stationID})
+	if err != nil {
+		t.Fatalf("Could not patch the incident: %v", err)
+	}
+	if patchResponse.RowVersion == rowVersion {
+		t.Errorf("The row version did not change: %s", rowVersion)
+	}
+	if server.Incident(incidentID).StationID != "2" {
+		t.Errorf("The station was not updated")
+	}
+
+	// The old row version is now stale.
+	_, err = client.PatchIncident(ctx, incidentID, rowVersion, emergencyreporting.PatchIncidentRequest{StationID: &stationID})
+	if err == nil || !strings.Contains(err.Error(), "RowVersionMismatch") {
+		t.Errorf("Expected a row version mismatch; got: %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	server := ertest.NewServer()
+	defer server.Close()
+
+	for _, incident := range []emergencyreporting.Incident{
+		{IncidentNumber: "2021-0001", StationID: "1"},
+		{IncidentNumber: "2021-0002", StationID: "2"},
+		{IncidentNumber: "2021-0003", StationID: "1"},
+		{IncidentNumber: "O'Brien", StationID: "1"},
+	} {
+		server.AddIncident(incident)
+	}
+	client := newClient(t, server)
+
+	rows := []struct {
+		description string
+		options     map[string]string
+		expected    []string
+		err         bool
+	}{
+		{
+			description: "Everything",
+			expected:    []string{"2021-0001", "2021-0002", "2021-0003", "O'Brien"},
+		},
+		{
+			description: "Filter",
+			options:     map[string]string{"filter": "stationID eq '1' and incidentNumber ne '2021-0003'"},
+			expected:    []string{"2021-0001", "O'Brien"},
+		},
+		{
+			description: "Quoted quote",
+			options:     map[string]string{"filter": "incidentNumber eq 'O''Brien'"},
+			expected:    []string{"O'Brien"},
+		},
+		{
+			description: "Limit and offset",
+			options:     map[string]string{"offset": "1", "limit": "2"},
+			expected:    []string{"2021-0002", "2021-0003"},
+		},
+		{
+			description: "Offset past the end",
+			options:     map[string]string{"offset": "10"},
+		},
+		{
+			description: "Order",
+			options:     map[string]string{"orderby": "stationID desc,incidentNumber", "limit": "2"},
+			expected:    []string{"2021-0002", "2021-0001"},
+		},
+		{
+			description: "Bad filter",
+			options:     map[string]string{"filter": "stationID is '1'"},
+			err:         true,
+		},
+		{
+			description: "Bad limit",
+			options:     map[string]string{"limit": "-1"},
+			err:         true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			response, err := client.GetIncidents(ctx, row.options)
+			if row.err {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var numbers []string
+			for _, incident := range response.Incidents {
+				numbers = append(numbers, incident.IncidentNumber)
+			}
+			if strings.Join(numbers, ",") != strings.Join(row.expected, ",") {
+				t.Errorf("Expected %v; got %v", row.expected, numbers)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	server := ertest.NewServer()
+	defer server.Close()
+
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		server.AddEvent(emergencyreporting.Event{EventName: name})
+	}
+	client := newClient(t, server)
+
+	events, err := client.GetAllEvents(ctx, map[string]string{"limit": "2"})
+	if err != nil {
+		t.Fatalf("Could not get the events: %v", err)
+	}
+	if len(events) != 5 {
+		t.Errorf("Expected 5 events; got %d", len(events))
+	}
+
+	var pages int
+	for _, request := range server.Requests() {
+		if request.Path == "/agencyevents/events" {
+			pages++
+		}
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages; got %d", pages)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	for _, errorShape := range []string{ertest.ErrorShapeStandard, ertest.ErrorShapeBuggy} {
+		t.Run(errorShape, func(t *testing.T) {
+			server := ertest.NewServer()
+			defer server.Close()
+			server.ErrorShape = errorShape
+
+			server.AddIncident(emergencyreporting.Incident{IncidentNumber: "2021-0001"})
+			client := newClient(t, server)
+
+			t.Run("Duplicate", func(t *testing.T) {
+				_, err := client.PostIncident(ctx, emergencyreporting.Incident{IncidentNumber: "2021-0001"})
+				if !errors.Is(err, emergencyreporting.ErrorDuplicate) {
+					t.Errorf("Expected a duplicate error; got: %v", err)
+				}
+			})
+
+			t.Run("Not found", func(t *testing.T) {
+				_, err := client.GetIncident(ctx, "999")
+				if !errors.Is(err, emergencyreporting.ErrorNotFound) {
+					t.Errorf("Expected a not-found error; got: %v", err)
+				}
+			})
+
+			t.Run("Other", func(t *testing.T) {
+				server.FailNext(http.StatusInternalServerError, "Boom", "Something went wrong.")
+				_, err := client.GetIncidents(ctx, nil)
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				if errors.Is(err, emergencyreporting.ErrorDuplicate) || errors.Is(err, emergencyreporting.ErrorNotFound) {
+					t.Errorf("Wrong error: %v", err)
+				}
+				if !strings.Contains(err.Error(), "Boom") || !strings.Contains(err.Error(), "Something went wrong.") {
+					t.Errorf("The error does not have the type and message: %v", err)
+				}
+			})
+		})
+	}
+}
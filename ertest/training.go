@@ -0,0 +1,105 @@
+package ertest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tekkamanendless/emergencyreporting"
+)
+
+// AddTrainingCategory adds a training category and returns its ID.
+func (s *Server) AddTrainingCategory(category emergencyreporting.TrainingCategory) string {
+	return s.add(&s.trainingCategories, "", category)
+}
+
+// AddTrainingClass adds a training class and returns its ID.
+func (s *Server) AddTrainingClass(class emergencyreporting.TrainingClass) string {
+	return s.add(&s.trainingClasses, "", class)
+}
+
+// AddTrainingAttendee adds an attendee to a training class and returns its ID.
+func (s *Server) AddTrainingAttendee(classID string, attendee emergencyreporting.TrainingAttendee) string {
+	attendee.ClassID = classID
+	return s.add(&s.trainingAttendees, classID, attendee)
+}
+
+// TrainingAttendees returns the current attendees of a training class.
+func (s *Server) TrainingAttendees(classID string) []*emergencyreporting.TrainingAttendee {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var fields []map[string]interface{}
+	for _, r := range s.trainingAttendees.children(classID) {
+		fields = append(fields, r.fields)
+	}
+	contents, _ := json.Marshal(fields)
+	var attendees []*emergencyreporting.TrainingAttendee
+	_ = json.Unmarshal(contents, &attendees)
+	return attendees
+}
+
+// serveTraining handles the training endpoints, returning false if the path is not one of them.
+//
+// The caller must hold the mutex.
+func (s *Server) serveTraining(w http.ResponseWriter, r *http.Request, parts []string, body []byte) bool {
+	if _, ok := match(parts, "agencytraining", "categories"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.trainingCategories.children(""), wrap("categories"))
+		return true
+	}
+	if _, ok := match(parts, "agencytraining", "classes"); ok {
+		s.handleCollection(w, r, body, &s.trainingClasses, "", "", "classes")
+		return true
+	}
+	if params, ok := match(parts, "agencytraining", "classes", "*"); ok {
+		s.handleItem(w, r, body, s.trainingClasses.find(params[0]), "class")
+		return true
+	}
+	if params, ok := match(parts, "agencytraining", "classes", "*", "attendees"); ok {
+		if s.trainingClasses.find(params[0]) == nil {
+			s.writeNotFound(w)
+			return true
+		}
+		s.handleCollection(w, r, body, &s.trainingAttendees, params[0], "classID", "attendees")
+		return true
+	}
+	if _, ok := match(parts, "agencytraining", "hours"); ok && r.Method == http.MethodGet {
+		s.handleList(w, r, s.trainingHours(), wrap("hours"))
+		return true
+	}
+	return false
+}
+
+// trainingHours builds the training hours from the classes and their attendees.
+//
+// The caller must hold the mutex.
+func (s *Server) trainingHours() []*record {
+	categoryNames := map[string]string{}
+	for _, category := range s.trainingCategories.records {
+		categoryNames[fieldString(category.fields["categoryID"])] = fieldString(category.fields["categoryName"])
+	}
+
+	var records []*record
+	for _, attendee := range s.trainingAttendees.records {
+		class := s.trainingClasses.find(attendee.parent)
+		if class == nil {
+			continue
+		}
+		hours := fieldString(attendee.fields["hours"])
+		if hours == "" {
+			hours = fieldString(class.fields["hours"])
+		}
+		categoryID := fieldString(class.fields["categoryID"])
+		records = append(records, &record{
+			parent: fieldString(attendee.fields["userID"]),
+			fields: map[string]interface{}{
+				"userID":           fieldString(attendee.fields["userID"]),
+				"classID":          attendee.parent,
+				"categoryID":       categoryID,
+				"categoryName":     categoryNames[categoryID],
+				"trainingDateTime": fieldString(class.fields["startDateTime"]),
+				"hours":            hours,
+			},
+		})
+	}
+	return records
+}
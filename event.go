@@ -0,0 +1,294 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Event attendance statuses.
+const (
+	EventAttendeeStatusAttended = "Attended"
+	EventAttendeeStatusExcused  = "Excused"
+	EventAttendeeStatusAbsent   = "Absent"
+)
+
+type Event struct {
+	EventID       string `json:"eventID,omitempty"` // Not used for creating events.
+	EventName     string `json:"eventName"`
+	EventType     string `json:"eventType"` // Such as "Meeting", "Standby", or "Fundraiser".
+	StartDateTime string `json:"startDateTime"`
+	EndDateTime   string `json:"endDateTime"`
+	Hours         string `json:"hours"` // The hours that each attendee gets by default; if empty, then the length of the event.
+	StationID     string `json:"stationID"`
+	Location      string `json:"location"`
+	Description   string `json:"description"`
+	IsPaid        string `json:"isPaid"`
+	RowVersion    string `json:"rowVersion,omitempty"` // Not used for creating events.
+}
+
+type GetEventsResponse struct {
+	TotalRows string   `json:"totalRows"`
+	Events    []*Event `json:"events"`
+}
+
+type GetEventResponse struct {
+	Event *Event `json:"event"`
+}
+
+type PostEventResponse struct {
+	EventID string `json:"eventID"`
+}
+
+type PatchEventRequest struct {
+	EventName     *string `json:"eventName,omitempty"`
+	EventType     *string `json:"eventType,omitempty"`
+	StartDateTime *string `json:"startDateTime,omitempty"`
+	EndDateTime   *string `json:"endDateTime,omitempty"`
+	Hours         *string `json:"hours,omitempty"`
+	StationID     *string `json:"stationID,omitempty"`
+	Location      *string `json:"location,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	IsPaid        *string `json:"isPaid,omitempty"`
+}
+
+type PatchEventResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// EventAttendee is someone's attendance at an event.
+//
+// The hours and pay grade are only set when they differ from the event's and the user's defaults.
+type EventAttendee struct {
+	AttendeeID   string `json:"attendeeID,omitempty"` // Not used for adding attendees.
+	EventID      string `json:"eventID,omitempty"`
+	UserID       string `json:"userID"`
+	Hours        string `json:"hours"`
+	PaygradeName string `json:"paygradeName"`
+	PaygradeRate string `json:"paygradeRate"`
+	Status       string `json:"status"` // One of the `EventAttendeeStatus*` constants.
+	Notes        string `json:"notes"`
+	RowVersion   string `json:"rowVersion,omitempty"`
+}
+
+type GetEventAttendeesResponse struct {
+	Attendees []*EventAttendee `json:"attendees"`
+}
+
+type PostEventAttendeeResponse struct {
+	AttendeeID string `json:"attendeeID"`
+}
+
+type PatchEventAttendeeRequest struct {
+	Hours        *string `json:"hours,omitempty"`
+	PaygradeName *string `json:"paygradeName,omitempty"`
+	PaygradeRate *string `json:"paygradeRate,omitempty"`
+	Status       *string `json:"status,omitempty"`
+	Notes        *string `json:"notes,omitempty"`
+}
+
+type PatchEventAttendeeResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetEvents gets a page of events.
+func (c *Client) GetEvents(ctx context.Context, options map[string]string) (*GetEventsResponse, error) {
+	// https://data.emergencyreporting.com/agencyevents/events[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyevents/events"
+
+	var parsedResponse GetEventsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the events: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllEvents gets every page of events.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllEvents(ctx context.Context, options map[string]string) ([]*Event, error) {
+	var events []*Event
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetEvents(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		events = append(events, response.Events...)
+		return len(response.Events), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetEvent gets an event.
+func (c *Client) GetEvent(ctx context.Context, eventID string) (*GetEventResponse, error) {
+	// https://data.emergencyreporting.com/agencyevents/events/{eventID}
+
+	targetURL := "/agencyevents/events/" + url.PathEscape(eventID)
+
+	var parsedResponse GetEventResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the event: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostEvent creates an event.
+func (c *Client) PostEvent(ctx context.Context, event Event) (*PostEventResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyevents/events
+
+	targetURL := "/agencyevents/events"
+
+	jsonInput, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostEventResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the event: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchEvent updates an event.
+func (c *Client) PatchEvent(ctx context.Context, eventID string, rowVersion string, payload PatchEventRequest) (*PatchEventResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyevents/events/{eventID}
+
+	targetURL := "/agencyevents/events/" + url.PathEscape(eventID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchEventResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the event: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetEventAttendees gets the attendance for an event.
+func (c *Client) GetEventAttendees(ctx context.Context, eventID string, options map[string]string) (*GetEventAttendeesResponse, error) {
+	// https://data.emergencyreporting.com/agencyevents/events/{eventID}/attendees[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyevents/events/" + url.PathEscape(eventID) + "/attendees"
+
+	var parsedResponse GetEventAttendeesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the event attendees: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllEventAttendees gets every page of the attendance for an event.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllEventAttendees(ctx context.Context, eventID string, options map[string]string) ([]*EventAttendee, error) {
+	var attendees []*EventAttendee
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetEventAttendees(ctx, eventID, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		attendees = append(attendees, response.Attendees...)
+		return len(response.Attendees), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+// PostEventAttendee adds an attendee to an event.
+func (c *Client) PostEventAttendee(ctx context.Context, eventID string, attendee EventAttendee) (*PostEventAttendeeResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyevents/events/{eventID}/attendees
+
+	targetURL := "/agencyevents/events/" + url.PathEscape(eventID) + "/attendees"
+
+	jsonInput, err := json.Marshal(attendee)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostEventAttendeeResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the event attendee: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchEventAttendee updates an attendee of an event.
+func (c *Client) PatchEventAttendee(ctx context.Context, eventID string, attendeeID string, rowVersion string, payload PatchEventAttendeeRequest) (*PatchEventAttendeeResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyevents/events/{eventID}/attendees/{attendeeID}
+
+	targetURL := "/agencyevents/events/" + url.PathEscape(eventID) + "/attendees/" + url.PathEscape(attendeeID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchEventAttendeeResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the event attendee: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
@@ -0,0 +1,538 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+type FireMarshalInspection struct {
+	InspectionID            string `json:"inspectionID,omitempty"` // Not used for creating inspections.
+	OccupancyID             string `json:"occupancyID"`
+	InspectionType          string `json:"inspectionType"` // Such as "Annual", "Complaint", or "Permit".
+	InspectionDateTime      string `json:"inspectionDateTime"`
+	InspectorUserID         string `json:"inspectorUserID"`
+	Status                  string `json:"status"`               // Such as "Scheduled", "In Progress", or "Complete".
+	Passed                  string `json:"passed"`               // "1" if the occupancy passed.
+	ReInspectionRequired    string `json:"reInspectionRequired"` // "1" if the violations need a re-inspection.
+	ReInspectionDueDateTime string `json:"reInspectionDueDateTime"`
+	CompletedDateTime       string `json:"completedDateTime"`
+	Notes                   string `json:"notes"`
+	RowVersion              string `json:"rowVersion,omitempty"` // Not used for creating inspections.
+}
+
+type GetFireMarshalInspectionsResponse struct {
+	TotalRows   string                   `json:"totalRows"`
+	Inspections []*FireMarshalInspection `json:"inspections"`
+}
+
+type GetFireMarshalInspectionResponse struct {
+	Inspection *FireMarshalInspection `json:"inspection"`
+}
+
+type PostFireMarshalInspectionResponse struct {
+	InspectionID string `json:"inspectionID"`
+}
+
+type PatchFireMarshalInspectionRequest struct {
+	OccupancyID             *string `json:"occupancyID,omitempty"`
+	InspectionType          *string `json:"inspectionType,omitempty"`
+	InspectionDateTime      *string `json:"inspectionDateTime,omitempty"`
+	InspectorUserID         *string `json:"inspectorUserID,omitempty"`
+	Status                  *string `json:"status,omitempty"`
+	Passed                  *string `json:"passed,omitempty"`
+	ReInspectionRequired    *string `json:"reInspectionRequired,omitempty"`
+	ReInspectionDueDateTime *string `json:"reInspectionDueDateTime,omitempty"`
+	CompletedDateTime       *string `json:"completedDateTime,omitempty"`
+	Notes                   *string `json:"notes,omitempty"`
+}
+
+type PatchFireMarshalInspectionResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type FireMarshalViolation struct {
+	ViolationID       string `json:"violationID,omitempty"` // Not used for creating violations.
+	InspectionID      string `json:"inspectionID,omitempty"`
+	CodeReference     string `json:"codeReference"` // The fire code section, such as "IFC 906.1".
+	Description       string `json:"description"`
+	Severity          string `json:"severity"`
+	Status            string `json:"status"` // Such as "Open" or "Corrected".
+	CorrectByDateTime string `json:"correctByDateTime"`
+	CorrectedDateTime string `json:"correctedDateTime"`
+	Notes             string `json:"notes"`
+	RowVersion        string `json:"rowVersion,omitempty"`
+}
+
+type GetFireMarshalViolationsResponse struct {
+	Violations []*FireMarshalViolation `json:"violations"`
+}
+
+type GetFireMarshalViolationResponse struct {
+	Violation *FireMarshalViolation `json:"violation"`
+}
+
+type PostFireMarshalViolationResponse struct {
+	ViolationID string `json:"violationID"`
+}
+
+type PatchFireMarshalViolationRequest struct {
+	CodeReference     *string `json:"codeReference,omitempty"`
+	Description       *string `json:"description,omitempty"`
+	Severity          *string `json:"severity,omitempty"`
+	Status            *string `json:"status,omitempty"`
+	CorrectByDateTime *string `json:"correctByDateTime,omitempty"`
+	CorrectedDateTime *string `json:"correctedDateTime,omitempty"`
+	Notes             *string `json:"notes,omitempty"`
+}
+
+type PatchFireMarshalViolationResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type FireMarshalReInspection struct {
+	ReInspectionID    string `json:"reInspectionID,omitempty"` // Not used for creating re-inspections.
+	InspectionID      string `json:"inspectionID,omitempty"`
+	ScheduledDateTime string `json:"scheduledDateTime"`
+	InspectorUserID   string `json:"inspectorUserID"`
+	CompletedDateTime string `json:"completedDateTime"` // Empty until the re-inspection is done.
+	Passed            string `json:"passed"`            // "1" if the violations were corrected.
+	Notes             string `json:"notes"`
+	RowVersion        string `json:"rowVersion,omitempty"`
+}
+
+type GetFireMarshalReInspectionsResponse struct {
+	ReInspections []*FireMarshalReInspection `json:"reInspections"`
+}
+
+type GetFireMarshalReInspectionResponse struct {
+	ReInspection *FireMarshalReInspection `json:"reInspection"`
+}
+
+type PostFireMarshalReInspectionResponse struct {
+	ReInspectionID string `json:"reInspectionID"`
+}
+
+type PatchFireMarshalReInspectionRequest struct {
+	ScheduledDateTime *string `json:"scheduledDateTime,omitempty"`
+	InspectorUserID   *string `json:"inspectorUserID,omitempty"`
+	CompletedDateTime *string `json:"completedDateTime,omitempty"`
+	Passed            *string `json:"passed,omitempty"`
+	Notes             *string `json:"notes,omitempty"`
+}
+
+type PatchFireMarshalReInspectionResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetFireMarshalInspections gets a page of fire marshal inspections.
+func (c *Client) GetFireMarshalInspections(ctx context.Context, options map[string]string) (*GetFireMarshalInspectionsResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyfiremarshal/inspections"
+
+	var parsedResponse GetFireMarshalInspectionsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal inspections: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllFireMarshalInspections gets every page of fire marshal inspections.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllFireMarshalInspections(ctx context.Context, options map[string]string) ([]*FireMarshalInspection, error) {
+	var inspections []*FireMarshalInspection
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetFireMarshalInspections(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		inspections = append(inspections, response.Inspections...)
+		return len(response.Inspections), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
+
+// GetFireMarshalInspection gets a fire marshal inspection.
+func (c *Client) GetFireMarshalInspection(ctx context.Context, inspectionID string) (*GetFireMarshalInspectionResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID)
+
+	var parsedResponse GetFireMarshalInspectionResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostFireMarshalInspection creates a fire marshal inspection.
+func (c *Client) PostFireMarshalInspection(ctx context.Context, inspection FireMarshalInspection) (*PostFireMarshalInspectionResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections
+
+	targetURL := "/agencyfiremarshal/inspections"
+
+	jsonInput, err := json.Marshal(inspection)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostFireMarshalInspectionResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the fire marshal inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchFireMarshalInspection updates a fire marshal inspection.
+func (c *Client) PatchFireMarshalInspection(ctx context.Context, inspectionID string, rowVersion string, payload PatchFireMarshalInspectionRequest) (*PatchFireMarshalInspectionResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchFireMarshalInspectionResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the fire marshal inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetFireMarshalViolations gets the violations for a fire marshal inspection.
+func (c *Client) GetFireMarshalViolations(ctx context.Context, inspectionID string, options map[string]string) (*GetFireMarshalViolationsResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/violations[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/violations"
+
+	var parsedResponse GetFireMarshalViolationsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal violations: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetFireMarshalViolation gets a fire marshal violation.
+func (c *Client) GetFireMarshalViolation(ctx context.Context, inspectionID string, violationID string) (*GetFireMarshalViolationResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/violations/{violationID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/violations/" + url.PathEscape(violationID)
+
+	var parsedResponse GetFireMarshalViolationResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal violation: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostFireMarshalViolation adds a violation to a fire marshal inspection.
+func (c *Client) PostFireMarshalViolation(ctx context.Context, inspectionID string, violation FireMarshalViolation) (*PostFireMarshalViolationResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/violations
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/violations"
+
+	jsonInput, err := json.Marshal(violation)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostFireMarshalViolationResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the fire marshal violation: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchFireMarshalViolation updates a fire marshal violation, such as to mark it corrected.
+func (c *Client) PatchFireMarshalViolation(ctx context.Context, inspectionID string, violationID string, rowVersion string, payload PatchFireMarshalViolationRequest) (*PatchFireMarshalViolationResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/violations/{violationID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/violations/" + url.PathEscape(violationID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchFireMarshalViolationResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the fire marshal violation: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetFireMarshalReInspections gets the re-inspections for a fire marshal inspection.
+func (c *Client) GetFireMarshalReInspections(ctx context.Context, inspectionID string, options map[string]string) (*GetFireMarshalReInspectionsResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/reinspections[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/reinspections"
+
+	var parsedResponse GetFireMarshalReInspectionsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal re-inspections: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetFireMarshalReInspection gets a fire marshal re-inspection.
+func (c *Client) GetFireMarshalReInspection(ctx context.Context, inspectionID string, reInspectionID string) (*GetFireMarshalReInspectionResponse, error) {
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/reinspections/{reInspectionID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/reinspections/" + url.PathEscape(reInspectionID)
+
+	var parsedResponse GetFireMarshalReInspectionResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the fire marshal re-inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostFireMarshalReInspection schedules a re-inspection for a fire marshal inspection.
+func (c *Client) PostFireMarshalReInspection(ctx context.Context, inspectionID string, reInspection FireMarshalReInspection) (*PostFireMarshalReInspectionResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/reinspections
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/reinspections"
+
+	jsonInput, err := json.Marshal(reInspection)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostFireMarshalReInspectionResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the fire marshal re-inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchFireMarshalReInspection updates a fire marshal re-inspection, such as to record its result.
+func (c *Client) PatchFireMarshalReInspection(ctx context.Context, inspectionID string, reInspectionID string, rowVersion string, payload PatchFireMarshalReInspectionRequest) (*PatchFireMarshalReInspectionResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyfiremarshal/inspections/{inspectionID}/reinspections/{reInspectionID}
+
+	targetURL := "/agencyfiremarshal/inspections/" + url.PathEscape(inspectionID) + "/reinspections/" + url.PathEscape(reInspectionID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchFireMarshalReInspectionResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the fire marshal re-inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// OverdueReInspection is an inspection whose re-inspection is past due.
+type OverdueReInspection struct {
+	OccupancyID    string `json:"occupancyID"`
+	OccupancyName  string `json:"occupancyName"`
+	InspectionID   string `json:"inspectionID"`
+	ReInspectionID string `json:"reInspectionID,omitempty"` // The pending re-inspection, if one was scheduled.
+	DueDateTime    string `json:"dueDateTime"`
+	DaysOverdue    int    `json:"daysOverdue"` // Any part of a day counts as a whole one.
+}
+
+// OverdueReInspections returns the inspections that needed a re-inspection before the given time but
+// don't have a passing one, sorted by occupancy and then by how overdue they are.
+//
+// The re-inspections are matched to the inspections by `InspectionID`, and the occupancies (which may be
+// nil) are only used for their names.  An inspection is due when its latest pending re-inspection is
+// scheduled or, if none is, at its `ReInspectionDueDateTime`.  Date/times without a time zone are in the
+// location of `asOf`.
+func OverdueReInspections(inspections []*FireMarshalInspection, reInspections []*FireMarshalReInspection, occupancies []*Occupancy, asOf time.Time) []*OverdueReInspection {
+	occupancyNames := map[string]string{}
+	for _, occupancy := range occupancies {
+		if occupancy != nil {
+			occupancyNames[occupancy.OccupancyID] = occupancy.OccupancyName
+		}
+	}
+	reInspectionsByInspection := map[string][]*FireMarshalReInspection{}
+	for _, reInspection := range reInspections {
+		if reInspection != nil {
+			reInspectionsByInspection[reInspection.InspectionID] = append(reInspectionsByInspection[reInspection.InspectionID], reInspection)
+		}
+	}
+
+	results := []*OverdueReInspection{}
+	for _, inspection := range inspections {
+		if inspection == nil || inspection.ReInspectionRequired != "1" {
+			continue
+		}
+
+		result := &OverdueReInspection{
+			OccupancyID:   inspection.OccupancyID,
+			OccupancyName: occupancyNames[inspection.OccupancyID],
+			InspectionID:  inspection.InspectionID,
+			DueDateTime:   inspection.ReInspectionDueDateTime,
+		}
+		due, ok := parseDateTime(inspection.ReInspectionDueDateTime, asOf.Location())
+
+		passed := false
+		for _, reInspection := range reInspectionsByInspection[inspection.InspectionID] {
+			if reInspection.CompletedDateTime != "" {
+				if reInspection.Passed == "1" {
+					passed = true
+				}
+				continue
+			}
+			scheduled, scheduledOK := parseDateTime(reInspection.ScheduledDateTime, asOf.Location())
+			if !scheduledOK {
+				continue
+			}
+			if result.ReInspectionID == "" || scheduled.After(due) {
+				result.ReInspectionID = reInspection.ReInspectionID
+				result.DueDateTime = reInspection.ScheduledDateTime
+				due, ok = scheduled, true
+			}
+		}
+		if passed || !ok || !due.Before(asOf) {
+			continue
+		}
+
+		result.DaysOverdue = -daysRemaining(due.Sub(asOf))
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].OccupancyName != results[j].OccupancyName {
+			// Occupancies without a name go last.
+			if results[i].OccupancyName == "" || results[j].OccupancyName == "" {
+				return results[j].OccupancyName == ""
+			}
+			return results[i].OccupancyName < results[j].OccupancyName
+		}
+		if results[i].OccupancyID != results[j].OccupancyID {
+			return results[i].OccupancyID < results[j].OccupancyID
+		}
+		return results[i].DaysOverdue > results[j].DaysOverdue
+	})
+	return results
+}
+
+// GetOverdueReInspections gets the inspections that need a re-inspection (along with their re-inspections
+// and the occupancies) and returns the overdue ones.
+//
+// See `OverdueReInspections`.
+func (c *Client) GetOverdueReInspections(ctx context.Context, asOf time.Time) ([]*OverdueReInspection, error) {
+	inspections, err := c.GetAllFireMarshalInspections(ctx, map[string]string{"filter": FilterEquals("reInspectionRequired", "1")})
+	if err != nil {
+		return nil, err
+	}
+
+	var reInspections []*FireMarshalReInspection
+	for _, inspection := range inspections {
+		if inspection.ReInspectionRequired != "1" {
+			continue
+		}
+		response, err := c.GetFireMarshalReInspections(ctx, inspection.InspectionID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, reInspection := range response.ReInspections {
+			if reInspection.InspectionID == "" {
+				reInspection.InspectionID = inspection.InspectionID
+			}
+			reInspections = append(reInspections, reInspection)
+		}
+	}
+
+	occupancies, err := c.GetAllOccupancies(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return OverdueReInspections(inspections, reInspections, occupancies, asOf), nil
+}
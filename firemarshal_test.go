@@ -0,0 +1,110 @@
+package emergencyreporting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverdueReInspections(t *testing.T) {
+	asOf := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	occupancies := []*Occupancy{
+		{OccupancyID: "1", OccupancyName: "Acme"},
+		{OccupancyID: "2", OccupancyName: "Bolt"},
+		nil,
+	}
+
+	rows := []struct {
+		description   string
+		inspections   []*FireMarshalInspection
+		reInspections []*FireMarshalReInspection
+		expected      []OverdueReInspection
+	}{
+		{
+			description: "Nothing",
+			expected:    []OverdueReInspection{},
+		},
+		{
+			description: "Not required",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "1", ReInspectionRequired: "0", ReInspectionDueDateTime: "2026-01-01"},
+			},
+			expected: []OverdueReInspection{},
+		},
+		{
+			description: "Past the due date",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-03-01"},
+				{InspectionID: "11", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-04-01"},
+			},
+			expected: []OverdueReInspection{
+				{OccupancyID: "1", OccupancyName: "Acme", InspectionID: "10", DueDateTime: "2026-03-01", DaysOverdue: 15},
+			},
+		},
+		{
+			description: "Passed",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-03-01"},
+			},
+			reInspections: []*FireMarshalReInspection{
+				{ReInspectionID: "20", InspectionID: "10", ScheduledDateTime: "2026-02-01", CompletedDateTime: "2026-02-01", Passed: "1"},
+			},
+			expected: []OverdueReInspection{},
+		},
+		{
+			description: "Failed",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-03-01"},
+			},
+			reInspections: []*FireMarshalReInspection{
+				{ReInspectionID: "20", InspectionID: "10", ScheduledDateTime: "2026-02-01", CompletedDateTime: "2026-02-01", Passed: "0"},
+			},
+			expected: []OverdueReInspection{
+				{OccupancyID: "1", OccupancyName: "Acme", InspectionID: "10", DueDateTime: "2026-03-01", DaysOverdue: 15},
+			},
+		},
+		{
+			description: "Rescheduled",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-01-01"},
+				{InspectionID: "11", OccupancyID: "2", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-01-01"},
+			},
+			reInspections: []*FireMarshalReInspection{
+				{ReInspectionID: "20", InspectionID: "10", ScheduledDateTime: "2026-02-01 09:00:00"},
+				{ReInspectionID: "21", InspectionID: "10", ScheduledDateTime: "2026-03-10 09:00:00"},
+				{ReInspectionID: "22", InspectionID: "11", ScheduledDateTime: "2026-04-01 09:00:00"},
+			},
+			expected: []OverdueReInspection{
+				{OccupancyID: "1", OccupancyName: "Acme", InspectionID: "10", ReInspectionID: "21", DueDateTime: "2026-03-10 09:00:00", DaysOverdue: 6},
+			},
+		},
+		{
+			description: "Order",
+			inspections: []*FireMarshalInspection{
+				{InspectionID: "10", OccupancyID: "999", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-01-01"},
+				{InspectionID: "11", OccupancyID: "2", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-03-01"},
+				{InspectionID: "12", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-03-01"},
+				{InspectionID: "13", OccupancyID: "1", ReInspectionRequired: "1", ReInspectionDueDateTime: "2026-02-01"},
+			},
+			expected: []OverdueReInspection{
+				{OccupancyID: "1", OccupancyName: "Acme", InspectionID: "13", DueDateTime: "2026-02-01", DaysOverdue: 43},
+				{OccupancyID: "1", OccupancyName: "Acme", InspectionID: "12", DueDateTime: "2026-03-01", DaysOverdue: 15},
+				{OccupancyID: "2", OccupancyName: "Bolt", InspectionID: "11", DueDateTime: "2026-03-01", DaysOverdue: 15},
+				{OccupancyID: "999", InspectionID: "10", DueDateTime: "2026-01-01", DaysOverdue: 74},
+			},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			results := OverdueReInspections(row.inspections, row.reInspections, occupancies, asOf)
+			if len(results) != len(row.expected) {
+				t.Fatalf("Expected %d results; got %d", len(row.expected), len(results))
+			}
+			for index, result := range results {
+				if *result != row.expected[index] {
+					t.Errorf("Result %d: expected %+v; got %+v", index, row.expected[index], *result)
+				}
+			}
+		})
+	}
+}
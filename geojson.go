@@ -0,0 +1,62 @@
+package emergencyreporting
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GeoJSON types.
+const (
+	geoJSONTypeFeatureCollection = "FeatureCollection"
+	geoJSONTypeFeature           = "Feature"
+	geoJSONTypePoint             = "Point"
+)
+
+// GeoJSONFeatureCollection is a GeoJSON (RFC 7946) feature collection.
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"` // Always "FeatureCollection".
+	Features []*GeoJSONFeature `json:"features"`
+}
+
+// NewGeoJSONFeatureCollection returns an empty feature collection.
+func NewGeoJSONFeatureCollection() *GeoJSONFeatureCollection {
+	return &GeoJSONFeatureCollection{
+		Type:     geoJSONTypeFeatureCollection,
+		Features: []*GeoJSONFeature{},
+	}
+}
+
+// GeoJSONFeature is a GeoJSON feature.
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"` // Always "Feature".
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *GeoJSONGeometry       `json:"geometry"` // This may be nil if the location is not known.
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry is a GeoJSON geometry.
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"` // For a point, this is the longitude and then the latitude.
+}
+
+// NewGeoJSONPoint returns a point for the latitude and longitude (as the API returns them).
+//
+// This returns nil if either of them is empty or not a number, or if they are both zero.
+func NewGeoJSONPoint(latitude string, longitude string) *GeoJSONGeometry {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	if err != nil {
+		return nil
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+	if err != nil {
+		return nil
+	}
+	if lat == 0 && lon == 0 {
+		return nil
+	}
+	return &GeoJSONGeometry{
+		Type:        geoJSONTypePoint,
+		Coordinates: []float64{lon, lat},
+	}
+}
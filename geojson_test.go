@@ -0,0 +1,60 @@
+package emergencyreporting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewGeoJSONPoint(t *testing.T) {
+	rows := []struct {
+		description string
+		latitude    string
+		longitude   string
+		expected    []float64 // Nil if there should be no point.
+	}{
+		{
+			description: "Longitude first",
+			latitude:    "39.7684",
+			longitude:   "-86.1581",
+			expected:    []float64{-86.1581, 39.7684},
+		},
+		{
+			description: "Spaces",
+			latitude:    " 39.5 ",
+			longitude:   " -86 ",
+			expected:    []float64{-86, 39.5},
+		},
+		{
+			description: "Empty",
+			latitude:    "",
+			longitude:   "-86.1581",
+		},
+		{
+			description: "Not a number",
+			latitude:    "39.7684",
+			longitude:   "west",
+		},
+		{
+			description: "Zero",
+			latitude:    "0",
+			longitude:   "0.0",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			point := NewGeoJSONPoint(row.latitude, row.longitude)
+			if row.expected == nil {
+				if point != nil {
+					t.Errorf("Expected no point; got %+v", point)
+				}
+				return
+			}
+			if point == nil {
+				t.Fatalf("Expected a point")
+			}
+			if point.Type != "Point" || !reflect.DeepEqual(point.Coordinates, row.expected) {
+				t.Errorf("Expected a point at %v; got %+v", row.expected, point)
+			}
+		})
+	}
+}
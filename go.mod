@@ -5,4 +5,5 @@ go 1.14
 require (
 	github.com/sirupsen/logrus v1.2.0
 	github.com/spf13/cobra v1.1.1
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,345 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Hydrant struct {
+	HydrantID               string `json:"hydrantID,omitempty"` // Not used for creating hydrants.
+	HydrantNumber           string `json:"hydrantNumber"`
+	HydrantType             string `json:"hydrantType"` // Such as "Dry Barrel" or "Wet Barrel".
+	Manufacturer            string `json:"manufacturer"`
+	Model                   string `json:"model"`
+	YearInstalled           string `json:"yearInstalled"`
+	MainSize                string `json:"mainSize"` // In inches.
+	OutletCount             string `json:"outletCount"`
+	ColorCode               string `json:"colorCode"` // The NFPA 291 class color, such as "Blue".
+	FlowRate                string `json:"flowRate"`  // In gallons per minute, from the latest flow test.
+	StreetAddress           string `json:"streetAddress"`
+	City                    string `json:"city"`
+	State                   string `json:"state"`
+	ZipCode                 string `json:"zipCode"`
+	Latitude                string `json:"latitude"`
+	Longitude               string `json:"longitude"`
+	LocationDescription     string `json:"locationDescription"`
+	StationID               string `json:"stationID"`
+	WaterSystem             string `json:"waterSystem"`
+	OutOfService            string `json:"outOfService"` // "1" if the hydrant is out of service.
+	OutOfServiceReason      string `json:"outOfServiceReason"`
+	OutOfServiceDateTime    string `json:"outOfServiceDateTime"`
+	ReturnToServiceDateTime string `json:"returnToServiceDateTime"`
+	Notes                   string `json:"notes"`
+	RowVersion              string `json:"rowVersion,omitempty"` // Not used for creating hydrants.
+}
+
+type GetHydrantsResponse struct {
+	TotalRows string     `json:"totalRows"`
+	Hydrants  []*Hydrant `json:"hydrants"`
+}
+
+type GetHydrantResponse struct {
+	Hydrant *Hydrant `json:"hydrant"`
+}
+
+type PostHydrantResponse struct {
+	HydrantID string `json:"hydrantID"`
+}
+
+type PatchHydrantRequest struct {
+	HydrantNumber           *string `json:"hydrantNumber,omitempty"`
+	HydrantType             *string `json:"hydrantType,omitempty"`
+	Manufacturer            *string `json:"manufacturer,omitempty"`
+	Model                   *string `json:"model,omitempty"`
+	YearInstalled           *string `json:"yearInstalled,omitempty"`
+	MainSize                *string `json:"mainSize,omitempty"`
+	OutletCount             *string `json:"outletCount,omitempty"`
+	ColorCode               *string `json:"colorCode,omitempty"`
+	FlowRate                *string `json:"flowRate,omitempty"`
+	StreetAddress           *string `json:"streetAddress,omitempty"`
+	City                    *string `json:"city,omitempty"`
+	State                   *string `json:"state,omitempty"`
+	ZipCode                 *string `json:"zipCode,omitempty"`
+	Latitude                *string `json:"latitude,omitempty"`
+	Longitude               *string `json:"longitude,omitempty"`
+	LocationDescription     *string `json:"locationDescription,omitempty"`
+	StationID               *string `json:"stationID,omitempty"`
+	WaterSystem             *string `json:"waterSystem,omitempty"`
+	OutOfService            *string `json:"outOfService,omitempty"`
+	OutOfServiceReason      *string `json:"outOfServiceReason,omitempty"`
+	OutOfServiceDateTime    *string `json:"outOfServiceDateTime,omitempty"`
+	ReturnToServiceDateTime *string `json:"returnToServiceDateTime,omitempty"`
+	Notes                   *string `json:"notes,omitempty"`
+}
+
+type PatchHydrantResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type HydrantFlowTest struct {
+	FlowTestID       string `json:"flowTestID,omitempty"` // Not used for creating flow tests.
+	HydrantID        string `json:"hydrantID,omitempty"`
+	TestDateTime     string `json:"testDateTime"`
+	StaticPressure   string `json:"staticPressure"`   // In PSI.
+	ResidualPressure string `json:"residualPressure"` // In PSI.
+	PitotPressure    string `json:"pitotPressure"`    // In PSI.
+	FlowRate         string `json:"flowRate"`         // In gallons per minute.
+	TestedByUserID   string `json:"testedByUserID"`
+	Notes            string `json:"notes"`
+	RowVersion       string `json:"rowVersion,omitempty"`
+}
+
+type GetHydrantFlowTestsResponse struct {
+	FlowTests []*HydrantFlowTest `json:"flowTests"`
+}
+
+type PostHydrantFlowTestResponse struct {
+	FlowTestID string `json:"flowTestID"`
+}
+
+type HydrantInspection struct {
+	InspectionID       string `json:"inspectionID,omitempty"` // Not used for creating inspections.
+	HydrantID          string `json:"hydrantID,omitempty"`
+	InspectionDateTime string `json:"inspectionDateTime"`
+	InspectedByUserID  string `json:"inspectedByUserID"`
+	Passed             string `json:"passed"` // "1" if the hydrant passed.
+	Flushed            string `json:"flushed"`
+	Painted            string `json:"painted"`
+	Deficiencies       string `json:"deficiencies"`
+	Notes              string `json:"notes"`
+	RowVersion         string `json:"rowVersion,omitempty"`
+}
+
+type GetHydrantInspectionsResponse struct {
+	Inspections []*HydrantInspection `json:"inspections"`
+}
+
+type PostHydrantInspectionResponse struct {
+	InspectionID string `json:"inspectionID"`
+}
+
+// GetHydrants gets a page of hydrants.
+func (c *Client) GetHydrants(ctx context.Context, options map[string]string) (*GetHydrantsResponse, error) {
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyhydrants/hydrants"
+
+	var parsedResponse GetHydrantsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the hydrants: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllHydrants gets every page of hydrants.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllHydrants(ctx context.Context, options map[string]string) ([]*Hydrant, error) {
+	var hydrants []*Hydrant
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetHydrants(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		hydrants = append(hydrants, response.Hydrants...)
+		return len(response.Hydrants), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hydrants, nil
+}
+
+// GetHydrant gets a hydrant.
+func (c *Client) GetHydrant(ctx context.Context, hydrantID string) (*GetHydrantResponse, error) {
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID)
+
+	var parsedResponse GetHydrantResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the hydrant: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostHydrant creates a hydrant.
+func (c *Client) PostHydrant(ctx context.Context, hydrant Hydrant) (*PostHydrantResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants
+
+	targetURL := "/agencyhydrants/hydrants"
+
+	jsonInput, err := json.Marshal(hydrant)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostHydrantResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the hydrant: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchHydrant updates a hydrant, including its out-of-service status.
+func (c *Client) PatchHydrant(ctx context.Context, hydrantID string, rowVersion string, payload PatchHydrantRequest) (*PatchHydrantResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchHydrantResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the hydrant: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetHydrantFlowTests gets the flow tests for a hydrant.
+func (c *Client) GetHydrantFlowTests(ctx context.Context, hydrantID string, options map[string]string) (*GetHydrantFlowTestsResponse, error) {
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}/flowtests[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID) + "/flowtests"
+
+	var parsedResponse GetHydrantFlowTestsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the hydrant flow tests: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostHydrantFlowTest adds a flow test to a hydrant.
+func (c *Client) PostHydrantFlowTest(ctx context.Context, hydrantID string, flowTest HydrantFlowTest) (*PostHydrantFlowTestResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}/flowtests
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID) + "/flowtests"
+
+	jsonInput, err := json.Marshal(flowTest)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostHydrantFlowTestResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the hydrant flow test: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetHydrantInspections gets the inspections for a hydrant.
+func (c *Client) GetHydrantInspections(ctx context.Context, hydrantID string, options map[string]string) (*GetHydrantInspectionsResponse, error) {
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}/inspections[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID) + "/inspections"
+
+	var parsedResponse GetHydrantInspectionsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the hydrant inspections: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostHydrantInspection adds an inspection to a hydrant.
+func (c *Client) PostHydrantInspection(ctx context.Context, hydrantID string, inspection HydrantInspection) (*PostHydrantInspectionResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyhydrants/hydrants/{hydrantID}/inspections
+
+	targetURL := "/agencyhydrants/hydrants/" + url.PathEscape(hydrantID) + "/inspections"
+
+	jsonInput, err := json.Marshal(inspection)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostHydrantInspectionResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the hydrant inspection: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// HydrantsGeoJSON returns the hydrants as a GeoJSON feature collection of points.
+//
+// The properties are the hydrant's fields (without the coordinates).  Hydrants without valid
+// coordinates are left out, since there is nothing to put on a map.
+func HydrantsGeoJSON(hydrants []*Hydrant) (*GeoJSONFeatureCollection, error) {
+	collection := NewGeoJSONFeatureCollection()
+	for _, hydrant := range hydrants {
+		point := NewGeoJSONPoint(hydrant.Latitude, hydrant.Longitude)
+		if point == nil {
+			continue
+		}
+		properties, err := jsonFields(hydrant)
+		if err != nil {
+			return nil, err
+		}
+		delete(properties, "latitude")
+		delete(properties, "longitude")
+		collection.Features = append(collection.Features, &GeoJSONFeature{
+			Type:       geoJSONTypeFeature,
+			ID:         hydrant.HydrantID,
+			Geometry:   point,
+			Properties: properties,
+		})
+	}
+	return collection, nil
+}
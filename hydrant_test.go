@@ -0,0 +1,62 @@
+package emergencyreporting
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestHydrantsGeoJSON(t *testing.T) {
+	hydrants := []*Hydrant{
+		{HydrantID: "1", HydrantNumber: "H-1", ColorCode: "Blue", Latitude: "39.7684", Longitude: "-86.1581"},
+		{HydrantID: "2", HydrantNumber: "H-2"},
+		{HydrantID: "3", HydrantNumber: "H-3", Latitude: "39.5", Longitude: "-86"},
+	}
+	collection, err := HydrantsGeoJSON(hydrants)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	contents, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatalf("Could not create JSON: %v", err)
+	}
+	var actual map[string]interface{}
+	err = json.Unmarshal(contents, &actual)
+	if err != nil {
+		t.Fatalf("Could not parse JSON: %v", err)
+	}
+
+	if actual["type"] != "FeatureCollection" {
+		t.Errorf("Expected a FeatureCollection; got %v", actual["type"])
+	}
+	features, _ := actual["features"].([]interface{})
+	if len(features) != 2 {
+		t.Fatalf("Expected 2 features (without the hydrant that has no coordinates); got %d", len(features))
+	}
+
+	first := features[0].(map[string]interface{})
+	if first["type"] != "Feature" || first["id"] != "1" {
+		t.Errorf("Expected feature 1; got %v", first)
+	}
+	expectedGeometry := map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{-86.1581, 39.7684},
+	}
+	if !reflect.DeepEqual(first["geometry"], expectedGeometry) {
+		t.Errorf("Expected geometry %v; got %v", expectedGeometry, first["geometry"])
+	}
+	properties := first["properties"].(map[string]interface{})
+	if properties["hydrantNumber"] != "H-1" || properties["colorCode"] != "Blue" {
+		t.Errorf("Expected the hydrant's fields as properties; got %v", properties)
+	}
+	for _, key := range []string{"latitude", "longitude"} {
+		if _, ok := properties[key]; ok {
+			t.Errorf("The properties should not have %s", key)
+		}
+	}
+
+	if second := features[1].(map[string]interface{}); second["id"] != "3" {
+		t.Errorf("Expected feature 3; got %v", second["id"])
+	}
+}
@@ -0,0 +1,297 @@
+package emergencyreporting
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operation describes a single call to the API.
+type Operation struct {
+	Name   string // The name of the client function, such as "GetIncidentExposure".
+	Method string // The HTTP method, such as "GET".
+	Route  string // The route template, such as "/agencyincidents/incidents/{incidentID}/exposures/{exposureID}".
+}
+
+// OperationResult is the outcome of a single call to the API.
+type OperationResult struct {
+	StatusCode    int           // The HTTP status code, or 0 if there was no response.
+	RequestBytes  int64         // The size of the request body.
+	ResponseBytes int64         // The size of the response body.
+	Duration      time.Duration // How long the call took, including reading the response and any retries.
+	Err           error         // The error that prevented a response, if any.
+}
+
+// Failed returns whether the operation failed, either with an error or with a non-2xx status code.
+func (r OperationResult) Failed() bool {
+	return r.Err != nil || r.StatusCode < 200 || r.StatusCode > 299
+}
+
+// Tracer starts a span for each operation.
+type Tracer interface {
+	// StartSpan starts a span for the operation.  The returned context is used for the request.
+	StartSpan(ctx context.Context, operation Operation) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	// End finishes the span.
+	End(result OperationResult)
+}
+
+// Metrics records metrics for each operation.
+type Metrics interface {
+	// ObserveOperation is called once per operation when it finishes.
+	ObserveOperation(operation Operation, result OperationResult)
+	// ObserveRetry is called each time that an operation is retried.
+	ObserveRetry(operation Operation)
+	// ObserveError is called when an operation fails.
+	ObserveError(operation Operation, result OperationResult)
+}
+
+// retryHookKey is the context key for the function that counts retries.
+type retryHookKey struct{}
+
+// observeRetry tells the instrumentation (if any) that the request is being retried.
+func observeRetry(ctx context.Context) {
+	if hook, ok := ctx.Value(retryHookKey{}).(func()); ok {
+		hook()
+	}
+}
+
+// route is a known API endpoint.
+type route struct {
+	name     string
+	method   string
+	template string
+}
+
+// routes are all of the known API endpoints.
+//
+// Literal segments must come before parameters in the same position so that
+// "/agencyincidents/incidents/exposures" is not mistaken for an incident ID.
+var routes = []route{
+	{"GenerateTokenLegacy", http.MethodPost, "/Token.php"},
+	{"GenerateToken2020", http.MethodPost, "/{tenantSegment}/B2C_1A_PasswordGrant/oauth2/v2.0/token"},
+	{"GetStations", http.MethodGet, "/agencystations/stations"},
+	{"GetExposures", http.MethodGet, "/agencyincidents/incidents/exposures"},
+	{"GetIncidents", http.MethodGet, "/agencyincidents/incidents"},
+	{"PostIncident", http.MethodPost, "/agencyincidents/incidents"},
+	{"GetIncident", http.MethodGet, "/agencyincidents/incidents/{incidentID}"},
+	{"PatchIncident", http.MethodPatch, "/agencyincidents/incidents/{incidentID}"},
+	{"DeleteIncident", http.MethodDelete, "/agencyincidents/incidents/{incidentID}"},
+	{"GetIncidentExposures", http.MethodGet, "/agencyincidents/incidents/{incidentID}/exposures"},
+	{"PostIncidentExposure", http.MethodPost, "/agencyincidents/incidents/{incidentID}/exposures"},
+	{"GetIncidentExposure", http.MethodGet, "/agencyincidents/incidents/{incidentID}/exposures/{exposureID}"},
+	{"PatchIncidentExposure", http.MethodPatch, "/agencyincidents/incidents/{incidentID}/exposures/{exposureID}"},
+	{"DeleteIncidentExposure", http.MethodDelete, "/agencyincidents/incidents/{incidentID}/exposures/{exposureID}"},
+	{"GetExposureLocation", http.MethodGet, "/agencyincidents/exposures/{exposureID}/location"},
+	{"PutExposureLocation", http.MethodPut, "/agencyincidents/exposures/{exposureID}/location"},
+	{"GetExposureFire", http.MethodGet, "/agencyincidents/exposures/{exposureID}/fire"},
+	{"GetExposureApparatuses", http.MethodGet, "/agencyincidents/exposures/{exposureID}/apparatuses"},
+	{"PostExposureApparatus", http.MethodPost, "/agencyincidents/exposures/{exposureID}/apparatuses"},
+	{"GetExposureMembers", http.MethodGet, "/agencyincidents/exposures/{exposureID}/crewmembers"},
+	{"PostExposureMember", http.MethodPost, "/agencyincidents/exposures/{exposureID}/crewmembers"},
+	{"GetExposureMember", http.MethodGet, "/agencyincidents/exposures/{exposureID}/crewmembers/{exposureUserID}"},
+	{"GetExposureMemberRoles", http.MethodGet, "/agencyincidents/crewmembers/{exposureUserID}/roles"},
+	{"GetUsers", http.MethodGet, "/agencyusers/users"},
+	{"GetUser", http.MethodGet, "/agencyusers/users/{userID}"},
+	{"PatchUser", http.MethodPatch, "/agencyusers/users/{userID}"},
+	{"GetUserContactInfo", http.MethodGet, "/agencyusers/users/{userID}/contactinfo"},
+	{"PutUserContactInfo", http.MethodPut, "/agencyusers/users/{userID}/contactinfo"},
+	{"GetUserLicenses", http.MethodGet, "/agencyusers/users/{userID}/licenses"},
+	{"PatchUserLicense", http.MethodPatch, "/agencyusers/users/{userID}/licenses/{licenseID}"},
+	{"GetUserCertifications", http.MethodGet, "/agencyusers/users/{userID}/certifications"},
+	{"PatchUserCertification", http.MethodPatch, "/agencyusers/users/{userID}/certifications/{certificationID}"},
+	{"GetApparatuses", http.MethodGet, "/agencyapparatus/apparatus"},
+	{"GetApparatus", http.MethodGet, "/agencyapparatus/apparatus/{departmentApparatusID}"},
+	{"GetHydrants", http.MethodGet, "/agencyhydrants/hydrants"},
+	{"PostHydrant", http.MethodPost, "/agencyhydrants/hydrants"},
+	{"GetHydrant", http.MethodGet, "/agencyhydrants/hydrants/{hydrantID}"},
+	{"PatchHydrant", http.MethodPatch, "/agencyhydrants/hydrants/{hydrantID}"},
+	{"GetHydrantFlowTests", http.MethodGet, "/agencyhydrants/hydrants/{hydrantID}/flowtests"},
+	{"PostHydrantFlowTest", http.MethodPost, "/agencyhydrants/hydrants/{hydrantID}/flowtests"},
+	{"GetHydrantInspections", http.MethodGet, "/agencyhydrants/hydrants/{hydrantID}/inspections"},
+	{"PostHydrantInspection", http.MethodPost, "/agencyhydrants/hydrants/{hydrantID}/inspections"},
+	{"GetOccupancies", http.MethodGet, "/agencyoccupancies/occupancies"},
+	{"PostOccupancy", http.MethodPost, "/agencyoccupancies/occupancies"},
+	{"GetOccupancy", http.MethodGet, "/agencyoccupancies/occupancies/{occupancyID}"},
+	{"PatchOccupancy", http.MethodPatch, "/agencyoccupancies/occupancies/{occupancyID}"},
+	{"GetOccupancyContacts", http.MethodGet, "/agencyoccupancies/occupancies/{occupancyID}/contacts"},
+	{"PostOccupancyContact", http.MethodPost, "/agencyoccupancies/occupancies/{occupancyID}/contacts"},
+	{"GetOccupancyHazards", http.MethodGet, "/agencyoccupancies/occupancies/{occupancyID}/hazards"},
+	{"PostOccupancyHazard", http.MethodPost, "/agencyoccupancies/occupancies/{occupancyID}/hazards"},
+	{"GetOccupancyPrePlans", http.MethodGet, "/agencyoccupancies/occupancies/{occupancyID}/preplans"},
+	{"PostOccupancyPrePlan", http.MethodPost, "/agencyoccupancies/occupancies/{occupancyID}/preplans"},
+	{"GetFireMarshalInspections", http.MethodGet, "/agencyfiremarshal/inspections"},
+	{"PostFireMarshalInspection", http.MethodPost, "/agencyfiremarshal/inspections"},
+	{"GetFireMarshalInspection", http.MethodGet, "/agencyfiremarshal/inspections/{inspectionID}"},
+	{"PatchFireMarshalInspection", http.MethodPatch, "/agencyfiremarshal/inspections/{inspectionID}"},
+	{"GetFireMarshalViolations", http.MethodGet, "/agencyfiremarshal/inspections/{inspectionID}/violations"},
+	{"PostFireMarshalViolation", http.MethodPost, "/agencyfiremarshal/inspections/{inspectionID}/violations"},
+	{"GetFireMarshalViolation", http.MethodGet, "/agencyfiremarshal/inspections/{inspectionID}/violations/{violationID}"},
+	{"PatchFireMarshalViolation", http.MethodPatch, "/agencyfiremarshal/inspections/{inspectionID}/violations/{violationID}"},
+	{"GetFireMarshalReInspections", http.MethodGet, "/agencyfiremarshal/inspections/{inspectionID}/reinspections"},
+	{"PostFireMarshalReInspection", http.MethodPost, "/agencyfiremarshal/inspections/{inspectionID}/reinspections"},
+	{"GetFireMarshalReInspection", http.MethodGet, "/agencyfiremarshal/inspections/{inspectionID}/reinspections/{reInspectionID}"},
+	{"PatchFireMarshalReInspection", http.MethodPatch, "/agencyfiremarshal/inspections/{inspectionID}/reinspections/{reInspectionID}"},
+	{"GetTrainingCategories", http.MethodGet, "/agencytraining/categories"},
+	{"GetTrainingClasses", http.MethodGet, "/agencytraining/classes"},
+	{"PostTrainingClass", http.MethodPost, "/agencytraining/classes"},
+	{"GetTrainingClass", http.MethodGet, "/agencytraining/classes/{classID}"},
+	{"PatchTrainingClass", http.MethodPatch, "/agencytraining/classes/{classID}"},
+	{"GetTrainingClassAttendees", http.MethodGet, "/agencytraining/classes/{classID}/attendees"},
+	{"PostTrainingClassAttendee", http.MethodPost, "/agencytraining/classes/{classID}/attendees"},
+	{"GetTrainingHours", http.MethodGet, "/agencytraining/hours"},
+	{"GetShifts", http.MethodGet, "/agencyshifts/shifts"},
+	{"GetShiftCalendar", http.MethodGet, "/agencyshifts/calendar"},
+	{"GetRosterPositions", http.MethodGet, "/agencyroster/positions"},
+	{"GetRosters", http.MethodGet, "/agencyroster/rosters"},
+	{"PostRoster", http.MethodPost, "/agencyroster/rosters"},
+	{"GetRoster", http.MethodGet, "/agencyroster/rosters/{rosterID}"},
+	{"PatchRoster", http.MethodPatch, "/agencyroster/rosters/{rosterID}"},
+	{"GetRosterAssignments", http.MethodGet, "/agencyroster/rosters/{rosterID}/assignments"},
+	{"PostRosterAssignment", http.MethodPost, "/agencyroster/rosters/{rosterID}/assignments"},
+	{"PatchRosterAssignment", http.MethodPatch, "/agencyroster/rosters/{rosterID}/assignments/{assignmentID}"},
+	{"GetEvents", http.MethodGet, "/agencyevents/events"},
+	{"PostEvent", http.MethodPost, "/agencyevents/events"},
+	{"GetEvent", http.MethodGet, "/agencyevents/events/{eventID}"},
+	{"PatchEvent", http.MethodPatch, "/agencyevents/events/{eventID}"},
+	{"GetEventAttendees", http.MethodGet, "/agencyevents/events/{eventID}/attendees"},
+	{"PostEventAttendee", http.MethodPost, "/agencyevents/events/{eventID}/attendees"},
+	{"PatchEventAttendee", http.MethodPatch, "/agencyevents/events/{eventID}/attendees/{attendeeID}"},
+	{"GetPaygrades", http.MethodGet, "/agencypayroll/paygrades"},
+	{"GetPayrollExports", http.MethodGet, "/agencypayroll/exports"},
+	{"PostPayrollExport", http.MethodPost, "/agencypayroll/exports"},
+	{"GetMaintenanceRecords", http.MethodGet, "/agencymaintenance/records"},
+	{"PostMaintenanceRecord", http.MethodPost, "/agencymaintenance/records"},
+	{"GetMaintenanceRecord", http.MethodGet, "/agencymaintenance/records/{recordID}"},
+	{"PatchMaintenanceRecord", http.MethodPatch, "/agencymaintenance/records/{recordID}"},
+	{"GetMaintenanceSchedules", http.MethodGet, "/agencymaintenance/schedules"},
+	{"PostMaintenanceSchedule", http.MethodPost, "/agencymaintenance/schedules"},
+	{"GetMaintenanceSchedule", http.MethodGet, "/agencymaintenance/schedules/{scheduleID}"},
+	{"PatchMaintenanceSchedule", http.MethodPatch, "/agencymaintenance/schedules/{scheduleID}"},
+	{"GetInventoryLocations", http.MethodGet, "/agencyinventory/locations"},
+	{"PostInventoryLocation", http.MethodPost, "/agencyinventory/locations"},
+	{"GetInventoryItems", http.MethodGet, "/agencyinventory/items"},
+	{"PostInventoryItem", http.MethodPost, "/agencyinventory/items"},
+	{"GetInventoryItem", http.MethodGet, "/agencyinventory/items/{itemID}"},
+	{"PatchInventoryItem", http.MethodPatch, "/agencyinventory/items/{itemID}"},
+	{"GetPatientCareReports", http.MethodGet, "/agencyems/patientcarereports"},
+	{"GetPatientCareReport", http.MethodGet, "/agencyems/patientcarereports/{pcrID}"},
+}
+
+// lookupOperation returns the operation for the request.
+//
+// Requests that don't match a known endpoint (such as from `RawOperation`) get the name "RawOperation"
+// and an empty route, so that they don't produce a separate metric for every ID.
+func lookupOperation(method string, path string) Operation {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, r := range routes {
+		if r.method != method {
+			continue
+		}
+		templateParts := strings.Split(strings.Trim(r.template, "/"), "/")
+		if len(templateParts) != len(parts) {
+			continue
+		}
+		matches := true
+		for index, templatePart := range templateParts {
+			if strings.HasPrefix(templatePart, "{") {
+				continue
+			}
+			if templatePart != parts[index] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return Operation{Name: r.name, Method: method, Route: r.template}
+		}
+	}
+	return Operation{Name: "RawOperation", Method: method}
+}
+
+// instrument wraps the handler with the client's tracer and metrics, if any.
+func (c *Client) instrument(handler RequestHandler) RequestHandler {
+	if c.Tracer == nil && c.Metrics == nil {
+		return handler
+	}
+	return func(request *http.Request) (*http.Response, error) {
+		operation := lookupOperation(request.Method, request.URL.Path)
+		start := time.Now()
+
+		ctx := request.Context()
+		var span Span
+		if c.Tracer != nil {
+			ctx, span = c.Tracer.StartSpan(ctx, operation)
+		}
+		if c.Metrics != nil {
+			ctx = context.WithValue(ctx, retryHookKey{}, func() {
+				c.Metrics.ObserveRetry(operation)
+			})
+		}
+		request = request.WithContext(ctx)
+
+		finish := func(result OperationResult) {
+			result.RequestBytes = request.ContentLength
+			result.Duration = time.Since(start)
+			if span != nil {
+				span.End(result)
+			}
+			if c.Metrics != nil {
+				c.Metrics.ObserveOperation(operation, result)
+				if result.Failed() {
+					c.Metrics.ObserveError(operation, result)
+				}
+			}
+		}
+
+		response, err := handler(request)
+		if err != nil {
+			finish(OperationResult{Err: err})
+			return nil, err
+		}
+
+		// The operation isn't over until the body has been read.
+		response.Body = &instrumentedBody{
+			ReadCloser: response.Body,
+			finish: func(bytes int64, readErr error) {
+				finish(OperationResult{StatusCode: response.StatusCode, ResponseBytes: bytes, Err: readErr})
+			},
+		}
+		return response, nil
+	}
+}
+
+// instrumentedBody counts the bytes in a response body and finishes the operation when it is closed.
+type instrumentedBody struct {
+	io.ReadCloser
+	finish func(bytes int64, err error)
+
+	once  sync.Once
+	bytes int64
+	err   error
+}
+
+// Read reads from the body.
+func (b *instrumentedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// Close closes the body and finishes the operation.
+func (b *instrumentedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.finish(b.bytes, b.err)
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package emergencyreporting
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLookupOperation(t *testing.T) {
+	rows := []struct {
+		description string
+		method      string
+		path        string
+		expected    Operation
+	}{
+		{
+			description: "Collection",
+			method:      http.MethodGet,
+			path:        "/agencyincidents/incidents",
+			expected:    Operation{Name: "GetIncidents", Method: http.MethodGet, Route: "/agencyincidents/incidents"},
+		},
+		{
+			description: "Method",
+			method:      http.MethodPost,
+			path:        "/agencyincidents/incidents",
+			expected:    Operation{Name: "PostIncident", Method: http.MethodPost, Route: "/agencyincidents/incidents"},
+		},
+		{
+			description: "Template",
+			method:      http.MethodGet,
+			path:        "/agencyincidents/incidents/123/exposures/456",
+			expected:    Operation{Name: "GetIncidentExposure", Method: http.MethodGet, Route: "/agencyincidents/incidents/{incidentID}/exposures/{exposureID}"},
+		},
+		{
+			description: "Literal before a parameter",
+			method:      http.MethodGet,
+			path:        "/agencyincidents/incidents/exposures",
+			expected:    Operation{Name: "GetExposures", Method: http.MethodGet, Route: "/agencyincidents/incidents/exposures"},
+		},
+		{
+			description: "Trailing slash",
+			method:      http.MethodPatch,
+			path:        "/agencyusers/users/7/",
+			expected:    Operation{Name: "PatchUser", Method: http.MethodPatch, Route: "/agencyusers/users/{userID}"},
+		},
+		{
+			description: "Token",
+			method:      http.MethodPost,
+			path:        "/tenant.onmicrosoft.com/B2C_1A_PasswordGrant/oauth2/v2.0/token",
+			expected:    Operation{Name: "GenerateToken2020", Method: http.MethodPost, Route: "/{tenantSegment}/B2C_1A_PasswordGrant/oauth2/v2.0/token"},
+		},
+		{
+			description: "Unknown path",
+			method:      http.MethodGet,
+			path:        "/agencyincidents/incidents/123/unknown",
+			expected:    Operation{Name: "RawOperation", Method: http.MethodGet},
+		},
+		{
+			description: "Unknown method",
+			method:      http.MethodDelete,
+			path:        "/agencyusers/users/7",
+			expected:    Operation{Name: "RawOperation", Method: http.MethodDelete},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := lookupOperation(row.method, row.path)
+			if actual != row.expected {
+				t.Errorf("Expected %+v; got %+v", row.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// InventoryLocation is a place where inventory is kept, such as a station's supply room or an apparatus compartment.
+type InventoryLocation struct {
+	LocationID            string `json:"locationID,omitempty"` // Not used for creating locations.
+	LocationName          string `json:"locationName"`
+	StationID             string `json:"stationID"`
+	DepartmentApparatusID string `json:"departmentApparatusID"` // Set if the location is on an apparatus.
+	Description           string `json:"description"`
+}
+
+type GetInventoryLocationsResponse struct {
+	Locations []*InventoryLocation `json:"locations"`
+}
+
+type PostInventoryLocationResponse struct {
+	LocationID string `json:"locationID"`
+}
+
+type InventoryItem struct {
+	ItemID         string `json:"itemID,omitempty"` // Not used for creating items.
+	ItemName       string `json:"itemName"`
+	Category       string `json:"category"` // Such as "SCBA", "Hose", or "Medical".
+	SerialNumber   string `json:"serialNumber"`
+	Quantity       string `json:"quantity"`
+	LocationID     string `json:"locationID"`
+	LocationName   string `json:"locationName,omitempty"` // Not used for creating items.
+	Status         string `json:"status"`                 // Such as "In Service" or "Out of Service".
+	ExpirationDate string `json:"expirationDate"`
+	Notes          string `json:"notes"`
+	RowVersion     string `json:"rowVersion,omitempty"` // Not used for creating items.
+}
+
+type GetInventoryItemsResponse struct {
+	TotalRows string           `json:"totalRows"`
+	Items     []*InventoryItem `json:"items"`
+}
+
+type GetInventoryItemResponse struct {
+	Item *InventoryItem `json:"item"`
+}
+
+type PostInventoryItemResponse struct {
+	ItemID string `json:"itemID"`
+}
+
+type PatchInventoryItemRequest struct {
+	ItemName       *string `json:"itemName,omitempty"`
+	Category       *string `json:"category,omitempty"`
+	SerialNumber   *string `json:"serialNumber,omitempty"`
+	Quantity       *string `json:"quantity,omitempty"`
+	LocationID     *string `json:"locationID,omitempty"`
+	Status         *string `json:"status,omitempty"`
+	ExpirationDate *string `json:"expirationDate,omitempty"`
+	Notes          *string `json:"notes,omitempty"`
+}
+
+type PatchInventoryItemResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetInventoryLocations gets the places where inventory is kept.
+func (c *Client) GetInventoryLocations(ctx context.Context, options map[string]string) (*GetInventoryLocationsResponse, error) {
+	// https://data.emergencyreporting.com/agencyinventory/locations[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyinventory/locations"
+
+	var parsedResponse GetInventoryLocationsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the inventory locations: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostInventoryLocation creates an inventory location.
+func (c *Client) PostInventoryLocation(ctx context.Context, location InventoryLocation) (*PostInventoryLocationResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyinventory/locations
+
+	targetURL := "/agencyinventory/locations"
+
+	jsonInput, err := json.Marshal(location)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostInventoryLocationResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the inventory location: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetInventoryItems gets a page of inventory items.
+func (c *Client) GetInventoryItems(ctx context.Context, options map[string]string) (*GetInventoryItemsResponse, error) {
+	// https://data.emergencyreporting.com/agencyinventory/items[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyinventory/items"
+
+	var parsedResponse GetInventoryItemsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the inventory items: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllInventoryItems gets every page of inventory items.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllInventoryItems(ctx context.Context, options map[string]string) ([]*InventoryItem, error) {
+	var items []*InventoryItem
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetInventoryItems(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		items = append(items, response.Items...)
+		return len(response.Items), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetInventoryItem gets an inventory item.
+func (c *Client) GetInventoryItem(ctx context.Context, itemID string) (*GetInventoryItemResponse, error) {
+	// https://data.emergencyreporting.com/agencyinventory/items/{itemID}
+
+	targetURL := "/agencyinventory/items/" + url.PathEscape(itemID)
+
+	var parsedResponse GetInventoryItemResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the inventory item: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostInventoryItem creates an inventory item.
+func (c *Client) PostInventoryItem(ctx context.Context, item InventoryItem) (*PostInventoryItemResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyinventory/items
+
+	targetURL := "/agencyinventory/items"
+
+	jsonInput, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostInventoryItemResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the inventory item: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchInventoryItem updates an inventory item.
+func (c *Client) PatchInventoryItem(ctx context.Context, itemID string, rowVersion string, payload PatchInventoryItemRequest) (*PatchInventoryItemResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyinventory/items/{itemID}
+
+	targetURL := "/agencyinventory/items/" + url.PathEscape(itemID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchInventoryItemResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the inventory item: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
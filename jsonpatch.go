@@ -0,0 +1,131 @@
+package emergencyreporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSON Patch operations.
+const (
+	PatchOperationAdd     = "add"
+	PatchOperationRemove  = "remove"
+	PatchOperationReplace = "replace"
+	PatchOperationTest    = "test" // The patch fails unless the value at the path is equal to the given one.
+)
+
+// MarshalJSON leaves out the value for "remove" operations (and keeps a null value for the others).
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Operation == PatchOperationRemove {
+		return json.Marshal(struct {
+			Operation string `json:"op"`
+			Path      string `json:"path"`
+		}{o.Operation, o.Path})
+	}
+	type plain PatchOperation
+	return json.Marshal(plain(o))
+}
+
+// Validate checks that every operation is supported and has a valid path.
+func (r PatchUserRequest) Validate() error {
+	for index, operation := range r {
+		switch operation.Operation {
+		case PatchOperationAdd, PatchOperationRemove, PatchOperationReplace, PatchOperationTest:
+		default:
+			return fmt.Errorf("operation %d: unsupported operation: %q", index, operation.Operation)
+		}
+		if !strings.HasPrefix(operation.Path, "/") {
+			return fmt.Errorf("operation %d: invalid path: %q", index, operation.Path)
+		}
+	}
+	return nil
+}
+
+// PatchPath returns the JSON Pointer for the given tokens, such as "/licenses/0".
+//
+// Any "~" and "/" characters in the tokens are escaped.
+func PatchPath(tokens ...string) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString("/")
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return builder.String()
+}
+
+// userReadOnlyFields are the user fields that `DiffUser` never changes.
+var userReadOnlyFields = map[string]bool{
+	"rowNum":     true,
+	"userID":     true,
+	"rowVersion": true,
+}
+
+// DiffUser returns the JSON Patch that turns the existing user into the desired one.
+//
+// Each field that differs (by its JSON value) is replaced as a whole, and a field that goes from set to unset
+// (null) is removed; the IDs and the row version are skipped.  The operations are sorted by path.  This returns
+// an empty patch if nothing changed.
+func DiffUser(existing User, desired User) (PatchUserRequest, error) {
+	existingFields, err := jsonFields(existing)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := jsonFields(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range existingFields {
+		keys = append(keys, key)
+	}
+	for key := range desiredFields {
+		if _, ok := existingFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	patch := PatchUserRequest{}
+	for _, key := range keys {
+		if userReadOnlyFields[key] {
+			continue
+		}
+		existingValue := existingFields[key]
+		desiredValue := desiredFields[key]
+		if reflect.DeepEqual(existingValue, desiredValue) {
+			continue
+		}
+		operation := PatchOperationReplace
+		switch {
+		case desiredValue == nil:
+			operation = PatchOperationRemove
+		case existingValue == nil:
+			if _, ok := existingFields[key]; !ok {
+				operation = PatchOperationAdd
+			}
+		}
+		patch = append(patch, PatchOperation{
+			Operation: operation,
+			Path:      PatchPath(key),
+			Value:     desiredValue,
+		})
+	}
+	return patch, nil
+}
+
+// jsonFields returns the top-level fields of the value's JSON representation.
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(contents, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse JSON: %w", err)
+	}
+	return fields, nil
+}
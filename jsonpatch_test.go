@@ -0,0 +1,100 @@
+package emergencyreporting
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffUser(t *testing.T) {
+	stringPointer := func(value string) *string {
+		return &value
+	}
+
+	existing := User{
+		UserID:     "1",
+		FullName:   "Jane Doe",
+		Title:      stringPointer("Captain"),
+		RowVersion: "5",
+		Licenses:   []*UserLicense{{LicenseType: "EMT", ExpirationDate: "2026-01-01"}},
+	}
+
+	rows := []struct {
+		description string
+		desired     func(user User) User
+		expected    string
+	}{
+		{
+			description: "Same",
+			desired:     func(user User) User { return user },
+			expected:    `[]`,
+		},
+		{
+			description: "Read-only fields are skipped",
+			desired: func(user User) User {
+				user.UserID = "2"
+				user.RowVersion = "6"
+				return user
+			},
+			expected: `[]`,
+		},
+		{
+			description: "Replace",
+			desired: func(user User) User {
+				user.FullName = "Jane Roe"
+				user.Title = stringPointer("Chief")
+				return user
+			},
+			expected: `[{"op":"replace","path":"/fullName","value":"Jane Roe"},{"op":"replace","path":"/title","value":"Chief"}]`,
+		},
+		{
+			description: "Set",
+			desired: func(user User) User {
+				user.Shift = stringPointer("A")
+				return user
+			},
+			expected: `[{"op":"replace","path":"/shift","value":"A"}]`,
+		},
+		{
+			description: "Unset",
+			desired: func(user User) User {
+				user.Title = nil
+				user.Licenses = nil
+				return user
+			},
+			expected: `[{"op":"remove","path":"/licenses"},{"op":"remove","path":"/title"}]`,
+		},
+		{
+			description: "Lists are replaced whole",
+			desired: func(user User) User {
+				user.Licenses = []*UserLicense{{LicenseType: "EMT", ExpirationDate: "2028-01-01"}}
+				return user
+			},
+			expected: `[{"op":"replace","path":"/licenses","value":[{"expirationDate":"2028-01-01","issueDate":"","issuingBody":"","level":"","licenseNumber":"","licenseType":"EMT","state":""}]}]`,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			patch, err := DiffUser(existing, row.desired(existing))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			err = patch.Validate()
+			if err != nil {
+				t.Errorf("The patch is not valid: %v", err)
+			}
+			contents, err := json.Marshal(patch)
+			if err != nil {
+				t.Fatalf("Could not create JSON: %v", err)
+			}
+			if string(contents) != row.expected {
+				t.Errorf("Expected %s; got %s", row.expected, contents)
+			}
+		})
+	}
+}
+
+func TestPatchPath(t *testing.T) {
+	if actual := PatchPath("licenses", "0", "a/b~c"); actual != "/licenses/0/a~1b~0c" {
+		t.Errorf("Wrong path: %s", actual)
+	}
+}
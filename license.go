@@ -0,0 +1,363 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// License levels.
+const (
+	LicenseLevelEMT          = "EMT"
+	LicenseLevelParamedic    = "Paramedic"
+	LicenseLevelFirefighter1 = "FF1" // Firefighter I.
+	LicenseLevelFirefighter2 = "FF2" // Firefighter II.
+)
+
+// UserLicense is a license or certification that a user holds.
+type UserLicense struct {
+	LicenseID      string `json:"licenseID,omitempty"`
+	LicenseType    string `json:"licenseType"` // Such as "State EMS License" or "NREMT".
+	Level          string `json:"level"`       // One of the `LicenseLevel*` constants, if it has a level.
+	IssuingBody    string `json:"issuingBody"`
+	LicenseNumber  string `json:"licenseNumber"`
+	State          string `json:"state"`
+	IssueDate      string `json:"issueDate"`
+	ExpirationDate string `json:"expirationDate"` // Empty if it does not expire.
+	RowVersion     string `json:"rowVersion,omitempty"`
+}
+
+// Certification statuses, for `UserCertification.Status` and `User.CertificationStatus`.
+const (
+	CertificationStatusCurrent  = "Current"
+	CertificationStatusExpiring = "Expiring"
+	CertificationStatusExpired  = "Expired"
+)
+
+// UserCertification is a certification that a user holds, such as a course or an instructor rating.
+//
+// State and national licenses (such as an EMS license) are `UserLicense`s instead.
+type UserCertification struct {
+	CertificationID     string `json:"certificationID,omitempty"`
+	CertificationName   string `json:"certificationName"` // Such as "Hazardous Materials Operations".
+	Level               string `json:"level"`             // One of the `LicenseLevel*` constants, if it has a level.
+	IssuingBody         string `json:"issuingBody"`
+	CertificationNumber string `json:"certificationNumber"`
+	IssueDate           string `json:"issueDate"`
+	ExpirationDate      string `json:"expirationDate"` // Empty if it does not expire.
+	Status              string `json:"status"`         // One of the `CertificationStatus*` constants.
+	RowVersion          string `json:"rowVersion,omitempty"`
+}
+
+type GetUserLicensesResponse struct {
+	Licenses []*UserLicense `json:"licenses"`
+}
+
+type PatchUserLicenseRequest struct {
+	LicenseType    *string `json:"licenseType,omitempty"`
+	Level          *string `json:"level,omitempty"`
+	IssuingBody    *string `json:"issuingBody,omitempty"`
+	LicenseNumber  *string `json:"licenseNumber,omitempty"`
+	State          *string `json:"state,omitempty"`
+	IssueDate      *string `json:"issueDate,omitempty"`
+	ExpirationDate *string `json:"expirationDate,omitempty"`
+}
+
+type PatchUserLicenseResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type GetUserCertificationsResponse struct {
+	Certifications []*UserCertification `json:"certifications"`
+}
+
+type PatchUserCertificationRequest struct {
+	CertificationName   *string `json:"certificationName,omitempty"`
+	Level               *string `json:"level,omitempty"`
+	IssuingBody         *string `json:"issuingBody,omitempty"`
+	CertificationNumber *string `json:"certificationNumber,omitempty"`
+	IssueDate           *string `json:"issueDate,omitempty"`
+	ExpirationDate      *string `json:"expirationDate,omitempty"`
+}
+
+type PatchUserCertificationResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetUserLicenses gets the licenses for a user.
+func (c *Client) GetUserLicenses(ctx context.Context, userID string, options map[string]string) (*GetUserLicensesResponse, error) {
+	// https://data.emergencyreporting.com/agencyusers/users/{userID}/licenses[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyusers/users/" + url.PathEscape(userID) + "/licenses"
+
+	var parsedResponse GetUserLicensesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the user licenses: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllUserLicenses gets every page of a user's licenses.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllUserLicenses(ctx context.Context, userID string, options map[string]string) ([]*UserLicense, error) {
+	var licenses []*UserLicense
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetUserLicenses(ctx, userID, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		licenses = append(licenses, response.Licenses...)
+		return len(response.Licenses), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return licenses, nil
+}
+
+// PatchUserLicense updates one of a user's licenses.
+func (c *Client) PatchUserLicense(ctx context.Context, userID string, licenseID string, rowVersion string, payload PatchUserLicenseRequest) (*PatchUserLicenseResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyusers/users/{userID}/licenses/{licenseID}
+
+	targetURL := "/agencyusers/users/" + url.PathEscape(userID) + "/licenses/" + url.PathEscape(licenseID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchUserLicenseResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the user license: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetUserCertifications gets the certifications for a user.
+func (c *Client) GetUserCertifications(ctx context.Context, userID string, options map[string]string) (*GetUserCertificationsResponse, error) {
+	// https://data.emergencyreporting.com/agencyusers/users/{userID}/certifications[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyusers/users/" + url.PathEscape(userID) + "/certifications"
+
+	var parsedResponse GetUserCertificationsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the user certifications: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllUserCertifications gets every page of a user's certifications.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllUserCertifications(ctx context.Context, userID string, options map[string]string) ([]*UserCertification, error) {
+	var certifications []*UserCertification
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetUserCertifications(ctx, userID, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		certifications = append(certifications, response.Certifications...)
+		return len(response.Certifications), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return certifications, nil
+}
+
+// PatchUserCertification updates one of a user's certifications.
+func (c *Client) PatchUserCertification(ctx context.Context, userID string, certificationID string, rowVersion string, payload PatchUserCertificationRequest) (*PatchUserCertificationResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyusers/users/{userID}/certifications/{certificationID}
+
+	targetURL := "/agencyusers/users/" + url.PathEscape(userID) + "/certifications/" + url.PathEscape(certificationID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchUserCertificationResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the user certification: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// LicenseExpirationOptions are the options for `LicenseExpirations`.
+type LicenseExpirationOptions struct {
+	AsOf           time.Time // The time to count from; if zero, then the current time is used.
+	Days           int       // Licenses that expire within this many days are included.
+	IncludeExpired bool      // If true, then licenses that have already expired are included.
+}
+
+// Expiration kinds.
+const (
+	ExpirationKindLicense       = "license"
+	ExpirationKindCertification = "certification"
+)
+
+// LicenseExpiration is a license or certification that is expiring (or has expired).
+//
+// For a certification, the license type and number are the certification's name and number.
+type LicenseExpiration struct {
+	Kind              string `json:"kind"` // One of the `ExpirationKind*` constants.
+	UserID            string `json:"userID"`
+	FullName          string `json:"fullName"`
+	AgencyPersonnelID string `json:"agencyPersonnelID"`
+	LicenseID         string `json:"licenseID,omitempty"`
+	CertificationID   string `json:"certificationID,omitempty"`
+	LicenseType       string `json:"licenseType"`
+	Level             string `json:"level"`
+	IssuingBody       string `json:"issuingBody"`
+	LicenseNumber     string `json:"licenseNumber"`
+	ExpirationDate    string `json:"expirationDate"`
+	DaysRemaining     int    `json:"daysRemaining"` // Negative if it has already expired.
+}
+
+// LicenseExpirations returns the users' licenses and certifications that expire within the given number
+// of days, sorted by expiration date.
+//
+// This uses the users' `Licenses` and `Certifications` as they are; see `GetLicenseExpirations` to fill
+// them in.  Licenses without an expiration date (or with one that can't be parsed) are skipped.  Dates without a
+// time zone are in the location of `AsOf`, and a license expires at the end of its expiration date.
+func LicenseExpirations(users []*User, options LicenseExpirationOptions) []*LicenseExpiration {
+	asOf := options.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	type expiration struct {
+		result  *LicenseExpiration
+		expires time.Time
+	}
+	var expirations []expiration
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		agencyPersonnelID := ""
+		if user.AgencyPersonnelID != nil {
+			agencyPersonnelID = *user.AgencyPersonnelID
+		}
+
+		var candidates []*LicenseExpiration
+		for _, license := range user.Licenses {
+			if license == nil {
+				continue
+			}
+			candidates = append(candidates, &LicenseExpiration{
+				Kind:           ExpirationKindLicense,
+				LicenseID:      license.LicenseID,
+				LicenseType:    license.LicenseType,
+				Level:          license.Level,
+				IssuingBody:    license.IssuingBody,
+				LicenseNumber:  license.LicenseNumber,
+				ExpirationDate: license.ExpirationDate,
+			})
+		}
+		for _, certification := range user.Certifications {
+			if certification == nil {
+				continue
+			}
+			candidates = append(candidates, &LicenseExpiration{
+				Kind:            ExpirationKindCertification,
+				CertificationID: certification.CertificationID,
+				LicenseType:     certification.CertificationName,
+				Level:           certification.Level,
+				IssuingBody:     certification.IssuingBody,
+				LicenseNumber:   certification.CertificationNumber,
+				ExpirationDate:  certification.ExpirationDate,
+			})
+		}
+
+		for _, result := range candidates {
+			expires, ok := parseDateTime(result.ExpirationDate, asOf.Location())
+			if !ok {
+				continue
+			}
+			if expires.Hour() == 0 && expires.Minute() == 0 && expires.Second() == 0 {
+				expires = expires.AddDate(0, 0, 1)
+			}
+			remaining := expires.Sub(asOf)
+			if remaining > time.Duration(options.Days)*24*time.Hour {
+				continue
+			}
+			if remaining <= 0 && !options.IncludeExpired {
+				continue
+			}
+
+			result.UserID = user.UserID
+			result.FullName = user.FullName
+			result.AgencyPersonnelID = agencyPersonnelID
+			result.DaysRemaining = daysRemaining(remaining)
+			expirations = append(expirations, expiration{result: result, expires: expires})
+		}
+	}
+
+	sort.SliceStable(expirations, func(i, j int) bool {
+		return expirations[i].expires.Before(expirations[j].expires)
+	})
+	results := []*LicenseExpiration{}
+	for _, expiration := range expirations {
+		results = append(results, expiration.result)
+	}
+	return results
+}
+
+// GetLicenseExpirations gets every user and returns their licenses and certifications that are expiring.
+//
+// The user list does not reliably include the licenses (and never includes the certifications), so
+// they are fetched for each user.  See `LicenseExpirations`.
+func (c *Client) GetLicenseExpirations(ctx context.Context, options LicenseExpirationOptions) ([]*LicenseExpiration, error) {
+	users, err := c.GetAllUsers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		user.Licenses, err = c.GetAllUserLicenses(ctx, user.UserID, nil)
+		if err != nil {
+			return nil, err
+		}
+		user.Certifications, err = c.GetAllUserCertifications(ctx, user.UserID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return LicenseExpirations(users, options), nil
+}
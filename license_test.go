@@ -0,0 +1,80 @@
+package emergencyreporting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLicenseExpirations(t *testing.T) {
+	asOf := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	agencyPersonnelID := "1001"
+
+	users := []*User{
+		{
+			UserID:            "1",
+			FullName:          "Jane Doe",
+			AgencyPersonnelID: &agencyPersonnelID,
+			Licenses: []*UserLicense{
+				{LicenseID: "10", LicenseType: "State EMS", LicenseNumber: "P1", ExpirationDate: "2026-11-01"},
+				{LicenseID: "11", LicenseType: "Firefighter", ExpirationDate: "2027-11-01"},
+				{LicenseID: "12", LicenseType: "Lifetime"},
+				{LicenseID: "13", LicenseType: "Bad", ExpirationDate: "soon"},
+				nil,
+			},
+		},
+		{
+			UserID:   "2",
+			FullName: "John Roe",
+			Licenses: []*UserLicense{
+				{LicenseID: "20", LicenseType: "NREMT", ExpirationDate: "2026-10-01"},
+				{LicenseID: "21", LicenseType: "CPR", ExpirationDate: "2026-10-18"},
+			},
+			Certifications: []*UserCertification{
+				{CertificationID: "30", CertificationName: "Hazmat Operations", CertificationNumber: "HM-1", IssuingBody: "State Fire Academy", ExpirationDate: "2026-10-25"},
+			},
+		},
+		nil,
+	}
+
+	rows := []struct {
+		description string
+		options     LicenseExpirationOptions
+		expected    []LicenseExpiration
+	}{
+		{
+			description: "Within 30 days",
+			options:     LicenseExpirationOptions{AsOf: asOf, Days: 30},
+			expected: []LicenseExpiration{
+				{Kind: ExpirationKindLicense, UserID: "2", FullName: "John Roe", LicenseID: "21", LicenseType: "CPR", ExpirationDate: "2026-10-18", DaysRemaining: 0},
+				{Kind: ExpirationKindCertification, UserID: "2", FullName: "John Roe", CertificationID: "30", LicenseType: "Hazmat Operations", IssuingBody: "State Fire Academy", LicenseNumber: "HM-1", ExpirationDate: "2026-10-25", DaysRemaining: 7},
+				{Kind: ExpirationKindLicense, UserID: "1", FullName: "Jane Doe", AgencyPersonnelID: "1001", LicenseID: "10", LicenseType: "State EMS", LicenseNumber: "P1", ExpirationDate: "2026-11-01", DaysRemaining: 14},
+			},
+		},
+		{
+			description: "Including expired",
+			options:     LicenseExpirationOptions{AsOf: asOf, Days: 7, IncludeExpired: true},
+			expected: []LicenseExpiration{
+				{Kind: ExpirationKindLicense, UserID: "2", FullName: "John Roe", LicenseID: "20", LicenseType: "NREMT", ExpirationDate: "2026-10-01", DaysRemaining: -17},
+				{Kind: ExpirationKindLicense, UserID: "2", FullName: "John Roe", LicenseID: "21", LicenseType: "CPR", ExpirationDate: "2026-10-18", DaysRemaining: 0},
+			},
+		},
+		{
+			description: "Nothing within zero days",
+			options:     LicenseExpirationOptions{AsOf: asOf},
+			expected:    []LicenseExpiration{},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			results := LicenseExpirations(users, row.options)
+			if len(results) != len(row.expected) {
+				t.Fatalf("Expected %d results; got %d", len(row.expected), len(results))
+			}
+			for index, result := range results {
+				if *result != row.expected[index] {
+					t.Errorf("Result %d: expected %+v; got %+v", index, row.expected[index], *result)
+				}
+			}
+		})
+	}
+}
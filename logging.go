@@ -0,0 +1,232 @@
+package emergencyreporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Level is a logging level.
+type Level int
+
+// Logging levels.
+const (
+	LevelDebug Level = iota // Request and response bodies (redacted) are only logged at this level.
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel parses a level name, such as "debug" or "WARN".
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown level: %s", name)
+}
+
+// LeveledLogger is a structured logger with levels.
+//
+// The key/value pairs alternate between keys (strings) and values, like "method", "GET", "status", 200.
+// See the "erlogrus" and "erslog" packages for adapters.
+type LeveledLogger interface {
+	Debug(message string, keysAndValues ...interface{})
+	Info(message string, keysAndValues ...interface{})
+	Warn(message string, keysAndValues ...interface{})
+	Error(message string, keysAndValues ...interface{})
+}
+
+// PrintfLogger is a `LeveledLogger` that writes lines to a `Logger`, such as a `*log.Logger`.
+//
+// Messages below the level are dropped.
+type PrintfLogger struct {
+	Logger Logger
+	Level  Level
+}
+
+// NewPrintfLogger returns a leveled logger that writes to the given logger.
+func NewPrintfLogger(logger Logger, level Level) *PrintfLogger {
+	return &PrintfLogger{
+		Logger: logger,
+		Level:  level,
+	}
+}
+
+// Debug logs a message at the debug level.
+func (l *PrintfLogger) Debug(message string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, message, keysAndValues)
+}
+
+// Info logs a message at the info level.
+func (l *PrintfLogger) Info(message string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, message, keysAndValues)
+}
+
+// Warn logs a message at the warn level.
+func (l *PrintfLogger) Warn(message string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, message, keysAndValues)
+}
+
+// Error logs a message at the error level.
+func (l *PrintfLogger) Error(message string, keysAndValues ...interface{}) {
+	l.log(LevelError, message, keysAndValues)
+}
+
+// log writes the line, if the level is high enough.
+func (l *PrintfLogger) log(level Level, message string, keysAndValues []interface{}) {
+	if level < l.Level || l.Logger == nil {
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(level.String())
+	builder.WriteString(" ")
+	builder.WriteString(message)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprintf("%v", keysAndValues[i])
+		value := "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = fmt.Sprintf("%v", keysAndValues[i+1])
+		}
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		builder.WriteString(" ")
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(value)
+	}
+	l.Logger.Printf("%s\n", builder.String())
+}
+
+// RedactedValue replaces the values of redacted fields.
+const RedactedValue = "REDACTED"
+
+// DefaultRedactedFields are the fields that are redacted from logged bodies unless `Client.RedactedFields` is set.
+//
+// These are case-insensitive `path.Match` patterns.
+var DefaultRedactedFields = []string{
+	"*password*",
+	"*secret*",
+	"*token*",
+	"authorization",
+	"*subscription*key*",
+	"*phone*",
+	"*email*",
+	"fullname",
+	"firstname",
+	"lastname",
+	"middlename",
+	"login",
+	"username",
+	"streetaddress*",
+	"emergencycontacts",
+	"patient",
+	"dateofbirth",
+	"ssn",
+	"*socialsecurity*",
+	"vitals",
+	"procedures",
+	"medications",
+	"*narrative*",
+}
+
+// Redact returns the body with the values of any matching fields replaced by `RedactedValue`.
+//
+// JSON bodies are redacted at every level; form bodies are redacted by key.  Anything else is returned as-is.
+func Redact(body []byte, fields []string) string {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Keep large numbers exactly as they were.
+	if decoder.Decode(&document) == nil && !decoder.More() {
+		contents, err := json.Marshal(redactValue(document, fields, func(interface{}) interface{} { return RedactedValue }))
+		if err == nil {
+			return string(contents)
+		}
+	}
+	if values, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") && !strings.ContainsAny(string(body), "{}<> \n") {
+		for key := range values {
+			if redactedField(key, fields) {
+				values.Set(key, RedactedValue)
+			}
+		}
+		return values.Encode()
+	}
+	return string(body)
+}
+
+// RedactJSONKeepingTypes returns the JSON body with the values of any matching fields redacted
+// such that it still decodes into the same types.
+//
+// Matching strings are replaced by `RedactedValue`; anything else (objects, arrays, numbers, and
+// booleans) is replaced by null.
+func RedactJSONKeepingTypes(body []byte, fields []string) ([]byte, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(document, fields, func(value interface{}) interface{} {
+		if _, ok := value.(string); ok {
+			return RedactedValue
+		}
+		return nil
+	}))
+}
+
+// redactValue redacts the matching fields in a decoded JSON value, replacing each one's value with the result of the replacement function.
+func redactValue(value interface{}, fields []string, replacement func(interface{}) interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typedValue {
+			if child != nil && redactedField(key, fields) {
+				typedValue[key] = replacement(child)
+				continue
+			}
+			typedValue[key] = redactValue(child, fields, replacement)
+		}
+	case []interface{}:
+		for index, child := range typedValue {
+			typedValue[index] = redactValue(child, fields, replacement)
+		}
+	}
+	return value
+}
+
+// redactedField returns whether the field name matches any of the patterns.
+func redactedField(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package emergencyreporting
+
+import (
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	rows := []struct {
+		description string
+		body        string
+		fields      []string
+		expected    string
+	}{
+		{
+			description: "Nested JSON",
+			body:        `{"user":{"fullName":"Jane Doe","userID":"1"},"contacts":[{"emailAddress":"jane@example.com"}]}`,
+			fields:      DefaultRedactedFields,
+			expected:    `{"contacts":[{"emailAddress":"` + RedactedValue + `"}],"user":{"fullName":"` + RedactedValue + `","userID":"1"}}`,
+		},
+		{
+			description: "Case-insensitive patterns",
+			body:        `{"PatientNarrative":"Pt found sitting.","rowVersion":"1"}`,
+			fields:      []string{"*narrative*"},
+			expected:    `{"PatientNarrative":"` + RedactedValue + `","rowVersion":"1"}`,
+		},
+		{
+			description: "Whole values",
+			body:        `{"patient":{"firstName":"Pat"},"vitals":[1,2],"narrative":null}`,
+			fields:      []string{"patient", "vitals", "narrative"},
+			expected:    `{"narrative":null,"patient":"` + RedactedValue + `","vitals":"` + RedactedValue + `"}`,
+		},
+		{
+			description: "Large numbers are kept",
+			body:        `{"id":12345678901234567890}`,
+			fields:      DefaultRedactedFields,
+			expected:    `{"id":12345678901234567890}`,
+		},
+		{
+			description: "Form",
+			body:        `grant_type=password&password=secret&username=jdoe`,
+			fields:      DefaultRedactedFields,
+			expected:    `grant_type=password&password=` + RedactedValue + `&username=` + RedactedValue,
+		},
+		{
+			description: "Other",
+			body:        `Not JSON, and not a form.`,
+			fields:      DefaultRedactedFields,
+			expected:    `Not JSON, and not a form.`,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := Redact([]byte(row.body), row.fields)
+			if actual != row.expected {
+				t.Errorf("Expected %s; got %s", row.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRedactJSONKeepingTypes(t *testing.T) {
+	actual, err := RedactJSONKeepingTypes([]byte(`{"fullName":"Jane Doe","patient":{"age":51},"vitals":[],"ssn":123}`), DefaultRedactedFields)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"fullName":"` + RedactedValue + `","patient":null,"ssn":null,"vitals":null}`
+	if string(actual) != expected {
+		t.Errorf("Expected %s; got %s", expected, actual)
+	}
+
+	_, err = RedactJSONKeepingTypes([]byte(`not JSON`), DefaultRedactedFields)
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
@@ -0,0 +1,466 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maintenance record statuses.
+const (
+	MaintenanceStatusOpen       = "Open"
+	MaintenanceStatusInProgress = "In Progress"
+	MaintenanceStatusCompleted  = "Completed"
+)
+
+// MaintenanceRecord is a work order for an apparatus or a piece of equipment.
+type MaintenanceRecord struct {
+	RecordID              string `json:"recordID,omitempty"` // Not used for creating records.
+	DepartmentApparatusID string `json:"departmentApparatusID"`
+	ItemID                string `json:"itemID"`     // The inventory item, for equipment that is not an apparatus.
+	ScheduleID            string `json:"scheduleID"` // The scheduled maintenance that this satisfies, if any.
+	WorkOrderNumber       string `json:"workOrderNumber"`
+	MaintenanceType       string `json:"maintenanceType"` // Such as "Oil Change" or "Pump Test".
+	Description           string `json:"description"`
+	Status                string `json:"status"` // One of the `MaintenanceStatus*` constants.
+	OpenedDateTime        string `json:"openedDateTime"`
+	CompletedDateTime     string `json:"completedDateTime"`
+	Mileage               string `json:"mileage"`
+	EngineHours           string `json:"engineHours"`
+	Cost                  string `json:"cost"`
+	PerformedBy           string `json:"performedBy"`
+	RowVersion            string `json:"rowVersion,omitempty"` // Not used for creating records.
+}
+
+type GetMaintenanceRecordsResponse struct {
+	TotalRows string               `json:"totalRows"`
+	Records   []*MaintenanceRecord `json:"records"`
+}
+
+type GetMaintenanceRecordResponse struct {
+	Record *MaintenanceRecord `json:"record"`
+}
+
+type PostMaintenanceRecordResponse struct {
+	RecordID string `json:"recordID"`
+}
+
+type PatchMaintenanceRecordRequest struct {
+	DepartmentApparatusID *string `json:"departmentApparatusID,omitempty"`
+	ItemID                *string `json:"itemID,omitempty"`
+	ScheduleID            *string `json:"scheduleID,omitempty"`
+	WorkOrderNumber       *string `json:"workOrderNumber,omitempty"`
+	MaintenanceType       *string `json:"maintenanceType,omitempty"`
+	Description           *string `json:"description,omitempty"`
+	Status                *string `json:"status,omitempty"`
+	OpenedDateTime        *string `json:"openedDateTime,omitempty"`
+	CompletedDateTime     *string `json:"completedDateTime,omitempty"`
+	Mileage               *string `json:"mileage,omitempty"`
+	EngineHours           *string `json:"engineHours,omitempty"`
+	Cost                  *string `json:"cost,omitempty"`
+	PerformedBy           *string `json:"performedBy,omitempty"`
+}
+
+type PatchMaintenanceRecordResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// MaintenanceSchedule is maintenance that recurs, such as an annual pump test.
+type MaintenanceSchedule struct {
+	ScheduleID            string `json:"scheduleID,omitempty"` // Not used for creating schedules.
+	DepartmentApparatusID string `json:"departmentApparatusID"`
+	ItemID                string `json:"itemID"`
+	MaintenanceType       string `json:"maintenanceType"`
+	Description           string `json:"description"`
+	IntervalDays          string `json:"intervalDays"`
+	LastCompletedDate     string `json:"lastCompletedDate"`
+	NextDueDate           string `json:"nextDueDate"` // If empty, then the last completed date plus the interval.
+	IsActive              string `json:"isActive"`
+	RowVersion            string `json:"rowVersion,omitempty"` // Not used for creating schedules.
+}
+
+type GetMaintenanceSchedulesResponse struct {
+	TotalRows string                 `json:"totalRows"`
+	Schedules []*MaintenanceSchedule `json:"schedules"`
+}
+
+type GetMaintenanceScheduleResponse struct {
+	Schedule *MaintenanceSchedule `json:"schedule"`
+}
+
+type PostMaintenanceScheduleResponse struct {
+	ScheduleID string `json:"scheduleID"`
+}
+
+type PatchMaintenanceScheduleRequest struct {
+	DepartmentApparatusID *string `json:"departmentApparatusID,omitempty"`
+	ItemID                *string `json:"itemID,omitempty"`
+	MaintenanceType       *string `json:"maintenanceType,omitempty"`
+	Description           *string `json:"description,omitempty"`
+	IntervalDays          *string `json:"intervalDays,omitempty"`
+	LastCompletedDate     *string `json:"lastCompletedDate,omitempty"`
+	NextDueDate           *string `json:"nextDueDate,omitempty"`
+	IsActive              *string `json:"isActive,omitempty"`
+}
+
+type PatchMaintenanceScheduleResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetMaintenanceRecords gets a page of maintenance records (work orders).
+func (c *Client) GetMaintenanceRecords(ctx context.Context, options map[string]string) (*GetMaintenanceRecordsResponse, error) {
+	// https://data.emergencyreporting.com/agencymaintenance/records[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencymaintenance/records"
+
+	var parsedResponse GetMaintenanceRecordsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the maintenance records: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllMaintenanceRecords gets every page of maintenance records.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllMaintenanceRecords(ctx context.Context, options map[string]string) ([]*MaintenanceRecord, error) {
+	var records []*MaintenanceRecord
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetMaintenanceRecords(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		records = append(records, response.Records...)
+		return len(response.Records), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetMaintenanceRecord gets a maintenance record.
+func (c *Client) GetMaintenanceRecord(ctx context.Context, recordID string) (*GetMaintenanceRecordResponse, error) {
+	// https://data.emergencyreporting.com/agencymaintenance/records/{recordID}
+
+	targetURL := "/agencymaintenance/records/" + url.PathEscape(recordID)
+
+	var parsedResponse GetMaintenanceRecordResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the maintenance record: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostMaintenanceRecord creates a maintenance record.
+func (c *Client) PostMaintenanceRecord(ctx context.Context, record MaintenanceRecord) (*PostMaintenanceRecordResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencymaintenance/records
+
+	targetURL := "/agencymaintenance/records"
+
+	jsonInput, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostMaintenanceRecordResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the maintenance record: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchMaintenanceRecord updates a maintenance record.
+func (c *Client) PatchMaintenanceRecord(ctx context.Context, recordID string, rowVersion string, payload PatchMaintenanceRecordRequest) (*PatchMaintenanceRecordResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencymaintenance/records/{recordID}
+
+	targetURL := "/agencymaintenance/records/" + url.PathEscape(recordID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchMaintenanceRecordResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the maintenance record: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetMaintenanceSchedules gets a page of scheduled maintenance.
+func (c *Client) GetMaintenanceSchedules(ctx context.Context, options map[string]string) (*GetMaintenanceSchedulesResponse, error) {
+	// https://data.emergencyreporting.com/agencymaintenance/schedules[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencymaintenance/schedules"
+
+	var parsedResponse GetMaintenanceSchedulesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the maintenance schedules: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllMaintenanceSchedules gets every page of scheduled maintenance.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllMaintenanceSchedules(ctx context.Context, options map[string]string) ([]*MaintenanceSchedule, error) {
+	var schedules []*MaintenanceSchedule
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetMaintenanceSchedules(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		schedules = append(schedules, response.Schedules...)
+		return len(response.Schedules), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetMaintenanceSchedule gets a maintenance schedule.
+func (c *Client) GetMaintenanceSchedule(ctx context.Context, scheduleID string) (*GetMaintenanceScheduleResponse, error) {
+	// https://data.emergencyreporting.com/agencymaintenance/schedules/{scheduleID}
+
+	targetURL := "/agencymaintenance/schedules/" + url.PathEscape(scheduleID)
+
+	var parsedResponse GetMaintenanceScheduleResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the maintenance schedule: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostMaintenanceSchedule creates a maintenance schedule.
+func (c *Client) PostMaintenanceSchedule(ctx context.Context, schedule MaintenanceSchedule) (*PostMaintenanceScheduleResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencymaintenance/schedules
+
+	targetURL := "/agencymaintenance/schedules"
+
+	jsonInput, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostMaintenanceScheduleResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the maintenance schedule: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchMaintenanceSchedule updates a maintenance schedule.
+func (c *Client) PatchMaintenanceSchedule(ctx context.Context, scheduleID string, rowVersion string, payload PatchMaintenanceScheduleRequest) (*PatchMaintenanceScheduleResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencymaintenance/schedules/{scheduleID}
+
+	targetURL := "/agencymaintenance/schedules/" + url.PathEscape(scheduleID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchMaintenanceScheduleResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the maintenance schedule: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// MaintenanceDueOptions are the options for `MaintenanceDue`.
+type MaintenanceDueOptions struct {
+	AsOf time.Time // The time to count from; if zero, then the current time is used.
+	Days int       // Maintenance that is due within this many days is included; overdue maintenance always is.
+}
+
+// MaintenanceDueItem is scheduled maintenance that is coming due (or is overdue).
+type MaintenanceDueItem struct {
+	DepartmentApparatusID   string `json:"departmentApparatusID"`
+	DepartmentApparatusName string `json:"departmentApparatusName"`
+	ItemID                  string `json:"itemID"`
+	ScheduleID              string `json:"scheduleID"`
+	MaintenanceType         string `json:"maintenanceType"`
+	LastCompletedDate       string `json:"lastCompletedDate"`
+	DueDate                 string `json:"dueDate"`
+	DaysRemaining           int    `json:"daysRemaining"` // Negative if it is overdue.
+}
+
+// MaintenanceDue returns the scheduled maintenance that is due within the given number of days (or is overdue),
+// sorted by due date.
+//
+// The last completion is the later of the schedule's `LastCompletedDate` and its completed records; if a record
+// is newer than the schedule, then the next due date is recomputed from the interval.  Inactive schedules and
+// schedules that have neither a due date nor a completion are skipped.  The apparatuses (which may be nil) are
+// only used for the names.  Dates without a time zone are in the location of `AsOf`, and maintenance is due at
+// the end of its due date.
+func MaintenanceDue(schedules []*MaintenanceSchedule, records []*MaintenanceRecord, apparatuses []*Apparatus, options MaintenanceDueOptions) []*MaintenanceDueItem {
+	asOf := options.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	location := asOf.Location()
+
+	apparatusesByID := map[string]*Apparatus{}
+	for _, apparatus := range apparatuses {
+		if apparatus != nil {
+			apparatusesByID[apparatus.DepartmentApparatusID] = apparatus
+		}
+	}
+
+	lastCompletedByScheduleID := map[string]time.Time{}
+	for _, record := range records {
+		if record == nil || record.ScheduleID == "" || record.Status != MaintenanceStatusCompleted {
+			continue
+		}
+		completed, ok := parseDateTime(record.CompletedDateTime, location)
+		if !ok {
+			continue
+		}
+		if completed.After(lastCompletedByScheduleID[record.ScheduleID]) {
+			lastCompletedByScheduleID[record.ScheduleID] = completed
+		}
+	}
+
+	type dueItem struct {
+		result *MaintenanceDueItem
+		due    time.Time
+	}
+	var dues []dueItem
+	for _, schedule := range schedules {
+		if schedule == nil || schedule.IsActive == "0" {
+			continue
+		}
+
+		lastCompleted, hasLastCompleted := parseDateTime(schedule.LastCompletedDate, location)
+		recordIsNewer := false
+		if completed, ok := lastCompletedByScheduleID[schedule.ScheduleID]; ok && (!hasLastCompleted || completed.After(lastCompleted)) {
+			lastCompleted, hasLastCompleted = completed, true
+			recordIsNewer = true
+		}
+
+		dueDate, hasDueDate := parseDateTime(schedule.NextDueDate, location)
+		intervalDays, err := strconv.Atoi(strings.TrimSpace(schedule.IntervalDays))
+		if err == nil && intervalDays > 0 && hasLastCompleted && (!hasDueDate || recordIsNewer) {
+			dueDate, hasDueDate = lastCompleted.AddDate(0, 0, intervalDays), true
+		}
+		if !hasDueDate {
+			continue
+		}
+		dueDate = time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, location)
+
+		remaining := dueDate.AddDate(0, 0, 1).Sub(asOf)
+		if remaining > time.Duration(options.Days)*24*time.Hour {
+			continue
+		}
+
+		result := &MaintenanceDueItem{
+			DepartmentApparatusID: schedule.DepartmentApparatusID,
+			ItemID:                schedule.ItemID,
+			ScheduleID:            schedule.ScheduleID,
+			MaintenanceType:       schedule.MaintenanceType,
+			DueDate:               dueDate.Format("2006-01-02"),
+			DaysRemaining:         daysRemaining(remaining),
+		}
+		if hasLastCompleted {
+			result.LastCompletedDate = lastCompleted.Format("2006-01-02")
+		}
+		if apparatus, ok := apparatusesByID[schedule.DepartmentApparatusID]; ok {
+			result.DepartmentApparatusName = apparatus.DepartmentApparatusName
+		}
+		dues = append(dues, dueItem{result: result, due: dueDate})
+	}
+
+	sort.SliceStable(dues, func(i, j int) bool {
+		if !dues[i].due.Equal(dues[j].due) {
+			return dues[i].due.Before(dues[j].due)
+		}
+		return dues[i].result.DepartmentApparatusName < dues[j].result.DepartmentApparatusName
+	})
+	results := []*MaintenanceDueItem{}
+	for _, item := range dues {
+		results = append(results, item.result)
+	}
+	return results
+}
+
+// GetMaintenanceDue gets the scheduled maintenance (along with the completed records and the apparatuses) and
+// returns what is coming due.
+//
+// See `MaintenanceDue`.
+func (c *Client) GetMaintenanceDue(ctx context.Context, options MaintenanceDueOptions) ([]*MaintenanceDueItem, error) {
+	schedules, err := c.GetAllMaintenanceSchedules(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	records, err := c.GetAllMaintenanceRecords(ctx, map[string]string{"filter": fmt.Sprintf("status eq '%s'", MaintenanceStatusCompleted)})
+	if err != nil {
+		return nil, err
+	}
+	apparatuses, err := c.GetAllApparatuses(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return MaintenanceDue(schedules, records, apparatuses, options), nil
+}
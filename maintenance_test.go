@@ -0,0 +1,76 @@
+package emergencyreporting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceDue(t *testing.T) {
+	asOf := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	schedules := []*MaintenanceSchedule{
+		{ScheduleID: "1", DepartmentApparatusID: "6", MaintenanceType: "Pump Test", NextDueDate: "2026-03-20"},
+		{ScheduleID: "2", DepartmentApparatusID: "5", MaintenanceType: "Oil Change", IntervalDays: "30", LastCompletedDate: "2026-02-18", NextDueDate: "2026-03-20"},
+		{ScheduleID: "3", DepartmentApparatusID: "6", MaintenanceType: "Ladder Test", IntervalDays: "30", LastCompletedDate: "2026-02-08"},
+		{ScheduleID: "4", DepartmentApparatusID: "5", MaintenanceType: "Tires", IntervalDays: "30", LastCompletedDate: "2026-01-30", NextDueDate: "2026-03-01"},
+		{ScheduleID: "5", DepartmentApparatusID: "5", MaintenanceType: "Retired", NextDueDate: "2026-03-01", IsActive: "0"},
+		{ScheduleID: "6", DepartmentApparatusID: "5", MaintenanceType: "Unscheduled"},
+		nil,
+	}
+	records := []*MaintenanceRecord{
+		{RecordID: "10", ScheduleID: "4", Status: MaintenanceStatusCompleted, CompletedDateTime: "2026-03-05 10:00:00"},
+		{RecordID: "11", ScheduleID: "4", Status: MaintenanceStatusCompleted, CompletedDateTime: "2026-02-01 10:00:00"},
+		{RecordID: "12", ScheduleID: "2", Status: MaintenanceStatusOpen, OpenedDateTime: "2026-03-14 10:00:00"},
+		{RecordID: "13", Status: MaintenanceStatusCompleted, CompletedDateTime: "2026-03-14 10:00:00"},
+		nil,
+	}
+	apparatuses := []*Apparatus{
+		{DepartmentApparatusID: "5", DepartmentApparatusName: "Engine 1"},
+		{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1"},
+	}
+
+	rows := []struct {
+		description string
+		days        int
+		expected    []MaintenanceDueItem
+	}{
+		{
+			description: "Overdue",
+			expected: []MaintenanceDueItem{
+				{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1", ScheduleID: "3", MaintenanceType: "Ladder Test", LastCompletedDate: "2026-02-08", DueDate: "2026-03-10", DaysRemaining: -5},
+			},
+		},
+		{
+			description: "Within a week",
+			days:        7,
+			expected: []MaintenanceDueItem{
+				{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1", ScheduleID: "3", MaintenanceType: "Ladder Test", LastCompletedDate: "2026-02-08", DueDate: "2026-03-10", DaysRemaining: -5},
+				{DepartmentApparatusID: "5", DepartmentApparatusName: "Engine 1", ScheduleID: "2", MaintenanceType: "Oil Change", LastCompletedDate: "2026-02-18", DueDate: "2026-03-20", DaysRemaining: 5},
+				{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1", ScheduleID: "1", MaintenanceType: "Pump Test", DueDate: "2026-03-20", DaysRemaining: 5},
+			},
+		},
+		{
+			description: "A newer record moves the due date",
+			days:        30,
+			expected: []MaintenanceDueItem{
+				{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1", ScheduleID: "3", MaintenanceType: "Ladder Test", LastCompletedDate: "2026-02-08", DueDate: "2026-03-10", DaysRemaining: -5},
+				{DepartmentApparatusID: "5", DepartmentApparatusName: "Engine 1", ScheduleID: "2", MaintenanceType: "Oil Change", LastCompletedDate: "2026-02-18", DueDate: "2026-03-20", DaysRemaining: 5},
+				{DepartmentApparatusID: "6", DepartmentApparatusName: "Ladder 1", ScheduleID: "1", MaintenanceType: "Pump Test", DueDate: "2026-03-20", DaysRemaining: 5},
+				{DepartmentApparatusID: "5", DepartmentApparatusName: "Engine 1", ScheduleID: "4", MaintenanceType: "Tires", LastCompletedDate: "2026-03-05", DueDate: "2026-04-04", DaysRemaining: 20},
+			},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			results := MaintenanceDue(schedules, records, apparatuses, MaintenanceDueOptions{AsOf: asOf, Days: row.days})
+			if len(results) != len(row.expected) {
+				t.Fatalf("Expected %d items; got %d", len(row.expected), len(results))
+			}
+			for index, result := range results {
+				if *result != row.expected[index] {
+					t.Errorf("Item %d: expected %+v; got %+v", index, row.expected[index], *result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package emergencyreporting
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHandler sends a request and returns its response.
+type RequestHandler func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps a request handler with some behavior of its own, such as logging, retries, or metrics.
+//
+// A middleware calls `next` to send the request on (or doesn't, to short-circuit it).
+type Middleware func(next RequestHandler) RequestHandler
+
+// Use adds middleware to the client.
+//
+// The middleware applies to every request, including the token requests.  The first middleware
+// added is the outermost one; it sees the request first and the response last.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// do sends the request through the middleware chain and then the HTTP client.
+func (c *Client) do(request *http.Request) (*http.Response, error) {
+	c.init()
+
+	handler := RequestHandler(c.HTTPClient.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+	return c.instrument(handler)(request)
+}
+
+// BeforeRequest returns a middleware that calls the hook before each request is sent.
+//
+// The hook may modify the request (to add headers, for example).  If the hook returns
+// an error, then the request is not sent and the error is returned.
+func BeforeRequest(hook func(request *http.Request) error) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(request *http.Request) (*http.Response, error) {
+			err := hook(request)
+			if err != nil {
+				return nil, err
+			}
+			return next(request)
+		}
+	}
+}
+
+// AfterResponse returns a middleware that calls the hook after each request has been sent.
+//
+// The hook gets the response and error from the request (only one of which is set), and
+// it returns the response and error to pass back.
+func AfterResponse(hook func(request *http.Request, response *http.Response, err error) (*http.Response, error)) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(request *http.Request) (*http.Response, error) {
+			response, err := next(request)
+			return hook(request, response, err)
+		}
+	}
+}
+
+// Retry returns a middleware that retries idempotent requests that fail with a network
+// error or a 429, 502, 503, or 504 status code.
+//
+// The delay doubles after each attempt.  Requests that cannot be safely repeated (such as
+// POST and PATCH) are never retried.
+func Retry(attempts int, delay time.Duration) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(request *http.Request) (*http.Response, error) {
+			switch request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+			default:
+				return next(request)
+			}
+
+			wait := delay
+			for attempt := 1; ; attempt++ {
+				response, err := next(request)
+				if attempt >= attempts || !shouldRetry(response, err) {
+					return response, err
+				}
+				if response != nil {
+					response.Body.Close()
+				}
+
+				// Each attempt needs a fresh copy of the body.
+				if request.GetBody != nil {
+					body, err := request.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					request.Body = body
+				}
+
+				select {
+				case <-request.Context().Done():
+					return nil, request.Context().Err()
+				case <-time.After(wait):
+				}
+				wait *= 2
+				observeRetry(request.Context())
+			}
+		}
+	}
+}
+
+// shouldRetry returns whether the result of a request is worth retrying.
+func shouldRetry(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
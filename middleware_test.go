@@ -0,0 +1,187 @@
+package emergencyreporting
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc is an `http.RoundTripper` made from a function.
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+// RoundTrip calls the function.
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// newTestResponse returns a response with the status code and a JSON body.
+func newTestResponse(request *http.Request, statusCode int) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+		Request:    request,
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	client := &Client{
+		HTTPClient: &http.Client{
+			Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, "transport "+request.Header.Get("X-Test"))
+				return newTestResponse(request, http.StatusOK), nil
+			}),
+		},
+	}
+	wrap := func(name string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				response, err := next(request)
+				calls = append(calls, name+" after")
+				return response, err
+			}
+		}
+	}
+	client.Use(wrap("first"), wrap("second"))
+	client.Use(
+		BeforeRequest(func(request *http.Request) error {
+			calls = append(calls, "hook before")
+			request.Header.Set("X-Test", "set")
+			return nil
+		}),
+		AfterResponse(func(request *http.Request, response *http.Response, err error) (*http.Response, error) {
+			calls = append(calls, "hook after")
+			return response, err
+		}),
+	)
+
+	_, err := client.RawOperation(context.Background(), http.MethodGet, "http://example.test/agencyincidents/incidents", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"first before", "second before", "hook before", "transport set", "hook after", "second after", "first after"}
+	if strings.Join(calls, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("Expected %v; got %v", expected, calls)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	sent := false
+	client := &Client{
+		HTTPClient: &http.Client{
+			Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				sent = true
+				return newTestResponse(request, http.StatusOK), nil
+			}),
+		},
+	}
+	hookErr := errors.New("not allowed")
+	client.Use(BeforeRequest(func(request *http.Request) error {
+		return hookErr
+	}))
+
+	_, err := client.RawOperation(context.Background(), http.MethodGet, "http://example.test/agencyincidents/incidents", nil, nil, nil)
+	if !errors.Is(err, hookErr) {
+		t.Errorf("Expected the hook's error; got %v", err)
+	}
+	if sent {
+		t.Errorf("The request should not have been sent")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	transportErr := errors.New("connection reset")
+
+	rows := []struct {
+		description string
+		method      string
+		results     []int // The status code of each attempt; 0 is a transport error.
+		attempts    int
+		success     bool
+	}{
+		{
+			description: "Success",
+			method:      http.MethodPut,
+			results:     []int{http.StatusOK},
+			attempts:    1,
+			success:     true,
+		},
+		{
+			description: "Service unavailable",
+			method:      http.MethodPut,
+			results:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			attempts:    3,
+			success:     true,
+		},
+		{
+			description: "Transport error",
+			method:      http.MethodPut,
+			results:     []int{0, http.StatusOK},
+			attempts:    2,
+			success:     true,
+		},
+		{
+			description: "Gives up",
+			method:      http.MethodPut,
+			results:     []int{http.StatusServiceUnavailable, 0, http.StatusGatewayTimeout, http.StatusOK},
+			attempts:    3,
+		},
+		{
+			description: "Not retryable",
+			method:      http.MethodPut,
+			results:     []int{http.StatusInternalServerError, http.StatusOK},
+			attempts:    1,
+		},
+		{
+			description: "Not idempotent",
+			method:      http.MethodPost,
+			results:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			attempts:    1,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			var bodies []string
+			client := &Client{
+				HTTPClient: &http.Client{
+					Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+						contents, err := ioutil.ReadAll(request.Body)
+						if err != nil {
+							t.Fatalf("Could not read the request body: %v", err)
+						}
+						bodies = append(bodies, string(contents))
+						statusCode := row.results[len(bodies)-1]
+						if statusCode == 0 {
+							return nil, transportErr
+						}
+						return newTestResponse(request, statusCode), nil
+					}),
+				},
+			}
+			client.Use(Retry(3, time.Millisecond))
+
+			_, err := client.RawOperation(context.Background(), row.method, "http://example.test/agencyincidents/exposures/1/location", nil, nil, []byte(`{"city":"Springfield"}`))
+			if row.success && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !row.success && err == nil {
+				t.Errorf("Expected an error")
+			}
+			if len(bodies) != row.attempts {
+				t.Fatalf("Expected %d attempts; got %d", row.attempts, len(bodies))
+			}
+			for index, body := range bodies {
+				if body != `{"city":"Springfield"}` {
+					t.Errorf("Attempt %d: wrong body: %q", index+1, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,803 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// NEMSIS3Version is the version of the NEMSIS 3 standard that the data sets are written for.
+//
+// The incident disposition group (eDisposition.27 through eDisposition.30) is only in 3.5, so the data
+// sets are not valid against the earlier schemas.
+const NEMSIS3Version = "3.5.0"
+
+// NEMSIS 3 values that are shared between elements.
+const (
+	// NEMSIS3NotRecorded is the "NV" (not value) code for a required element that has no value.
+	NEMSIS3NotRecorded = "7701003"
+	// NEMSIS3No is the code for "No".
+	NEMSIS3No = "9923001"
+	// NEMSIS3Yes is the code for "Yes".
+	NEMSIS3Yes = "9923003"
+)
+
+// NEMSIS3Namespace is the XML namespace of a NEMSIS 3 EMS data set.
+const NEMSIS3Namespace = "http://www.nemsis.org"
+
+// NEMSIS3Options controls how patient care reports are mapped to NEMSIS 3.
+type NEMSIS3Options struct {
+	AgencyStateID   string         // The agency's unique ID from the state EMS office (dAgency.01).  This has no Emergency Reporting equivalent.
+	AgencyNumber    string         // The agency's number from the state EMS office (dAgency.02).
+	AgencyName      string         // The agency's name.
+	State           string         // The two-digit ANSI code of the state that the data is submitted to (dAgency.04).
+	SoftwareCreator string         // The company that wrote the software that created the data (eRecord.02).
+	SoftwareName    string         // The name of the software (eRecord.03).
+	SoftwareVersion string         // The version of the software (eRecord.04).
+	UnitCapability  string         // The NEMSIS code for the units' transport and equipment capability (eResponse.07).  This has no Emergency Reporting equivalent.
+	UnitLevelOfCare string         // The NEMSIS code for the units' level of care (eResponse.15).  This has no Emergency Reporting equivalent.
+	Location        *time.Location // The time zone that Emergency Reporting date/times are in.  If empty, UTC is assumed.
+}
+
+// NEMSIS3Value is the value of a NEMSIS 3 element.
+//
+// Required elements without a value are sent as nil with an "NV" code instead.
+type NEMSIS3Value struct {
+	Value string `xml:",chardata"`
+	Nil   string `xml:"xsi:nil,attr,omitempty"`
+	NV    string `xml:"NV,attr,omitempty"`
+}
+
+// NEMSIS3DataSet is a NEMSIS 3 EMS data set, as submitted to a state.
+type NEMSIS3DataSet struct {
+	XMLName  xml.Name      `xml:"EMSDataSet"`
+	Xmlns    string        `xml:"xmlns,attr"`
+	XmlnsXSI string        `xml:"xmlns:xsi,attr"`
+	Header   NEMSIS3Header `xml:"Header"`
+}
+
+// NEMSIS3Header is the agency and its patient care reports.
+type NEMSIS3Header struct {
+	DemographicGroup   NEMSIS3DemographicGroup     `xml:"DemographicGroup"`
+	PatientCareReports []*NEMSIS3PatientCareReport `xml:"PatientCareReport"`
+}
+
+// NEMSIS3DemographicGroup identifies the agency.
+type NEMSIS3DemographicGroup struct {
+	AgencyStateID *NEMSIS3Value `xml:"dAgency.01"`
+	AgencyNumber  *NEMSIS3Value `xml:"dAgency.02"`
+	State         *NEMSIS3Value `xml:"dAgency.04"`
+}
+
+// NEMSIS3PatientCareReport is a single patient care report.
+//
+// The schema is a strict sequence, so the sections are in the schema's order; the optional sections that
+// Emergency Reporting has nothing for (eLabs, eAirway, eDevice, and eCustomResults) are left out.  The
+// required sections are always written, with "Not Recorded" for the required elements that have no value.
+type NEMSIS3PatientCareReport struct {
+	Record      NEMSIS3Record      `xml:"eRecord"`
+	Response    NEMSIS3Response    `xml:"eResponse"`
+	Dispatch    NEMSIS3Dispatch    `xml:"eDispatch"`
+	Crew        NEMSIS3Crew        `xml:"eCrew"`
+	Times       NEMSIS3Times       `xml:"eTimes"`
+	Patient     NEMSIS3Patient     `xml:"ePatient"`
+	Payment     NEMSIS3Payment     `xml:"ePayment"`
+	Scene       NEMSIS3Scene       `xml:"eScene"`
+	Situation   NEMSIS3Situation   `xml:"eSituation"`
+	Injury      NEMSIS3Injury      `xml:"eInjury"`
+	Arrest      NEMSIS3Arrest      `xml:"eArrest"`
+	History     NEMSIS3History     `xml:"eHistory"`
+	Narrative   NEMSIS3Narrative   `xml:"eNarrative"`
+	Vitals      NEMSIS3Vitals      `xml:"eVitals"`
+	Exam        NEMSIS3Exam        `xml:"eExam"`
+	Protocols   NEMSIS3Protocols   `xml:"eProtocols"`
+	Medications NEMSIS3Medications `xml:"eMedications"`
+	Procedures  NEMSIS3Procedures  `xml:"eProcedures"`
+	Disposition NEMSIS3Disposition `xml:"eDisposition"`
+	Outcome     NEMSIS3Outcome     `xml:"eOutcome"`
+	Other       NEMSIS3Other       `xml:"eOther"`
+}
+
+// NEMSIS3Record is the "eRecord" section.
+type NEMSIS3Record struct {
+	PCRNumber       *NEMSIS3Value `xml:"eRecord.01"`
+	SoftwareCreator *NEMSIS3Value `xml:"eRecord.SoftwareApplicationGroup>eRecord.02"`
+	SoftwareName    *NEMSIS3Value `xml:"eRecord.SoftwareApplicationGroup>eRecord.03"`
+	SoftwareVersion *NEMSIS3Value `xml:"eRecord.SoftwareApplicationGroup>eRecord.04"`
+}
+
+// NEMSIS3Response is the "eResponse" section.
+type NEMSIS3Response struct {
+	AgencyNumber          *NEMSIS3Value   `xml:"eResponse.AgencyGroup>eResponse.01"`
+	AgencyName            *NEMSIS3Value   `xml:"eResponse.AgencyGroup>eResponse.02,omitempty"`
+	IncidentNumber        *NEMSIS3Value   `xml:"eResponse.03"`
+	ResponseNumber        *NEMSIS3Value   `xml:"eResponse.04"`
+	TypeOfService         *NEMSIS3Value   `xml:"eResponse.ServiceGroup>eResponse.05"`
+	UnitCapability        *NEMSIS3Value   `xml:"eResponse.07"`
+	DispatchDelays        []*NEMSIS3Value `xml:"eResponse.08"`
+	ResponseDelays        []*NEMSIS3Value `xml:"eResponse.09"`
+	SceneDelays           []*NEMSIS3Value `xml:"eResponse.10"`
+	TransportDelays       []*NEMSIS3Value `xml:"eResponse.11"`
+	TurnAroundDelays      []*NEMSIS3Value `xml:"eResponse.12"`
+	VehicleNumber         *NEMSIS3Value   `xml:"eResponse.13"`
+	CallSign              *NEMSIS3Value   `xml:"eResponse.14"`
+	LevelOfCare           *NEMSIS3Value   `xml:"eResponse.15"`
+	ResponseMode          *NEMSIS3Value   `xml:"eResponse.23"`
+	ResponseModeModifiers []*NEMSIS3Value `xml:"eResponse.24"`
+}
+
+// NEMSIS3Dispatch is the "eDispatch" section.
+type NEMSIS3Dispatch struct {
+	DispatchReason *NEMSIS3Value `xml:"eDispatch.01"`
+	EMDPerformed   *NEMSIS3Value `xml:"eDispatch.02"`
+}
+
+// NEMSIS3Crew is the "eCrew" section.
+type NEMSIS3Crew struct {
+	CrewGroups []*NEMSIS3CrewGroup `xml:"eCrew.CrewGroup"`
+}
+
+// NEMSIS3CrewGroup is a single crew member.
+type NEMSIS3CrewGroup struct {
+	CrewMemberID *NEMSIS3Value `xml:"eCrew.01"`
+	Level        *NEMSIS3Value `xml:"eCrew.02"`
+	ResponseRole *NEMSIS3Value `xml:"eCrew.03"`
+}
+
+// NEMSIS3Times is the "eTimes" section.
+type NEMSIS3Times struct {
+	PSAPCall              *NEMSIS3Value `xml:"eTimes.01"`
+	UnitNotified          *NEMSIS3Value `xml:"eTimes.03"`
+	UnitEnroute           *NEMSIS3Value `xml:"eTimes.05"`
+	UnitArrivedOnScene    *NEMSIS3Value `xml:"eTimes.06"`
+	ArrivedAtPatient      *NEMSIS3Value `xml:"eTimes.07"`
+	UnitLeftScene         *NEMSIS3Value `xml:"eTimes.09"`
+	AtDestination         *NEMSIS3Value `xml:"eTimes.11"`
+	TransferOfPatientCare *NEMSIS3Value `xml:"eTimes.12"`
+	UnitBackInService     *NEMSIS3Value `xml:"eTimes.13"`
+}
+
+// NEMSIS3Patient is the "ePatient" section.
+type NEMSIS3Patient struct {
+	LastName      *NEMSIS3Value `xml:"ePatient.PatientNameGroup>ePatient.02"`
+	FirstName     *NEMSIS3Value `xml:"ePatient.PatientNameGroup>ePatient.03"`
+	MiddleName    *NEMSIS3Value `xml:"ePatient.PatientNameGroup>ePatient.04,omitempty"`
+	StreetAddress *NEMSIS3Value `xml:"ePatient.05,omitempty"`
+	City          *NEMSIS3Value `xml:"ePatient.06,omitempty"`
+	County        *NEMSIS3Value `xml:"ePatient.07,omitempty"`
+	State         *NEMSIS3Value `xml:"ePatient.08,omitempty"`
+	ZipCode       *NEMSIS3Value `xml:"ePatient.09"`
+	Gender        *NEMSIS3Value `xml:"ePatient.13"`
+	Race          *NEMSIS3Value `xml:"ePatient.14"`
+	Age           *NEMSIS3Value `xml:"ePatient.AgeGroup>ePatient.15"`
+	AgeUnits      *NEMSIS3Value `xml:"ePatient.AgeGroup>ePatient.16"`
+	DateOfBirth   *NEMSIS3Value `xml:"ePatient.17"`
+	Phone         *NEMSIS3Value `xml:"ePatient.18,omitempty"`
+}
+
+// NEMSIS3Payment is the "ePayment" section.
+type NEMSIS3Payment struct {
+	PrimaryMethodOfPayment *NEMSIS3Value `xml:"ePayment.01"`
+	CMSServiceLevel        *NEMSIS3Value `xml:"ePayment.50"`
+}
+
+// NEMSIS3Scene is the "eScene" section.
+type NEMSIS3Scene struct {
+	FirstUnitOnScene     *NEMSIS3Value `xml:"eScene.01"`
+	NumberOfPatients     *NEMSIS3Value `xml:"eScene.06"`
+	MassCasualtyIncident *NEMSIS3Value `xml:"eScene.07"`
+	TriageClassification *NEMSIS3Value `xml:"eScene.08"`
+	LocationType         *NEMSIS3Value `xml:"eScene.09"`
+	State                *NEMSIS3Value `xml:"eScene.18"`
+	ZipCode              *NEMSIS3Value `xml:"eScene.19"`
+	County               *NEMSIS3Value `xml:"eScene.21"`
+}
+
+// NEMSIS3Situation is the "eSituation" section.
+type NEMSIS3Situation struct {
+	SymptomOnset         *NEMSIS3Value   `xml:"eSituation.01"`
+	PossibleInjury       *NEMSIS3Value   `xml:"eSituation.02"`
+	ComplaintLocation    *NEMSIS3Value   `xml:"eSituation.07"`
+	ComplaintOrganSystem []*NEMSIS3Value `xml:"eSituation.08"`
+	PrimarySymptom       *NEMSIS3Value   `xml:"eSituation.09"`
+	OtherSymptoms        []*NEMSIS3Value `xml:"eSituation.10"`
+	PrimaryImpression    *NEMSIS3Value   `xml:"eSituation.11"`
+	SecondaryImpressions []*NEMSIS3Value `xml:"eSituation.12"`
+	InitialAcuity        *NEMSIS3Value   `xml:"eSituation.13"`
+}
+
+// NEMSIS3Injury is the "eInjury" section.
+type NEMSIS3Injury struct {
+	CauseOfInjury        []*NEMSIS3Value `xml:"eInjury.01"`
+	TraumaCenterCriteria []*NEMSIS3Value `xml:"eInjury.03"`
+	InjuryRiskFactors    []*NEMSIS3Value `xml:"eInjury.04"`
+}
+
+// NEMSIS3Arrest is the "eArrest" section.
+//
+// Emergency Reporting has no cardiac arrest details, so everything is "Not Recorded".
+type NEMSIS3Arrest struct {
+	CardiacArrest          *NEMSIS3Value   `xml:"eArrest.01"`
+	Etiology               *NEMSIS3Value   `xml:"eArrest.02"`
+	ResuscitationAttempted []*NEMSIS3Value `xml:"eArrest.03"`
+	Witnessed              []*NEMSIS3Value `xml:"eArrest.04"`
+	CPRPriorToEMS          *NEMSIS3Value   `xml:"eArrest.05"`
+	AEDPriorToEMS          *NEMSIS3Value   `xml:"eArrest.07"`
+	TypeOfCPR              []*NEMSIS3Value `xml:"eArrest.09"`
+	FirstMonitoredRhythm   *NEMSIS3Value   `xml:"eArrest.11"`
+	ROSC                   []*NEMSIS3Value `xml:"eArrest.12"`
+	ArrestDateTime         *NEMSIS3Value   `xml:"eArrest.14"`
+	ResuscitationStopped   *NEMSIS3Value   `xml:"eArrest.15"`
+	ReasonStopped          *NEMSIS3Value   `xml:"eArrest.16"`
+	RhythmAtDestination    []*NEMSIS3Value `xml:"eArrest.17"`
+	EndOfArrestEvent       *NEMSIS3Value   `xml:"eArrest.18"`
+}
+
+// NEMSIS3History is the "eHistory" section.
+type NEMSIS3History struct {
+	BarriersToCare []*NEMSIS3Value `xml:"eHistory.01"`
+	AlcoholOrDrugs []*NEMSIS3Value `xml:"eHistory.17"`
+}
+
+// NEMSIS3Narrative is the "eNarrative" section.
+type NEMSIS3Narrative struct {
+	Narrative *NEMSIS3Value `xml:"eNarrative.01"`
+}
+
+// NEMSIS3Vitals is the "eVitals" section.
+type NEMSIS3Vitals struct {
+	VitalGroups []*NEMSIS3VitalGroup `xml:"eVitals.VitalGroup"`
+}
+
+// NEMSIS3VitalGroup is a single set of vital signs.
+type NEMSIS3VitalGroup struct {
+	DateTime         *NEMSIS3Value   `xml:"eVitals.01"`
+	PriorToEMSCare   *NEMSIS3Value   `xml:"eVitals.02"`
+	CardiacRhythm    []*NEMSIS3Value `xml:"eVitals.CardiacRhythmGroup>eVitals.03"`
+	SystolicBP       *NEMSIS3Value   `xml:"eVitals.BloodPressureGroup>eVitals.06"`
+	DiastolicBP      *NEMSIS3Value   `xml:"eVitals.BloodPressureGroup>eVitals.07,omitempty"`
+	HeartRate        *NEMSIS3Value   `xml:"eVitals.HeartRateGroup>eVitals.10"`
+	PulseOximetry    *NEMSIS3Value   `xml:"eVitals.12"`
+	RespiratoryRate  *NEMSIS3Value   `xml:"eVitals.14"`
+	BloodGlucose     *NEMSIS3Value   `xml:"eVitals.18"`
+	GCSEye           *NEMSIS3Value   `xml:"eVitals.GlasgowScoreGroup>eVitals.19"`
+	GCSVerbal        *NEMSIS3Value   `xml:"eVitals.GlasgowScoreGroup>eVitals.20"`
+	GCSMotor         *NEMSIS3Value   `xml:"eVitals.GlasgowScoreGroup>eVitals.21"`
+	GCSQualifier     []*NEMSIS3Value `xml:"eVitals.GlasgowScoreGroup>eVitals.22"`
+	GCSTotal         *NEMSIS3Value   `xml:"eVitals.GlasgowScoreGroup>eVitals.23,omitempty"`
+	TemperatureC     *NEMSIS3Value   `xml:"eVitals.TemperatureGroup>eVitals.24,omitempty"`
+	Responsiveness   *NEMSIS3Value   `xml:"eVitals.26"`
+	PainScale        *NEMSIS3Value   `xml:"eVitals.PainScaleGroup>eVitals.27"`
+	StrokeScaleScore *NEMSIS3Value   `xml:"eVitals.StrokeScaleGroup>eVitals.29"`
+	StrokeScaleType  *NEMSIS3Value   `xml:"eVitals.StrokeScaleGroup>eVitals.30"`
+}
+
+// NEMSIS3Exam is the "eExam" section.
+//
+// Emergency Reporting has no exam findings, and none of them are required, so this is always empty.
+type NEMSIS3Exam struct{}
+
+// NEMSIS3Protocols is the "eProtocols" section.
+type NEMSIS3Protocols struct {
+	ProtocolGroups []*NEMSIS3ProtocolGroup `xml:"eProtocols.ProtocolGroup"`
+}
+
+// NEMSIS3ProtocolGroup is a single protocol that was used.
+type NEMSIS3ProtocolGroup struct {
+	Protocol *NEMSIS3Value `xml:"eProtocols.01"`
+}
+
+// NEMSIS3Medications is the "eMedications" section.
+type NEMSIS3Medications struct {
+	MedicationGroups []*NEMSIS3MedicationGroup `xml:"eMedications.MedicationGroup"`
+}
+
+// NEMSIS3MedicationGroup is a single medication.
+type NEMSIS3MedicationGroup struct {
+	DateTime       *NEMSIS3Value   `xml:"eMedications.01"`
+	PriorToEMSCare *NEMSIS3Value   `xml:"eMedications.02"`
+	Medication     *NEMSIS3Value   `xml:"eMedications.03"`
+	Route          *NEMSIS3Value   `xml:"eMedications.04"`
+	Dose           *NEMSIS3Value   `xml:"eMedications.DosageGroup>eMedications.05"`
+	DoseUnits      *NEMSIS3Value   `xml:"eMedications.DosageGroup>eMedications.06"`
+	Response       *NEMSIS3Value   `xml:"eMedications.07"`
+	Complications  []*NEMSIS3Value `xml:"eMedications.08"`
+	CrewMemberID   *NEMSIS3Value   `xml:"eMedications.09,omitempty"`
+	GivenByRole    *NEMSIS3Value   `xml:"eMedications.10"`
+}
+
+// NEMSIS3Procedures is the "eProcedures" section.
+type NEMSIS3Procedures struct {
+	ProcedureGroups []*NEMSIS3ProcedureGroup `xml:"eProcedures.ProcedureGroup"`
+}
+
+// NEMSIS3ProcedureGroup is a single procedure.
+type NEMSIS3ProcedureGroup struct {
+	DateTime        *NEMSIS3Value   `xml:"eProcedures.01"`
+	PriorToEMSCare  *NEMSIS3Value   `xml:"eProcedures.02"`
+	Procedure       *NEMSIS3Value   `xml:"eProcedures.03"`
+	Attempts        *NEMSIS3Value   `xml:"eProcedures.05"`
+	Successful      *NEMSIS3Value   `xml:"eProcedures.06"`
+	Complications   []*NEMSIS3Value `xml:"eProcedures.07"`
+	Response        *NEMSIS3Value   `xml:"eProcedures.08"`
+	CrewMemberID    *NEMSIS3Value   `xml:"eProcedures.09,omitempty"`
+	PerformedByRole *NEMSIS3Value   `xml:"eProcedures.10"`
+}
+
+// NEMSIS3Disposition is the "eDisposition" section.
+type NEMSIS3Disposition struct {
+	DestinationName        *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.01,omitempty"`
+	DestinationCode        *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.02,omitempty"`
+	DestinationAddress     *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.03,omitempty"`
+	DestinationCity        *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.04,omitempty"`
+	DestinationState       *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.05,omitempty"`
+	DestinationCounty      *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.06,omitempty"`
+	DestinationZipCode     *NEMSIS3Value   `xml:"eDisposition.DestinationGroup>eDisposition.07,omitempty"`
+	TransportMethod        *NEMSIS3Value   `xml:"eDisposition.16"`
+	TransportModeFromScene *NEMSIS3Value   `xml:"eDisposition.17"`
+	TransportModifiers     []*NEMSIS3Value `xml:"eDisposition.18"`
+	FinalAcuity            *NEMSIS3Value   `xml:"eDisposition.19"`
+	ReasonForDestination   []*NEMSIS3Value `xml:"eDisposition.20"`
+	DestinationType        *NEMSIS3Value   `xml:"eDisposition.21"`
+	InPatientDestination   *NEMSIS3Value   `xml:"eDisposition.22"`
+	HospitalCapability     []*NEMSIS3Value `xml:"eDisposition.23"`
+	PreArrivalAlert        *NEMSIS3Value   `xml:"eDisposition.24"`
+	PreArrivalAlertTime    *NEMSIS3Value   `xml:"eDisposition.25"`
+	UnitDisposition        *NEMSIS3Value   `xml:"eDisposition.IncidentDispositionGroup>eDisposition.27"`
+	PatientEvaluationCare  *NEMSIS3Value   `xml:"eDisposition.IncidentDispositionGroup>eDisposition.28"`
+	CrewDisposition        *NEMSIS3Value   `xml:"eDisposition.IncidentDispositionGroup>eDisposition.29"`
+	TransportDisposition   *NEMSIS3Value   `xml:"eDisposition.IncidentDispositionGroup>eDisposition.30"`
+}
+
+// NEMSIS3Outcome is the "eOutcome" section.
+//
+// The hospital outcome comes from the hospital, not the agency, so this is always empty.
+type NEMSIS3Outcome struct{}
+
+// NEMSIS3Other is the "eOther" section.
+type NEMSIS3Other struct {
+	WorkRelatedExposure []*NEMSIS3Value `xml:"eOther.05"`
+}
+
+// nemsis3Mapper holds the state for mapping a single patient care report.
+type nemsis3Mapper struct {
+	options NEMSIS3Options
+	err     error
+}
+
+// value returns an optional value; empty values return nil so that the element is left out.
+func (m *nemsis3Mapper) value(value string) *NEMSIS3Value {
+	if value == "" {
+		return nil
+	}
+	return &NEMSIS3Value{Value: value}
+}
+
+// required returns a required value; empty values are "Not Recorded".
+func (m *nemsis3Mapper) required(value string) *NEMSIS3Value {
+	if value == "" {
+		return &NEMSIS3Value{Nil: "true", NV: NEMSIS3NotRecorded}
+	}
+	return &NEMSIS3Value{Value: value}
+}
+
+// notRecorded returns a single "Not Recorded" value for a required element that can repeat.
+func (m *nemsis3Mapper) notRecorded() []*NEMSIS3Value {
+	return []*NEMSIS3Value{m.required("")}
+}
+
+// yesNo returns "Yes" if the value is "1" and "No" otherwise.
+func (m *nemsis3Mapper) yesNo(value string) *NEMSIS3Value {
+	if value == "1" {
+		return &NEMSIS3Value{Value: NEMSIS3Yes}
+	}
+	return &NEMSIS3Value{Value: NEMSIS3No}
+}
+
+// dateTime converts an Emergency Reporting date/time to the NEMSIS format, which is RFC 3339 with the offset.
+//
+// The first value that is not empty is used.  Unparseable values are an error.
+func (m *nemsis3Mapper) dateTime(field string, values ...string) string {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		t, ok := parseDateTime(value, m.options.Location)
+		if !ok {
+			if m.err == nil {
+				m.err = fmt.Errorf("%s: could not parse the date/time: %s", field, value)
+			}
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// date converts an Emergency Reporting date to the NEMSIS format.
+func (m *nemsis3Mapper) date(field string, value string) string {
+	if value == "" {
+		return ""
+	}
+	t, ok := parseDateTime(value, m.options.Location)
+	if !ok {
+		if m.err == nil {
+			m.err = fmt.Errorf("%s: could not parse the date: %s", field, value)
+		}
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// stringValue returns the value, or an empty string if there is none.
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// NEMSIS3FromPatientCareReport maps a patient care report to NEMSIS 3.
+//
+// The unit (the exposure apparatus) and the apparatus are optional; when given, they fill in the
+// response mode, the vehicle, and any times that the report is missing.  Required elements that
+// have no value are sent as "Not Recorded", and the required sections that Emergency Reporting
+// has nothing for are sent with only those.
+func NEMSIS3FromPatientCareReport(report *PatientCareReport, unit *ExposureApparatus, apparatus *Apparatus, options NEMSIS3Options) (*NEMSIS3PatientCareReport, error) {
+	m := &nemsis3Mapper{
+		options: options,
+	}
+
+	result := &NEMSIS3PatientCareReport{}
+
+	pcrNumber := report.PCRNumber
+	if pcrNumber == "" {
+		pcrNumber = report.PCRID
+	}
+	result.Record = NEMSIS3Record{
+		PCRNumber:       m.required(pcrNumber),
+		SoftwareCreator: m.required(options.SoftwareCreator),
+		SoftwareName:    m.required(options.SoftwareName),
+		SoftwareVersion: m.required(options.SoftwareVersion),
+	}
+
+	result.Response = NEMSIS3Response{
+		AgencyNumber:          m.required(options.AgencyNumber),
+		AgencyName:            m.value(options.AgencyName),
+		IncidentNumber:        m.required(report.IncidentNumber),
+		ResponseNumber:        m.required(report.ResponseNumber),
+		TypeOfService:         m.required(report.TypeOfService),
+		UnitCapability:        m.required(options.UnitCapability),
+		DispatchDelays:        m.notRecorded(),
+		ResponseDelays:        m.notRecorded(),
+		SceneDelays:           m.notRecorded(),
+		TransportDelays:       m.notRecorded(),
+		TurnAroundDelays:      m.notRecorded(),
+		LevelOfCare:           m.required(options.UnitLevelOfCare),
+		ResponseModeModifiers: m.notRecorded(),
+	}
+	if apparatus != nil {
+		vehicleNumber := apparatus.VehicleNumber
+		if vehicleNumber == "" {
+			vehicleNumber = apparatus.DepartmentApparatusName
+		}
+		result.Response.VehicleNumber = m.required(vehicleNumber)
+		result.Response.CallSign = m.required(apparatus.EmsUnitCallSign)
+	} else {
+		result.Response.VehicleNumber = m.required("")
+		result.Response.CallSign = m.required("")
+	}
+
+	result.Dispatch = NEMSIS3Dispatch{
+		DispatchReason: m.required(""),
+		EMDPerformed:   m.required(""),
+	}
+
+	// The crew is everyone who gave a medication or performed a procedure.
+	var crewMemberIDs []string
+	seenCrewMemberIDs := map[string]bool{}
+	for _, procedure := range report.Procedures {
+		if procedure.PerformedByUserID != "" && !seenCrewMemberIDs[procedure.PerformedByUserID] {
+			seenCrewMemberIDs[procedure.PerformedByUserID] = true
+			crewMemberIDs = append(crewMemberIDs, procedure.PerformedByUserID)
+		}
+	}
+	for _, medication := range report.Medications {
+		if medication.GivenByUserID != "" && !seenCrewMemberIDs[medication.GivenByUserID] {
+			seenCrewMemberIDs[medication.GivenByUserID] = true
+			crewMemberIDs = append(crewMemberIDs, medication.GivenByUserID)
+		}
+	}
+	if len(crewMemberIDs) == 0 {
+		crewMemberIDs = []string{""}
+	}
+	for _, crewMemberID := range crewMemberIDs {
+		result.Crew.CrewGroups = append(result.Crew.CrewGroups, &NEMSIS3CrewGroup{
+			CrewMemberID: m.required(crewMemberID),
+			Level:        m.required(""),
+			ResponseRole: m.required(""),
+		})
+	}
+
+	times := report.Times
+	if times == nil {
+		times = &EMSTimes{}
+	}
+	if unit == nil {
+		unit = &ExposureApparatus{}
+	}
+	result.Response.ResponseMode = m.required(unit.ResponseModeNemsis3)
+	result.Times = NEMSIS3Times{
+		PSAPCall:              m.required(m.dateTime("eTimes.01", times.PSAPCallDateTime)),
+		UnitNotified:          m.required(m.dateTime("eTimes.03", times.UnitNotifiedDateTime, unit.DispatchDateTime, unit.AlarmDateTime)),
+		UnitEnroute:           m.required(m.dateTime("eTimes.05", times.UnitEnrouteDateTime, stringValue(unit.EnrouteDateTime))),
+		UnitArrivedOnScene:    m.required(m.dateTime("eTimes.06", times.UnitArrivedOnSceneDateTime, stringValue(unit.ArrivedDateTime))),
+		ArrivedAtPatient:      m.required(m.dateTime("eTimes.07", times.ArrivedAtPatientDateTime, stringValue(unit.ArrivedAtPatientDateTime))),
+		UnitLeftScene:         m.required(m.dateTime("eTimes.09", times.UnitLeftSceneDateTime, stringValue(unit.ClearedSceneDateTime))),
+		AtDestination:         m.required(m.dateTime("eTimes.11", times.AtDestinationDateTime, stringValue(unit.AtDestinationDateTime))),
+		TransferOfPatientCare: m.required(m.dateTime("eTimes.12", times.TransferOfPatientCareDateTime, stringValue(unit.TransferOfPatientCareDateTime))),
+		UnitBackInService:     m.required(m.dateTime("eTimes.13", times.UnitBackInServiceDateTime, stringValue(unit.CallCompletedDateTime))),
+	}
+
+	patient := report.Patient
+	if patient == nil {
+		patient = &EMSPatient{}
+	}
+	result.Patient = NEMSIS3Patient{
+		LastName:      m.required(patient.LastName),
+		FirstName:     m.required(patient.FirstName),
+		MiddleName:    m.value(patient.MiddleName),
+		StreetAddress: m.value(patient.StreetAddress),
+		City:          m.value(patient.City),
+		County:        m.value(patient.County),
+		State:         m.value(patient.State),
+		ZipCode:       m.required(patient.ZipCode),
+		Gender:        m.required(patient.Gender),
+		Race:          m.required(patient.Race),
+		Age:           m.required(patient.Age),
+		AgeUnits:      m.required(patient.AgeUnits),
+		DateOfBirth:   m.required(m.date("ePatient.17", patient.DateOfBirth)),
+		Phone:         m.value(patient.Phone),
+	}
+
+	result.Payment = NEMSIS3Payment{
+		PrimaryMethodOfPayment: m.required(""),
+		CMSServiceLevel:        m.required(""),
+	}
+
+	result.Scene = NEMSIS3Scene{
+		FirstUnitOnScene:     m.required(""),
+		NumberOfPatients:     m.required(""),
+		MassCasualtyIncident: m.required(""),
+		TriageClassification: m.required(""),
+		LocationType:         m.required(""),
+		State:                m.required(""),
+		ZipCode:              m.required(""),
+		County:               m.required(""),
+	}
+
+	result.Situation = NEMSIS3Situation{
+		SymptomOnset:         m.required(""),
+		PossibleInjury:       m.required(""),
+		ComplaintLocation:    m.required(""),
+		ComplaintOrganSystem: m.notRecorded(),
+		PrimarySymptom:       m.required(""),
+		OtherSymptoms:        m.notRecorded(),
+		PrimaryImpression:    m.required(""),
+		SecondaryImpressions: m.notRecorded(),
+		InitialAcuity:        m.required(""),
+	}
+
+	result.Injury = NEMSIS3Injury{
+		CauseOfInjury:        m.notRecorded(),
+		TraumaCenterCriteria: m.notRecorded(),
+		InjuryRiskFactors:    m.notRecorded(),
+	}
+
+	result.Arrest = NEMSIS3Arrest{
+		CardiacArrest:          m.required(""),
+		Etiology:               m.required(""),
+		ResuscitationAttempted: m.notRecorded(),
+		Witnessed:              m.notRecorded(),
+		CPRPriorToEMS:          m.required(""),
+		AEDPriorToEMS:          m.required(""),
+		TypeOfCPR:              m.notRecorded(),
+		FirstMonitoredRhythm:   m.required(""),
+		ROSC:                   m.notRecorded(),
+		ArrestDateTime:         m.required(""),
+		ResuscitationStopped:   m.required(""),
+		ReasonStopped:          m.required(""),
+		RhythmAtDestination:    m.notRecorded(),
+		EndOfArrestEvent:       m.required(""),
+	}
+
+	result.History = NEMSIS3History{
+		BarriersToCare: m.notRecorded(),
+		AlcoholOrDrugs: m.notRecorded(),
+	}
+
+	result.Narrative = NEMSIS3Narrative{
+		Narrative: m.required(report.Narrative),
+	}
+
+	vitalsList := report.Vitals
+	if len(vitalsList) == 0 {
+		vitalsList = []*EMSVitals{{}}
+	}
+	for _, vitals := range vitalsList {
+		result.Vitals.VitalGroups = append(result.Vitals.VitalGroups, &NEMSIS3VitalGroup{
+			DateTime:         m.required(m.dateTime("eVitals.01", vitals.VitalsDateTime)),
+			PriorToEMSCare:   m.yesNo(vitals.PerformedBeforeEMS),
+			CardiacRhythm:    m.notRecorded(),
+			SystolicBP:       m.required(vitals.SystolicBP),
+			DiastolicBP:      m.value(vitals.DiastolicBP),
+			HeartRate:        m.required(vitals.HeartRate),
+			PulseOximetry:    m.value(vitals.PulseOximetry),
+			RespiratoryRate:  m.required(vitals.RespiratoryRate),
+			BloodGlucose:     m.value(vitals.BloodGlucose),
+			GCSEye:           m.required(vitals.GCSEye),
+			GCSVerbal:        m.required(vitals.GCSVerbal),
+			GCSMotor:         m.required(vitals.GCSMotor),
+			GCSQualifier:     m.notRecorded(),
+			GCSTotal:         m.value(vitals.GCSTotal),
+			TemperatureC:     m.value(vitals.TemperatureC),
+			Responsiveness:   m.required(""),
+			PainScale:        m.required(vitals.PainScale),
+			StrokeScaleScore: m.required(""),
+			StrokeScaleType:  m.required(""),
+		})
+	}
+
+	result.Protocols.ProtocolGroups = []*NEMSIS3ProtocolGroup{
+		{
+			Protocol: m.required(""),
+		},
+	}
+
+	medications := report.Medications
+	if len(medications) == 0 {
+		medications = []*EMSMedication{{}}
+	}
+	for _, medication := range medications {
+		result.Medications.MedicationGroups = append(result.Medications.MedicationGroups, &NEMSIS3MedicationGroup{
+			DateTime:       m.required(m.dateTime("eMedications.01", medication.MedicationDateTime)),
+			PriorToEMSCare: m.yesNo(medication.PerformedBeforeEMS),
+			Medication:     m.required(medication.MedicationCode),
+			Route:          m.required(medication.Route),
+			Dose:           m.required(medication.Dose),
+			DoseUnits:      m.required(medication.DoseUnits),
+			Response:       m.required(medication.Response),
+			Complications:  m.notRecorded(),
+			CrewMemberID:   m.value(medication.GivenByUserID),
+			GivenByRole:    m.required(""),
+		})
+	}
+
+	procedures := report.Procedures
+	if len(procedures) == 0 {
+		procedures = []*EMSProcedure{{}}
+	}
+	for _, procedure := range procedures {
+		result.Procedures.ProcedureGroups = append(result.Procedures.ProcedureGroups, &NEMSIS3ProcedureGroup{
+			DateTime:        m.required(m.dateTime("eProcedures.01", procedure.ProcedureDateTime)),
+			PriorToEMSCare:  m.yesNo(procedure.PerformedBeforeEMS),
+			Procedure:       m.required(procedure.ProcedureCode),
+			Attempts:        m.required(procedure.Attempts),
+			Successful:      m.yesNo(procedure.Successful),
+			Complications:   m.notRecorded(),
+			Response:        m.required(""),
+			CrewMemberID:    m.value(procedure.PerformedByUserID),
+			PerformedByRole: m.required(""),
+		})
+	}
+
+	disposition := report.Disposition
+	if disposition == nil {
+		disposition = &EMSDisposition{}
+	}
+	transportMethod := disposition.TransportMethod
+	if transportMethod == "" && disposition.DestinationName != "" && apparatus != nil {
+		transportMethod = apparatus.Nemesis3TransportMethod
+	}
+	result.Disposition = NEMSIS3Disposition{
+		TransportMethod:        m.required(transportMethod),
+		TransportModeFromScene: m.required(disposition.TransportModeFromScene),
+		TransportModifiers:     m.notRecorded(),
+		FinalAcuity:            m.required(""),
+		ReasonForDestination:   m.notRecorded(),
+		DestinationType:        m.required(disposition.DestinationType),
+		InPatientDestination:   m.required(""),
+		HospitalCapability:     m.notRecorded(),
+		PreArrivalAlert:        m.required(""),
+		PreArrivalAlertTime:    m.required(""),
+		UnitDisposition:        m.required(disposition.UnitDisposition),
+		PatientEvaluationCare:  m.required(disposition.PatientEvaluationCare),
+		CrewDisposition:        m.required(disposition.CrewDisposition),
+		TransportDisposition:   m.required(disposition.TransportDisposition),
+	}
+	if disposition.DestinationName != "" || disposition.DestinationCode != "" {
+		result.Disposition.DestinationName = m.value(disposition.DestinationName)
+		result.Disposition.DestinationCode = m.value(disposition.DestinationCode)
+		result.Disposition.DestinationAddress = m.value(disposition.DestinationAddress)
+		result.Disposition.DestinationCity = m.value(disposition.DestinationCity)
+		result.Disposition.DestinationState = m.required(disposition.DestinationState)
+		result.Disposition.DestinationCounty = m.required("")
+		result.Disposition.DestinationZipCode = m.required(disposition.DestinationZipCode)
+	}
+
+	result.Other = NEMSIS3Other{
+		WorkRelatedExposure: m.notRecorded(),
+	}
+
+	if m.err != nil {
+		return nil, fmt.Errorf("patient care report %s: %w", pcrNumber, m.err)
+	}
+	return result, nil
+}
+
+// NewNEMSIS3DataSet returns a data set with the agency and the patient care reports.
+func NewNEMSIS3DataSet(reports []*NEMSIS3PatientCareReport, options NEMSIS3Options) *NEMSIS3DataSet {
+	m := &nemsis3Mapper{
+		options: options,
+	}
+	return &NEMSIS3DataSet{
+		Xmlns:    NEMSIS3Namespace,
+		XmlnsXSI: "http://www.w3.org/2001/XMLSchema-instance",
+		Header: NEMSIS3Header{
+			DemographicGroup: NEMSIS3DemographicGroup{
+				AgencyStateID: m.required(options.AgencyStateID),
+				AgencyNumber:  m.required(options.AgencyNumber),
+				State:         m.required(options.State),
+			},
+			PatientCareReports: reports,
+		},
+	}
+}
+
+// WriteNEMSIS3XML writes the data set as an XML document.
+func WriteNEMSIS3XML(w io.Writer, dataSet *NEMSIS3DataSet) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(dataSet)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// GetNEMSIS3PatientCareReport gets the unit and apparatus of a patient care report and maps it to NEMSIS 3.
+//
+// See `NEMSIS3FromPatientCareReport`.
+func (c *Client) GetNEMSIS3PatientCareReport(ctx context.Context, report *PatientCareReport, options NEMSIS3Options) (*NEMSIS3PatientCareReport, error) {
+	var unit *ExposureApparatus
+	if report.ExposureID != "" && report.DepartmentApparatusID != "" {
+		apparatusesResponse, err := c.GetExposureApparatuses(ctx, report.ExposureID)
+		if err != nil {
+			return nil, err
+		}
+		for _, apparatus := range apparatusesResponse.Apparatuses {
+			if apparatus.DepartmentApparatusID == report.DepartmentApparatusID {
+				unit = apparatus
+				break
+			}
+		}
+	}
+
+	var apparatus *Apparatus
+	if report.DepartmentApparatusID != "" {
+		apparatusResponse, err := c.GetApparatus(ctx, report.DepartmentApparatusID)
+		if err != nil {
+			return nil, err
+		}
+		apparatus = apparatusResponse.Apparatus
+	}
+
+	return NEMSIS3FromPatientCareReport(report, unit, apparatus, options)
+}
@@ -0,0 +1,330 @@
+package emergencyreporting
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "Update the golden files in testdata.")
+
+// nemsis3TestDataSet returns the data set that the golden file is made from.
+func nemsis3TestDataSet(t *testing.T) []byte {
+	location, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("Could not load the time zone: %v", err)
+	}
+	options := NEMSIS3Options{
+		AgencyStateID:   "12345",
+		AgencyNumber:    "A123",
+		AgencyName:      "Springfield Fire",
+		State:           "17",
+		SoftwareCreator: "Example",
+		SoftwareName:    "emergencyreporting",
+		SoftwareVersion: "1.0",
+		UnitCapability:  "2207015",
+		UnitLevelOfCare: "2215005",
+		Location:        location,
+	}
+
+	transferOfPatientCare := "2021-03-04 05:40:00"
+	unit := &ExposureApparatus{
+		DepartmentApparatusID:         "1",
+		DispatchDateTime:              "2021-03-04 05:06:30",
+		ResponseModeNemsis3:           "2223001",
+		TransferOfPatientCareDateTime: &transferOfPatientCare,
+	}
+	apparatus := &Apparatus{
+		DepartmentApparatusID:   "1",
+		DepartmentApparatusName: "E1",
+		EmsUnitCallSign:         "Medic 1",
+		Nemesis3TransportMethod: "4216005",
+	}
+
+	var reports []*NEMSIS3PatientCareReport
+	for _, report := range []*PatientCareReport{
+		{
+			PCRNumber:             "PCR-1",
+			IncidentNumber:        "2021-0002",
+			ResponseNumber:        "1",
+			DepartmentApparatusID: "1",
+			TypeOfService:         "2205001",
+			Times:                 &EMSTimes{UnitEnrouteDateTime: "2021-03-04 05:07:00"},
+			Patient:               &EMSPatient{FirstName: "Pat", LastName: "Smith & Co", DateOfBirth: "1970-01-02", Gender: "9906001", Age: "51", AgeUnits: "2516009"},
+			Vitals:                []*EMSVitals{{VitalsDateTime: "2021-03-04 05:15:00", SystolicBP: "120", DiastolicBP: "80", HeartRate: "88", GCSTotal: "15"}},
+			Procedures:            []*EMSProcedure{{ProcedureDateTime: "2021-03-04 05:16:00", ProcedureCode: "392230005", Successful: "1", Attempts: "1", PerformedByUserID: "u1"}},
+			Medications:           []*EMSMedication{{MedicationDateTime: "2021-03-04 05:17:00", MedicationCode: "1191", Route: "9927043", Dose: "324", DoseUnits: "3706013", Response: "9916005", GivenByUserID: "u2"}},
+			Disposition:           &EMSDisposition{DestinationName: "Memorial", DestinationState: "17", DestinationZipCode: "62701", UnitDisposition: "4227001", TransportDisposition: "4230001"},
+			Narrative:             "Pt found <sitting>.",
+		},
+		{
+			PCRID: "2",
+		},
+	} {
+		var reportUnit *ExposureApparatus
+		var reportApparatus *Apparatus
+		if report.DepartmentApparatusID != "" {
+			reportUnit = unit
+			reportApparatus = apparatus
+		}
+		result, err := NEMSIS3FromPatientCareReport(report, reportUnit, reportApparatus, options)
+		if err != nil {
+			t.Fatalf("Could not map the report: %v", err)
+		}
+		reports = append(reports, result)
+	}
+
+	var buffer bytes.Buffer
+	err = WriteNEMSIS3XML(&buffer, NewNEMSIS3DataSet(reports, options))
+	if err != nil {
+		t.Fatalf("Could not write the XML: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestNEMSIS3Golden(t *testing.T) {
+	output := nemsis3TestDataSet(t)
+
+	filename := filepath.Join("testdata", "nemsis3.xml")
+	if *updateGolden {
+		err := ioutil.WriteFile(filename, output, 0644)
+		if err != nil {
+			t.Fatalf("Could not update the golden file: %v", err)
+		}
+	}
+	expected, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Could not read the golden file: %v", err)
+	}
+	if !bytes.Equal(expected, output) {
+		t.Errorf("The output does not match %s (run with -update to update it):\n%s", filename, output)
+	}
+}
+
+// TestNEMSIS3Schema validates the golden file against the official NEMSIS 3.5 schema.
+//
+// The schema is not distributed with this package; set NEMSIS_XSD to the path of "EMSDataSet_v3.xsd" to run it.
+func TestNEMSIS3Schema(t *testing.T) {
+	xsd := os.Getenv("NEMSIS_XSD")
+	if xsd == "" {
+		t.Skip("NEMSIS_XSD is not set")
+	}
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint is not installed")
+	}
+
+	output, err := exec.Command(xmllint, "--noout", "--schema", xsd, filepath.Join("testdata", "nemsis3.xml")).CombinedOutput()
+	if err != nil {
+		t.Errorf("The golden file is not valid: %v\n%s", err, output)
+	}
+}
+
+// nemsis3Element is an element of a decoded data set.
+type nemsis3Element struct {
+	Name     string
+	Children []*nemsis3Element
+}
+
+// decodeNEMSIS3 decodes a data set into its elements.
+func decodeNEMSIS3(t *testing.T, input []byte) *nemsis3Element {
+	root := &nemsis3Element{}
+	stack := []*nemsis3Element{root}
+	decoder := xml.NewDecoder(bytes.NewReader(input))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := &nemsis3Element{Name: token.Name.Local}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("Expected a single root element; got %d", len(root.Children))
+	}
+	return root.Children[0]
+}
+
+// number returns the element number of a NEMSIS element (such as 6 for "eVitals.06").
+//
+// Groups are numbered by their first numbered element, and anything else (such as a section) is -1.
+func (e *nemsis3Element) number() int {
+	index := strings.LastIndex(e.Name, ".")
+	if index < 0 {
+		return -1
+	}
+	number, err := strconv.Atoi(e.Name[index+1:])
+	if err == nil {
+		return number
+	}
+	if !strings.HasSuffix(e.Name, "Group") {
+		return -1
+	}
+	for _, child := range e.Children {
+		if number := child.number(); number >= 0 {
+			return number
+		}
+	}
+	return -1
+}
+
+func TestNEMSIS3SectionOrder(t *testing.T) {
+	dataSet := decodeNEMSIS3(t, nemsis3TestDataSet(t))
+
+	// This is the xs:sequence of PatientCareReport, without the optional sections that are never written.
+	expected := []string{"eRecord", "eResponse", "eDispatch", "eCrew", "eTimes", "ePatient", "ePayment", "eScene", "eSituation", "eInjury", "eArrest", "eHistory", "eNarrative", "eVitals", "eExam", "eProtocols", "eMedications", "eProcedures", "eDisposition", "eOutcome", "eOther"}
+
+	var reports []*nemsis3Element
+	for _, child := range dataSet.Children {
+		if child.Name == "Header" {
+			for _, grandchild := range child.Children {
+				if grandchild.Name == "PatientCareReport" {
+					reports = append(reports, grandchild)
+				}
+			}
+		}
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports; got %d", len(reports))
+	}
+	for r, report := range reports {
+		var names []string
+		for _, section := range report.Children {
+			names = append(names, section.Name)
+		}
+		if strings.Join(names, " ") != strings.Join(expected, " ") {
+			t.Errorf("Report %d: wrong sections:\nExpected: %v\nActual:   %v", r, expected, names)
+		}
+	}
+}
+
+func TestNEMSIS3ElementOrder(t *testing.T) {
+	dataSet := decodeNEMSIS3(t, nemsis3TestDataSet(t))
+
+	var check func(path string, element *nemsis3Element)
+	check = func(path string, element *nemsis3Element) {
+		previous := -1
+		for _, child := range element.Children {
+			number := child.number()
+			if number >= 0 && number < previous {
+				t.Errorf("%s: %s is out of order", path, child.Name)
+			}
+			if number >= 0 {
+				previous = number
+			}
+			check(path+"/"+child.Name, child)
+		}
+	}
+	check(dataSet.Name, dataSet)
+}
+
+func TestNEMSIS3FromPatientCareReport(t *testing.T) {
+	rows := []struct {
+		description string
+		report      *PatientCareReport
+		check       func(t *testing.T, result *NEMSIS3PatientCareReport)
+		err         bool
+	}{
+		{
+			description: "Empty report",
+			report:      &PatientCareReport{PCRID: "1"},
+			check: func(t *testing.T, result *NEMSIS3PatientCareReport) {
+				if result.Record.PCRNumber.Value != "1" {
+					t.Errorf("Wrong PCR number: %q", result.Record.PCRNumber.Value)
+				}
+				if result.Narrative.Narrative.NV != NEMSIS3NotRecorded {
+					t.Errorf("The narrative should be not recorded")
+				}
+				if len(result.Vitals.VitalGroups) != 1 || result.Vitals.VitalGroups[0].SystolicBP.NV != NEMSIS3NotRecorded {
+					t.Errorf("There should be one vitals group that is not recorded")
+				}
+				if len(result.Crew.CrewGroups) != 1 || result.Crew.CrewGroups[0].CrewMemberID.NV != NEMSIS3NotRecorded {
+					t.Errorf("There should be one crew member that is not recorded")
+				}
+				if result.Disposition.DestinationState != nil {
+					t.Errorf("There should be no destination")
+				}
+			},
+		},
+		{
+			description: "Crew",
+			report: &PatientCareReport{
+				Procedures:  []*EMSProcedure{{PerformedByUserID: "2"}, {PerformedByUserID: "1"}},
+				Medications: []*EMSMedication{{GivenByUserID: "1"}, {GivenByUserID: "3"}},
+			},
+			check: func(t *testing.T, result *NEMSIS3PatientCareReport) {
+				var ids []string
+				for _, crewGroup := range result.Crew.CrewGroups {
+					ids = append(ids, crewGroup.CrewMemberID.Value)
+				}
+				if strings.Join(ids, ",") != "2,1,3" {
+					t.Errorf("Wrong crew: %v", ids)
+				}
+			},
+		},
+		{
+			description: "Destination",
+			report:      &PatientCareReport{Disposition: &EMSDisposition{DestinationName: "Memorial", DestinationZipCode: "62701"}},
+			check: func(t *testing.T, result *NEMSIS3PatientCareReport) {
+				if result.Disposition.DestinationZipCode.Value != "62701" {
+					t.Errorf("Wrong destination zip code")
+				}
+				if result.Disposition.DestinationState.NV != NEMSIS3NotRecorded {
+					t.Errorf("The destination state should be not recorded")
+				}
+			},
+		},
+		{
+			description: "Prior to EMS care",
+			report:      &PatientCareReport{Vitals: []*EMSVitals{{PerformedBeforeEMS: "1"}}},
+			check: func(t *testing.T, result *NEMSIS3PatientCareReport) {
+				if result.Vitals.VitalGroups[0].PriorToEMSCare.Value != NEMSIS3Yes {
+					t.Errorf("The vitals should be prior to EMS care")
+				}
+				if result.Procedures.ProcedureGroups[0].PriorToEMSCare.Value != NEMSIS3No {
+					t.Errorf("The procedure should not be prior to EMS care")
+				}
+			},
+		},
+		{
+			description: "Bad date/time",
+			report:      &PatientCareReport{Times: &EMSTimes{PSAPCallDateTime: "yesterday"}},
+			err:         true,
+		},
+		{
+			description: "Bad date of birth",
+			report:      &PatientCareReport{Patient: &EMSPatient{DateOfBirth: "1970-13-45"}},
+			err:         true,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			result, err := NEMSIS3FromPatientCareReport(row.report, nil, nil, NEMSIS3Options{})
+			if row.err {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			row.check(t, result)
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package emergencyreporting
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NERIS issue kinds.
+const (
+	// NERISIssueMissing means that a field required by NERIS has no value in the source data.
+	NERISIssueMissing = "missing"
+	// NERISIssueUnmappable means that the source data has a value that has no NERIS equivalent.
+	NERISIssueUnmappable = "unmappable"
+)
+
+// NERISOptions controls how incidents are mapped to NERIS.
+type NERISOptions struct {
+	DepartmentNERISID string         // The NERIS ID assigned to the department.  This has no Emergency Reporting equivalent.
+	Location          *time.Location // The time zone that Emergency Reporting date/times are in.  If empty, UTC is assumed.
+}
+
+// NERISIssue describes a single problem found while mapping an incident to NERIS.
+type NERISIssue struct {
+	Kind    string `json:"kind"`             // One of the `NERISIssue*` constants.
+	Field   string `json:"field"`            // The NERIS field, in dotted notation.
+	Source  string `json:"source,omitempty"` // The Emergency Reporting field, if there is one.
+	Value   string `json:"value,omitempty"`  // The offending value, if there is one.
+	Message string `json:"message"`
+}
+
+// NERISExport is the result of mapping a single incident to NERIS.
+type NERISExport struct {
+	IncidentID string         `json:"incidentID"`
+	Incident   *NERISIncident `json:"incident"`
+	Issues     []NERISIssue   `json:"issues"`
+}
+
+// NERISIncident is an incident in the NERIS incident JSON schema.
+type NERISIncident struct {
+	Base           NERISBase            `json:"base"`
+	IncidentTypes  []NERISIncidentType  `json:"incident_types"`
+	Dispatch       NERISDispatch        `json:"dispatch"`
+	Aids           []NERISAid           `json:"aids,omitempty"`
+	ActionsTactics *NERISActionsTactics `json:"actions_tactics,omitempty"`
+	Exposures      []NERISExposure      `json:"exposures,omitempty"`
+	Losses         *NERISLosses         `json:"losses,omitempty"`
+}
+
+// NERISBase is the "base" module of a NERIS incident.
+type NERISBase struct {
+	DepartmentNERISID string            `json:"department_neris_id"`
+	IncidentNumber    string            `json:"incident_number"`
+	Location          NERISLocation     `json:"location"`
+	LocationUse       *NERISLocationUse `json:"location_use,omitempty"`
+}
+
+// NERISLocation is a civic location in the NG911 CLDXF format that NERIS uses.
+type NERISLocation struct {
+	Number                   string      `json:"number,omitempty"`
+	StreetPrefixDirection    string      `json:"street_prefix_direction,omitempty"`
+	Street                   string      `json:"street,omitempty"`
+	StreetPostfix            string      `json:"street_postfix,omitempty"`
+	StreetPostfixDirection   string      `json:"street_postfix_direction,omitempty"`
+	UnitValue                string      `json:"unit_value,omitempty"`
+	IncorporatedMunicipality string      `json:"incorporated_municipality,omitempty"`
+	County                   string      `json:"county,omitempty"`
+	State                    string      `json:"state,omitempty"`
+	PostalCode               string      `json:"postal_code,omitempty"`
+	Directions               string      `json:"directions,omitempty"`
+	Point                    *NERISPoint `json:"point,omitempty"`
+}
+
+// NERISPoint is a GeoJSON point.
+type NERISPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // Longitude, latitude.
+}
+
+// NERISLocationUse is the use of the property at the incident location.
+type NERISLocationUse struct {
+	UseType string `json:"use_type"`
+}
+
+// NERISIncidentType is a single incident type.
+type NERISIncidentType struct {
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+// NERISDispatch is the "dispatch" module of a NERIS incident.
+type NERISDispatch struct {
+	IncidentNumber string              `json:"incident_number,omitempty"`
+	CallArrival    string              `json:"call_arrival,omitempty"`
+	CallAnswered   string              `json:"call_answered,omitempty"`
+	CallCreate     string              `json:"call_create,omitempty"`
+	UnitResponses  []NERISUnitResponse `json:"unit_responses"`
+}
+
+// NERISUnitResponse is a single unit (apparatus) response.
+type NERISUnitResponse struct {
+	ReportedUnitID     string `json:"reported_unit_id"`
+	Staffing           int    `json:"staffing"`
+	ResponseMode       string `json:"response_mode,omitempty"`
+	Dispatch           string `json:"dispatch,omitempty"`
+	EnrouteToScene     string `json:"enroute_to_scene,omitempty"`
+	OnScene            string `json:"on_scene,omitempty"`
+	UnitClearScene     string `json:"unit_clear_scene,omitempty"`
+	CanceledEnroute    string `json:"canceled_enroute,omitempty"`
+	UnitInQuarters     string `json:"unit_in_quarters,omitempty"`
+	UnitTransferOfCare string `json:"unit_transfer_of_care,omitempty"`
+}
+
+// NERISAid is a mutual or automatic aid entry.
+type NERISAid struct {
+	AidDirection string `json:"aid_direction"`
+	AidType      string `json:"aid_type"`
+}
+
+// NERISActionsTactics is the "actions_tactics" module of a NERIS incident.
+type NERISActionsTactics struct {
+	ActionNoAction NERISActionNoAction `json:"action_noaction"`
+}
+
+// NERISActionNoAction lists the actions taken, or why none were taken.
+type NERISActionNoAction struct {
+	Type    string   `json:"type"` // "ACTION" or "NOACTION".
+	Actions []string `json:"actions,omitempty"`
+}
+
+// NERISExposure is an additional exposure.
+type NERISExposure struct {
+	Location NERISLocation `json:"location"`
+}
+
+// NERISLosses is the dollar loss information for an incident.
+type NERISLosses struct {
+	PropertyLoss int64 `json:"property_loss,omitempty"`
+	ContentsLoss int64 `json:"contents_loss,omitempty"`
+}
+
+// nerisIncidentTypes is the crosswalk from specific NFIRS incident type codes to NERIS incident types.
+//
+// Codes that are not listed here fall back to `nerisIncidentTypeSeries`.  An empty value means
+// that the code has no NERIS equivalent even though the rest of its series does.
+var nerisIncidentTypes = map[string]string{
+	"111": "FIRE||STRUCTURE_FIRE",
+	"112": "FIRE||STRUCTURE_FIRE",
+	"113": "FIRE||STRUCTURE_FIRE||CONFINED_COOKING_APPLIANCE_FIRE",
+	"114": "FIRE||STRUCTURE_FIRE||CHIMNEY_FIRE",
+	"131": "FIRE||TRANSPORTATION_FIRE||VEHICLE_PASSENGER",
+	"132": "FIRE||TRANSPORTATION_FIRE||VEHICLE_COMMERCIAL",
+	"141": "FIRE||OUTSIDE_FIRE||FOREST_WOODLAND_FIRE",
+	"142": "FIRE||OUTSIDE_FIRE||VEGETATION_GRASS_FIRE",
+	"143": "FIRE||OUTSIDE_FIRE||VEGETATION_GRASS_FIRE",
+	"151": "FIRE||OUTSIDE_FIRE||TRASH_RUBBISH_FIRE",
+	"154": "FIRE||OUTSIDE_FIRE||DUMPSTER_OUTDOOR_CONTAINER_FIRE",
+	"311": "MEDICAL||ILLNESS",
+	"321": "MEDICAL||ILLNESS",
+	"322": "MEDICAL||INJURY||MOTOR_VEHICLE_COLLISION",
+	"323": "MEDICAL||INJURY||MOTOR_VEHICLE_COLLISION",
+	"324": "", // A motor vehicle accident with no injuries is not a medical incident.
+	"353": "RESCUE||ELEVATOR_ESCALATOR",
+	"411": "HAZSIT||HAZARDOUS_MATERIALS||FLAMMABLE_LIQUID",
+	"412": "HAZSIT||HAZARDOUS_MATERIALS||NATURAL_GAS",
+	"424": "HAZSIT||HAZARDOUS_MATERIALS||CARBON_MONOXIDE",
+	"611": "NOEMERG||CANCELLED",
+	"622": "NOEMERG||GOOD_INTENT||NO_INCIDENT_FOUND_ON_ARRIVAL",
+	"700": "NOEMERG||FALSE_ALARM",
+	"714": "NOEMERG||FALSE_ALARM||MALICIOUS_ALARM",
+	"733": "NOEMERG||FALSE_ALARM||SYSTEM_MALFUNCTION",
+	"743": "NOEMERG||FALSE_ALARM||UNINTENTIONAL_ALARM",
+}
+
+// nerisIncidentTypeSeries is the crosswalk from NFIRS incident type series (the first
+// digit or two of the code) to NERIS incident types.
+var nerisIncidentTypeSeries = map[string]string{
+	"11": "FIRE||STRUCTURE_FIRE",
+	"12": "FIRE||STRUCTURE_FIRE",
+	"13": "FIRE||TRANSPORTATION_FIRE",
+	"14": "FIRE||OUTSIDE_FIRE",
+	"15": "FIRE||OUTSIDE_FIRE",
+	"16": "FIRE||OUTSIDE_FIRE",
+	"17": "FIRE||OUTSIDE_FIRE",
+	"32": "MEDICAL",
+	"35": "RESCUE",
+	"36": "RESCUE||WATER",
+	"4":  "HAZSIT",
+	"5":  "PUBSERV",
+	"6":  "NOEMERG||GOOD_INTENT",
+	"7":  "NOEMERG||FALSE_ALARM",
+}
+
+// nerisActions is the crosswalk from NFIRS action taken codes to NERIS actions.
+var nerisActions = map[string]string{
+	"10": "SUPPRESSION",
+	"11": "SUPPRESSION||EXTINGUISHMENT",
+	"12": "SUPPRESSION||SALVAGE_AND_OVERHAUL",
+	"21": "SEARCH",
+	"22": "RESCUE",
+	"31": "EMERGENCY_MEDICAL_CARE||PROVIDE_BLS",
+	"32": "EMERGENCY_MEDICAL_CARE||PROVIDE_BLS",
+	"33": "EMERGENCY_MEDICAL_CARE||PROVIDE_ALS",
+	"34": "EMERGENCY_MEDICAL_CARE||PATIENT_TRANSPORT",
+	"51": "VENTILATION",
+	"52": "FORCIBLE_ENTRY",
+	"86": "INVESTIGATION",
+	"93": "COMMAND_AND_CONTROL||CANCELLED_ENROUTE",
+}
+
+// nerisAids is the crosswalk from NFIRS aid given or received codes to NERIS aid entries.
+var nerisAids = map[string]NERISAid{
+	"1": {AidDirection: "RECEIVED", AidType: "MUTUAL"},
+	"2": {AidDirection: "RECEIVED", AidType: "AUTOMATIC"},
+	"3": {AidDirection: "GIVEN", AidType: "MUTUAL"},
+	"4": {AidDirection: "GIVEN", AidType: "AUTOMATIC"},
+	"5": {AidDirection: "GIVEN", AidType: "OTHER"},
+}
+
+// nerisResponseModes is the crosswalk from NFIRS response modes to NERIS response modes.
+var nerisResponseModes = map[string]string{
+	"1": "EMERGENT",
+	"2": "NON_EMERGENT",
+}
+
+// streetNumberPattern splits a leading street number from a street name.
+var streetNumberPattern = regexp.MustCompile(`^(\d+[A-Za-z]?)\s+(.+)$`)
+
+// splitStreetNumber splits the leading street number (if any) from a street name, such as "123 Main" into "123" and "Main".
+func splitStreetNumber(streetName string) (string, string) {
+	if matches := streetNumberPattern.FindStringSubmatch(strings.TrimSpace(streetName)); matches != nil {
+		return matches[1], matches[2]
+	}
+	return "", streetName
+}
+
+// nerisMapper holds the state for mapping a single incident.
+type nerisMapper struct {
+	options NERISOptions
+	issues  []NERISIssue
+}
+
+// issue records a problem with the mapping.
+func (m *nerisMapper) issue(kind string, field string, source string, value string, format string, v ...interface{}) {
+	m.issues = append(m.issues, NERISIssue{
+		Kind:    kind,
+		Field:   field,
+		Source:  source,
+		Value:   value,
+		Message: fmt.Sprintf(format, v...),
+	})
+}
+
+// dateTime converts an Emergency Reporting date/time to RFC 3339.
+//
+// Empty values return an empty string; unparseable values are reported.
+func (m *nerisMapper) dateTime(field string, source string, value *string) string {
+	if value == nil || *value == "" {
+		return ""
+	}
+	t, ok := parseDateTime(*value, m.options.Location)
+	if ok {
+		return t.Format(time.RFC3339)
+	}
+	m.issue(NERISIssueUnmappable, field, source, *value, "Could not parse the date/time")
+	return ""
+}
+
+// NERISFromIncident maps an incident to the NERIS incident schema.
+//
+// The incident should be fully populated (see `GetIncidentDetails`); the first exposure is
+// treated as the primary one.  Any fields that are required by NERIS but missing, or that have
+// values with no NERIS equivalent, are returned as issues.
+func NERISFromIncident(incident *Incident, options NERISOptions) *NERISExport {
+	m := &nerisMapper{
+		options: options,
+	}
+
+	result := &NERISIncident{}
+
+	result.Base.DepartmentNERISID = options.DepartmentNERISID
+	if result.Base.DepartmentNERISID == "" {
+		m.issue(NERISIssueMissing, "base.department_neris_id", "", "", "The department NERIS ID has no Emergency Reporting equivalent and must be provided")
+	}
+
+	result.Base.IncidentNumber = incident.IncidentNumber
+	if result.Base.IncidentNumber == "" {
+		m.issue(NERISIssueMissing, "base.incident_number", "incidentNumber", "", "The incident number is required")
+	}
+
+	result.Dispatch.IncidentNumber = incident.DispatchRunNumber
+	result.Dispatch.UnitResponses = []NERISUnitResponse{}
+
+	if len(incident.Exposures) == 0 {
+		m.issue(NERISIssueMissing, "incident_types", "exposures", "", "The incident has no exposures")
+		m.issue(NERISIssueMissing, "base.location", "exposures", "", "The incident has no exposures")
+		return &NERISExport{
+			IncidentID: incident.IncidentID,
+			Incident:   result,
+			Issues:     m.issues,
+		}
+	}
+
+	primary := incident.Exposures[0]
+
+	if incidentType := m.incidentType(primary.IncidentType); incidentType != "" {
+		result.IncidentTypes = append(result.IncidentTypes, NERISIncidentType{Type: incidentType, Primary: true})
+	}
+
+	if primary.Location == nil {
+		m.issue(NERISIssueMissing, "base.location", "exposureLocation", "", "The primary exposure has no location")
+	} else {
+		result.Base.Location = m.location("base.location", primary.Location)
+		if primary.Location.PropertyUse != "" {
+			result.Base.LocationUse = &NERISLocationUse{UseType: primary.Location.PropertyUse}
+			m.issue(NERISIssueUnmappable, "base.location_use.use_type", "propertyUse", primary.Location.PropertyUse, "NFIRS property use codes have no direct NERIS equivalent; the code was passed through as-is")
+		}
+	}
+
+	// Emergency Reporting only has the one PSAP time, so the call arrived and was answered at the same time.
+	result.Dispatch.CallArrival = m.dateTime("dispatch.call_arrival", "psapDateTime", &primary.PSAPDateTime)
+	result.Dispatch.CallAnswered = result.Dispatch.CallArrival
+	result.Dispatch.CallCreate = m.dateTime("dispatch.call_create", "dispatchNotifiedDateTime", &primary.DispatchNotifiedDateTime)
+	if result.Dispatch.CallCreate == "" {
+		result.Dispatch.CallCreate = m.dateTime("dispatch.call_create", "incidentDateTime", &incident.IncidentDateTime)
+	}
+	if result.Dispatch.CallCreate == "" {
+		m.issue(NERISIssueMissing, "dispatch.call_create", "dispatchNotifiedDateTime", "", "The call creation time is required")
+	}
+
+	for _, apparatus := range primary.Apparatuses {
+		result.Dispatch.UnitResponses = append(result.Dispatch.UnitResponses, m.unitResponse(primary, apparatus))
+	}
+	if len(result.Dispatch.UnitResponses) == 0 {
+		m.issue(NERISIssueMissing, "dispatch.unit_responses", "exposureApparatuses", "", "At least one unit response is required")
+	}
+
+	if primary.AidGivenOrReceived != "" && primary.AidGivenOrReceived != "N" {
+		if aid, ok := nerisAids[primary.AidGivenOrReceived]; ok {
+			result.Aids = append(result.Aids, aid)
+		} else {
+			m.issue(NERISIssueUnmappable, "aids", "aidGivenOrReceived", primary.AidGivenOrReceived, "Unknown aid given or received code")
+		}
+	}
+
+	{
+		var actions []string
+		for _, source := range []struct {
+			name  string
+			value string
+		}{
+			{"primaryActionTaken", primary.PrimaryActionTaken},
+			{"secondaryActionTaken", primary.SecondaryActionTaken},
+			{"thirdActionTaken", primary.ThirdActionTaken},
+		} {
+			if source.value == "" {
+				continue
+			}
+			action, ok := nerisActions[source.value]
+			if !ok {
+				m.issue(NERISIssueUnmappable, "actions_tactics.action_noaction.actions", source.name, source.value, "No NERIS action for this NFIRS action taken code")
+				continue
+			}
+			actions = append(actions, action)
+		}
+		if len(actions) > 0 {
+			result.ActionsTactics = &NERISActionsTactics{
+				ActionNoAction: NERISActionNoAction{
+					Type:    "ACTION",
+					Actions: actions,
+				},
+			}
+		}
+	}
+
+	{
+		var losses NERISLosses
+		if primary.HasPropertyLoss == "1" {
+			losses.PropertyLoss = m.amount("losses.property_loss", "propertyLossAmount", primary.PropertyLossAmount)
+		}
+		if primary.HasContentLoss == "1" {
+			losses.ContentsLoss = m.amount("losses.contents_loss", "contentLossAmount", primary.ContentLossAmount)
+		}
+		if losses != (NERISLosses{}) {
+			result.Losses = &losses
+		}
+	}
+
+	for index, exposure := range incident.Exposures[1:] {
+		field := fmt.Sprintf("exposures[%d]", index)
+		if exposure.Location == nil {
+			m.issue(NERISIssueMissing, field+".location", "exposureLocation", "", "Exposure %s has no location", exposure.ExposureID)
+			continue
+		}
+		result.Exposures = append(result.Exposures, NERISExposure{
+			Location: m.location(field+".location", exposure.Location),
+		})
+	}
+
+	return &NERISExport{
+		IncidentID: incident.IncidentID,
+		Incident:   result,
+		Issues:     m.issues,
+	}
+}
+
+// incidentType maps an NFIRS incident type code to a NERIS incident type.
+func (m *nerisMapper) incidentType(code string) string {
+	if code == "" {
+		m.issue(NERISIssueMissing, "incident_types", "incidentType", "", "The primary exposure has no incident type")
+		return ""
+	}
+	if incidentType, ok := nerisIncidentTypes[code]; ok {
+		if incidentType == "" {
+			m.issue(NERISIssueUnmappable, "incident_types", "incidentType", code, "No NERIS incident type for this NFIRS incident type code")
+		}
+		return incidentType
+	}
+	for length := 2; length >= 1; length-- {
+		if len(code) < length {
+			continue
+		}
+		if incidentType, ok := nerisIncidentTypeSeries[code[0:length]]; ok {
+			return incidentType
+		}
+	}
+	m.issue(NERISIssueUnmappable, "incident_types", "incidentType", code, "No NERIS incident type for this NFIRS incident type code")
+	return ""
+}
+
+// location maps an exposure location to a NERIS location.
+func (m *nerisMapper) location(field string, location *ExposureLocation) NERISLocation {
+	result := NERISLocation{
+		StreetPrefixDirection:    location.StreetPrefix,
+		Street:                   location.StreetName,
+		StreetPostfix:            location.StreetType,
+		StreetPostfixDirection:   location.StreetSuffix,
+		UnitValue:                location.AptOrSuiteNumber,
+		IncorporatedMunicipality: location.City,
+		County:                   location.CountyCode,
+		State:                    location.State,
+		PostalCode:               location.ZipCode,
+		Directions:               location.CrossStreetOrDirections,
+	}
+
+	// Emergency Reporting has no separate street number field, so it is usually part of the street name.
+	result.Number, result.Street = splitStreetNumber(location.StreetName)
+
+	if location.LocationType != "" && location.LocationType != "1" {
+		m.issue(NERISIssueUnmappable, field, "locationType", location.LocationType, "Only street address locations can be mapped to a civic location")
+	}
+	if result.Street == "" {
+		m.issue(NERISIssueMissing, field+".street", "streetName", "", "The street name is required")
+	}
+	if result.State == "" {
+		m.issue(NERISIssueMissing, field+".state", "state", "", "The state is required")
+	}
+
+	if location.Latitude != "" || location.Longitude != "" {
+		latitude, latitudeErr := strconv.ParseFloat(strings.TrimSpace(location.Latitude), 64)
+		longitude, longitudeErr := strconv.ParseFloat(strings.TrimSpace(location.Longitude), 64)
+		if latitudeErr != nil || longitudeErr != nil {
+			m.issue(NERISIssueUnmappable, field+".point", "latitude/longitude", location.Latitude+","+location.Longitude, "Could not parse the coordinates")
+		} else {
+			result.Point = &NERISPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{longitude, latitude},
+			}
+		}
+	}
+
+	return result
+}
+
+// unitResponse maps an exposure apparatus to a NERIS unit response.
+func (m *nerisMapper) unitResponse(exposure *Exposure, apparatus *ExposureApparatus) NERISUnitResponse {
+	field := "dispatch.unit_responses[" + apparatus.ApparatusID + "]"
+
+	result := NERISUnitResponse{
+		ReportedUnitID:     apparatus.AgencyApparatusID,
+		Dispatch:           m.dateTime(field+".dispatch", "dispatchDateTime", &apparatus.DispatchDateTime),
+		EnrouteToScene:     m.dateTime(field+".enroute_to_scene", "enrouteDateTime", apparatus.EnrouteDateTime),
+		OnScene:            m.dateTime(field+".on_scene", "arrivedDateTime", apparatus.ArrivedDateTime),
+		UnitClearScene:     m.dateTime(field+".unit_clear_scene", "clearedSceneDateTime", apparatus.ClearedSceneDateTime),
+		UnitInQuarters:     m.dateTime(field+".unit_in_quarters", "inQuartersDateTime", apparatus.InQuartersDateTime),
+		UnitTransferOfCare: m.dateTime(field+".unit_transfer_of_care", "transferOfPatientCareDateTime", apparatus.TransferOfPatientCareDateTime),
+	}
+	if result.ReportedUnitID == "" {
+		result.ReportedUnitID = apparatus.DepartmentApparatusID
+	}
+	if result.ReportedUnitID == "" {
+		m.issue(NERISIssueMissing, field+".reported_unit_id", "agencyApparatusID", "", "The unit ID is required")
+	}
+	if result.Dispatch == "" {
+		result.Dispatch = m.dateTime(field+".dispatch", "alarmDateTime", &apparatus.AlarmDateTime)
+	}
+	if apparatus.WasCancelled == "1" {
+		result.CanceledEnroute = m.dateTime(field+".canceled_enroute", "cancelledDateTime", apparatus.CancelledDateTime)
+	}
+
+	if apparatus.ResponseModeToScene != "" {
+		if mode, ok := nerisResponseModes[apparatus.ResponseModeToScene]; ok {
+			result.ResponseMode = mode
+		} else {
+			m.issue(NERISIssueUnmappable, field+".response_mode", "responseModeToScene", apparatus.ResponseModeToScene, "Unknown response mode")
+		}
+	}
+
+	for _, member := range exposure.CrewMembers {
+		if member.ApparatusID == apparatus.ApparatusID {
+			result.Staffing++
+		}
+	}
+	if result.Staffing == 0 {
+		m.issue(NERISIssueMissing, field+".staffing", "crewMembers", "", "No crew members are assigned to apparatus %s", apparatus.ApparatusID)
+	}
+
+	return result
+}
+
+// amount parses a whole-dollar amount.
+func (m *nerisMapper) amount(field string, source string, value string) int64 {
+	if value == "" {
+		m.issue(NERISIssueMissing, field, source, "", "The amount is flagged as present but is empty")
+		return 0
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		m.issue(NERISIssueUnmappable, field, source, value, "Could not parse the amount")
+		return 0
+	}
+	return int64(amount)
+}
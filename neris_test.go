@@ -0,0 +1,223 @@
+package emergencyreporting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nerisTestIncident returns an incident that maps to NERIS without any issues.
+func nerisTestIncident() *Incident {
+	transferOfCare := "2026-03-01 10:30:00"
+	return &Incident{
+		IncidentID:        "1",
+		IncidentNumber:    "2026-0001",
+		DispatchRunNumber: "R1",
+		Exposures: []*Exposure{
+			{
+				ExposureID:               "10",
+				IncidentType:             "111",
+				AidGivenOrReceived:       "1",
+				PrimaryActionTaken:       "11",
+				PSAPDateTime:             "2026-03-01 10:00:00",
+				DispatchNotifiedDateTime: "2026-03-01 10:01:00",
+				Location: &ExposureLocation{
+					LocationType: "1",
+					StreetName:   "123 Main",
+					StreetType:   "St",
+					City:         "Springfield",
+					State:        "IL",
+					ZipCode:      "62701",
+				},
+				Apparatuses: []*ExposureApparatus{
+					{ApparatusID: "A1", AgencyApparatusID: "E1", DispatchDateTime: "2026-03-01 10:02:00", ResponseModeToScene: "1", TransferOfPatientCareDateTime: &transferOfCare},
+				},
+				CrewMembers: []*CrewMember{
+					{UserID: "100", ApparatusID: "A1"},
+					{UserID: "101", ApparatusID: "A1"},
+					{UserID: "102", ApparatusID: "A2"},
+				},
+			},
+		},
+	}
+}
+
+func TestNERISFromIncident(t *testing.T) {
+	options := NERISOptions{DepartmentNERISID: "FD12345", Location: time.UTC}
+
+	export := NERISFromIncident(nerisTestIncident(), options)
+	if len(export.Issues) != 0 {
+		t.Errorf("Unexpected issues: %+v", export.Issues)
+	}
+	if export.IncidentID != "1" {
+		t.Errorf("Expected incident ID 1; got %s", export.IncidentID)
+	}
+
+	expected := &NERISIncident{
+		Base: NERISBase{
+			DepartmentNERISID: "FD12345",
+			IncidentNumber:    "2026-0001",
+			Location: NERISLocation{
+				Number:                   "123",
+				Street:                   "Main",
+				StreetPostfix:            "St",
+				IncorporatedMunicipality: "Springfield",
+				State:                    "IL",
+				PostalCode:               "62701",
+			},
+		},
+		IncidentTypes: []NERISIncidentType{{Type: "FIRE||STRUCTURE_FIRE", Primary: true}},
+		Dispatch: NERISDispatch{
+			IncidentNumber: "R1",
+			CallArrival:    "2026-03-01T10:00:00Z",
+			CallAnswered:   "2026-03-01T10:00:00Z",
+			CallCreate:     "2026-03-01T10:01:00Z",
+			UnitResponses: []NERISUnitResponse{
+				{ReportedUnitID: "E1", Staffing: 2, ResponseMode: "EMERGENT", Dispatch: "2026-03-01T10:02:00Z", UnitTransferOfCare: "2026-03-01T10:30:00Z"},
+			},
+		},
+		Aids: []NERISAid{{AidDirection: "RECEIVED", AidType: "MUTUAL"}},
+		ActionsTactics: &NERISActionsTactics{
+			ActionNoAction: NERISActionNoAction{Type: "ACTION", Actions: []string{"SUPPRESSION||EXTINGUISHMENT"}},
+		},
+	}
+	if !reflect.DeepEqual(export.Incident, expected) {
+		t.Errorf("Expected:\n%+v\nGot:\n%+v", expected, export.Incident)
+	}
+}
+
+func TestNERISFromIncidentIssues(t *testing.T) {
+	rows := []struct {
+		description string
+		modify      func(incident *Incident, options *NERISOptions)
+		expected    []string // The kind and field of each issue.
+	}{
+		{
+			description: "No department NERIS ID",
+			modify: func(incident *Incident, options *NERISOptions) {
+				options.DepartmentNERISID = ""
+			},
+			expected: []string{"missing base.department_neris_id"},
+		},
+		{
+			description: "No exposures",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures = nil
+			},
+			expected: []string{"missing incident_types", "missing base.location"},
+		},
+		{
+			description: "Unmappable incident type",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].IncidentType = "324"
+			},
+			expected: []string{"unmappable incident_types"},
+		},
+		{
+			description: "No state",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].Location.State = ""
+			},
+			expected: []string{"missing base.location.state"},
+		},
+		{
+			description: "Bad date/time",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].PSAPDateTime = "yesterday"
+			},
+			expected: []string{"unmappable dispatch.call_arrival"},
+		},
+		{
+			description: "No apparatuses",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].Apparatuses = nil
+			},
+			expected: []string{"missing dispatch.unit_responses"},
+		},
+		{
+			description: "Unknown response mode and no crew",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].Apparatuses[0].ResponseModeToScene = "9"
+				incident.Exposures[0].CrewMembers = nil
+			},
+			expected: []string{"unmappable dispatch.unit_responses[A1].response_mode", "missing dispatch.unit_responses[A1].staffing"},
+		},
+		{
+			description: "Unknown aid and action",
+			modify: func(incident *Incident, options *NERISOptions) {
+				incident.Exposures[0].AidGivenOrReceived = "9"
+				incident.Exposures[0].SecondaryActionTaken = "99"
+			},
+			expected: []string{"unmappable aids", "unmappable actions_tactics.action_noaction.actions"},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			options := NERISOptions{DepartmentNERISID: "FD12345"}
+			incident := nerisTestIncident()
+			row.modify(incident, &options)
+
+			export := NERISFromIncident(incident, options)
+			actual := []string{}
+			for _, issue := range export.Issues {
+				actual = append(actual, issue.Kind+" "+issue.Field)
+			}
+			if !reflect.DeepEqual(actual, row.expected) {
+				t.Errorf("Expected %v; got %v", row.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNERISIncidentType(t *testing.T) {
+	rows := []struct {
+		code     string
+		expected string
+		issue    string
+	}{
+		{code: "111", expected: "FIRE||STRUCTURE_FIRE"},
+		{code: "113", expected: "FIRE||STRUCTURE_FIRE||CONFINED_COOKING_APPLIANCE_FIRE"},
+		{code: "118", expected: "FIRE||STRUCTURE_FIRE"},
+		{code: "400", expected: "HAZSIT"},
+		{code: "324", issue: NERISIssueUnmappable},
+		{code: "000", issue: NERISIssueUnmappable},
+		{code: "", issue: NERISIssueMissing},
+	}
+	for _, row := range rows {
+		t.Run(row.code, func(t *testing.T) {
+			m := &nerisMapper{}
+			actual := m.incidentType(row.code)
+			if actual != row.expected {
+				t.Errorf("Expected %q; got %q", row.expected, actual)
+			}
+			var issue string
+			if len(m.issues) > 0 {
+				issue = m.issues[0].Kind
+			}
+			if issue != row.issue {
+				t.Errorf("Expected issue %q; got %q", row.issue, issue)
+			}
+		})
+	}
+}
+
+func TestSplitStreetNumber(t *testing.T) {
+	rows := []struct {
+		input  string
+		number string
+		street string
+	}{
+		{input: "123 Main", number: "123", street: "Main"},
+		{input: "12B Elm", number: "12B", street: "Elm"},
+		{input: " 9  Oak Hill ", number: "9", street: "Oak Hill"},
+		{input: "Main", number: "", street: "Main"},
+		{input: "1st", number: "", street: "1st"},
+		{input: "", number: "", street: ""},
+	}
+	for _, row := range rows {
+		number, street := splitStreetNumber(row.input)
+		if number != row.number || street != row.street {
+			t.Errorf("%q: expected %q and %q; got %q and %q", row.input, row.number, row.street, number, street)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Occupancy struct {
+	OccupancyID      string `json:"occupancyID,omitempty"` // Not used for creating occupancies.
+	OccupancyNumber  string `json:"occupancyNumber"`
+	OccupancyName    string `json:"occupancyName"` // Usually the business name.
+	OccupancyType    string `json:"occupancyType"` // The IFC occupancy classification, such as "B" or "R-2".
+	PropertyUse      string `json:"propertyUse"`   // 3-digit code, as on `ExposureLocation`.
+	StreetNumber     string `json:"streetNumber"`
+	StreetPrefix     string `json:"streetPrefix"`
+	StreetName       string `json:"streetName"`
+	StreetType       string `json:"streetType"`
+	StreetSuffix     string `json:"streetSuffix"`
+	AptOrSuiteNumber string `json:"aptOrSuiteNumber"`
+	City             string `json:"city"`
+	State            string `json:"state"`
+	ZipCode          string `json:"zipCode"`
+	Latitude         string `json:"latitude"`
+	Longitude        string `json:"longitude"`
+	StationID        string `json:"stationID"`
+	SquareFootage    string `json:"squareFootage"`
+	NumberOfStories  string `json:"numberOfStories"`
+	ConstructionType string `json:"constructionType"`
+	Sprinklered      string `json:"sprinklered"` // "1" if the building has sprinklers.
+	FireAlarm        string `json:"fireAlarm"`   // "1" if the building has a fire alarm.
+	KnoxBox          string `json:"knoxBox"`     // "1" if the building has a Knox box.
+	IsActive         string `json:"isActive"`
+	Notes            string `json:"notes"`
+	RowVersion       string `json:"rowVersion,omitempty"` // Not used for creating occupancies.
+}
+
+type GetOccupanciesResponse struct {
+	TotalRows   string       `json:"totalRows"`
+	Occupancies []*Occupancy `json:"occupancies"`
+}
+
+type GetOccupancyResponse struct {
+	Occupancy *Occupancy `json:"occupancy"`
+}
+
+type PostOccupancyResponse struct {
+	OccupancyID string `json:"occupancyID"`
+}
+
+type PatchOccupancyRequest struct {
+	OccupancyNumber  *string `json:"occupancyNumber,omitempty"`
+	OccupancyName    *string `json:"occupancyName,omitempty"`
+	OccupancyType    *string `json:"occupancyType,omitempty"`
+	PropertyUse      *string `json:"propertyUse,omitempty"`
+	StreetNumber     *string `json:"streetNumber,omitempty"`
+	StreetPrefix     *string `json:"streetPrefix,omitempty"`
+	StreetName       *string `json:"streetName,omitempty"`
+	StreetType       *string `json:"streetType,omitempty"`
+	StreetSuffix     *string `json:"streetSuffix,omitempty"`
+	AptOrSuiteNumber *string `json:"aptOrSuiteNumber,omitempty"`
+	City             *string `json:"city,omitempty"`
+	State            *string `json:"state,omitempty"`
+	ZipCode          *string `json:"zipCode,omitempty"`
+	Latitude         *string `json:"latitude,omitempty"`
+	Longitude        *string `json:"longitude,omitempty"`
+	StationID        *string `json:"stationID,omitempty"`
+	SquareFootage    *string `json:"squareFootage,omitempty"`
+	NumberOfStories  *string `json:"numberOfStories,omitempty"`
+	ConstructionType *string `json:"constructionType,omitempty"`
+	Sprinklered      *string `json:"sprinklered,omitempty"`
+	FireAlarm        *string `json:"fireAlarm,omitempty"`
+	KnoxBox          *string `json:"knoxBox,omitempty"`
+	IsActive         *string `json:"isActive,omitempty"`
+	Notes            *string `json:"notes,omitempty"`
+}
+
+type PatchOccupancyResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type OccupancyContact struct {
+	OccupancyContactID string `json:"occupancyContactID,omitempty"` // Not used for creating contacts.
+	OccupancyID        string `json:"occupancyID,omitempty"`
+	ContactType        string `json:"contactType"` // Such as "Owner", "Manager", or "Emergency".
+	FirstName          string `json:"firstName"`
+	LastName           string `json:"lastName"`
+	BusinessName       string `json:"businessName"`
+	Phone              string `json:"phone"`
+	AlternatePhone     string `json:"alternatePhone"`
+	Email              string `json:"email"`
+	Notes              string `json:"notes"`
+	RowVersion         string `json:"rowVersion,omitempty"`
+}
+
+type GetOccupancyContactsResponse struct {
+	Contacts []*OccupancyContact `json:"contacts"`
+}
+
+type PostOccupancyContactResponse struct {
+	OccupancyContactID string `json:"occupancyContactID"`
+}
+
+type OccupancyHazard struct {
+	OccupancyHazardID string `json:"occupancyHazardID,omitempty"` // Not used for creating hazards.
+	OccupancyID       string `json:"occupancyID,omitempty"`
+	HazardType        string `json:"hazardType"` // Such as "Hazardous Materials" or "Structural".
+	Description       string `json:"description"`
+	Location          string `json:"location"` // Where the hazard is in the building.
+	Quantity          string `json:"quantity"`
+	UNNumber          string `json:"unNumber"` // For hazardous materials.
+	Notes             string `json:"notes"`
+	RowVersion        string `json:"rowVersion,omitempty"`
+}
+
+type GetOccupancyHazardsResponse struct {
+	Hazards []*OccupancyHazard `json:"hazards"`
+}
+
+type PostOccupancyHazardResponse struct {
+	OccupancyHazardID string `json:"occupancyHazardID"`
+}
+
+type OccupancyPrePlan struct {
+	PrePlanID              string `json:"prePlanID,omitempty"` // Not used for creating pre-plans.
+	OccupancyID            string `json:"occupancyID,omitempty"`
+	PrePlanDateTime        string `json:"prePlanDateTime"`
+	ReviewDateTime         string `json:"reviewDateTime"`
+	PreparedByUserID       string `json:"preparedByUserID"`
+	AccessInstructions     string `json:"accessInstructions"`
+	WaterSupply            string `json:"waterSupply"`
+	UtilityShutoffs        string `json:"utilityShutoffs"`
+	FireProtectionSystems  string `json:"fireProtectionSystems"`
+	TacticalConsiderations string `json:"tacticalConsiderations"`
+	Notes                  string `json:"notes"`
+	RowVersion             string `json:"rowVersion,omitempty"`
+}
+
+type GetOccupancyPrePlansResponse struct {
+	PrePlans []*OccupancyPrePlan `json:"prePlans"`
+}
+
+type PostOccupancyPrePlanResponse struct {
+	PrePlanID string `json:"prePlanID"`
+}
+
+// GetOccupancies gets a page of occupancies.
+func (c *Client) GetOccupancies(ctx context.Context, options map[string]string) (*GetOccupanciesResponse, error) {
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyoccupancies/occupancies"
+
+	var parsedResponse GetOccupanciesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the occupancies: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllOccupancies gets every page of occupancies.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllOccupancies(ctx context.Context, options map[string]string) ([]*Occupancy, error) {
+	var occupancies []*Occupancy
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetOccupancies(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		occupancies = append(occupancies, response.Occupancies...)
+		return len(response.Occupancies), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return occupancies, nil
+}
+
+// GetOccupancy gets an occupancy.
+func (c *Client) GetOccupancy(ctx context.Context, occupancyID string) (*GetOccupancyResponse, error) {
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID)
+
+	var parsedResponse GetOccupancyResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the occupancy: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostOccupancy creates an occupancy.
+func (c *Client) PostOccupancy(ctx context.Context, occupancy Occupancy) (*PostOccupancyResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies
+
+	targetURL := "/agencyoccupancies/occupancies"
+
+	jsonInput, err := json.Marshal(occupancy)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostOccupancyResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the occupancy: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchOccupancy updates an occupancy.
+func (c *Client) PatchOccupancy(ctx context.Context, occupancyID string, rowVersion string, payload PatchOccupancyRequest) (*PatchOccupancyResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchOccupancyResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the occupancy: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetOccupancyContacts gets the contacts for an occupancy.
+func (c *Client) GetOccupancyContacts(ctx context.Context, occupancyID string, options map[string]string) (*GetOccupancyContactsResponse, error) {
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/contacts[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/contacts"
+
+	var parsedResponse GetOccupancyContactsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the occupancy contacts: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostOccupancyContact adds a contact to an occupancy.
+func (c *Client) PostOccupancyContact(ctx context.Context, occupancyID string, contact OccupancyContact) (*PostOccupancyContactResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/contacts
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/contacts"
+
+	jsonInput, err := json.Marshal(contact)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostOccupancyContactResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the occupancy contact: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetOccupancyHazards gets the hazards for an occupancy.
+func (c *Client) GetOccupancyHazards(ctx context.Context, occupancyID string, options map[string]string) (*GetOccupancyHazardsResponse, error) {
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/hazards[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/hazards"
+
+	var parsedResponse GetOccupancyHazardsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the occupancy hazards: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostOccupancyHazard adds a hazard to an occupancy.
+func (c *Client) PostOccupancyHazard(ctx context.Context, occupancyID string, hazard OccupancyHazard) (*PostOccupancyHazardResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/hazards
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/hazards"
+
+	jsonInput, err := json.Marshal(hazard)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostOccupancyHazardResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the occupancy hazard: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetOccupancyPrePlans gets the pre-plans for an occupancy.
+func (c *Client) GetOccupancyPrePlans(ctx context.Context, occupancyID string, options map[string]string) (*GetOccupancyPrePlansResponse, error) {
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/preplans[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/preplans"
+
+	var parsedResponse GetOccupancyPrePlansResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the occupancy pre-plans: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostOccupancyPrePlan adds a pre-plan to an occupancy.
+func (c *Client) PostOccupancyPrePlan(ctx context.Context, occupancyID string, prePlan OccupancyPrePlan) (*PostOccupancyPrePlanResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyoccupancies/occupancies/{occupancyID}/preplans
+
+	targetURL := "/agencyoccupancies/occupancies/" + url.PathEscape(occupancyID) + "/preplans"
+
+	jsonInput, err := json.Marshal(prePlan)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostOccupancyPrePlanResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the occupancy pre-plan: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// Occupancy match types.
+const (
+	OccupancyMatchAddress = "address" // The street address (and unit, city, and ZIP code, where known) matched.
+	OccupancyMatchNearest = "nearest" // The occupancy was the closest one to the location's coordinates.
+)
+
+// OccupancyMatch is the occupancy that an exposure location was linked to.
+type OccupancyMatch struct {
+	Occupancy *Occupancy `json:"occupancy"`
+	MatchType string     `json:"matchType"`          // One of the `OccupancyMatch*` constants.
+	Distance  *float64   `json:"distance,omitempty"` // In meters, if both have coordinates.
+}
+
+// MatchOccupancy links an exposure location to one of the occupancies.
+//
+// An occupancy whose address matches wins; otherwise, the nearest occupancy within the maximum distance
+// (in meters) is used.  If the maximum distance is zero, then only addresses are matched.  This returns
+// nil if nothing matches.
+//
+// Emergency Reporting has no separate street number field on the location, so the street number is
+// expected to be part of the street name; it is split off so that the parts line up with the occupancy's.
+func MatchOccupancy(location *ExposureLocation, occupancies []*Occupancy, maximumDistance float64) *OccupancyMatch {
+	if location == nil {
+		return nil
+	}
+
+	locationNumber, locationStreet := splitStreetNumber(location.StreetName)
+	locationAddress := normalizeAddress(locationNumber, location.StreetPrefix, locationStreet, location.StreetType, location.StreetSuffix)
+	locationPoint := NewGeoJSONPoint(location.Latitude, location.Longitude)
+
+	var nearest *OccupancyMatch
+	for _, occupancy := range occupancies {
+		if occupancy == nil {
+			continue
+		}
+
+		var distance *float64
+		if occupancyPoint := NewGeoJSONPoint(occupancy.Latitude, occupancy.Longitude); locationPoint != nil && occupancyPoint != nil {
+			value := haversineDistance(locationPoint.Coordinates, occupancyPoint.Coordinates)
+			distance = &value
+		}
+
+		occupancyNumber, occupancyStreet := occupancy.StreetNumber, occupancy.StreetName
+		if occupancyNumber == "" {
+			occupancyNumber, occupancyStreet = splitStreetNumber(occupancyStreet)
+		}
+		if locationAddress != "" && locationAddress == normalizeAddress(occupancyNumber, occupancy.StreetPrefix, occupancyStreet, occupancy.StreetType, occupancy.StreetSuffix) &&
+			sameAddressPart(location.AptOrSuiteNumber, occupancy.AptOrSuiteNumber) &&
+			sameAddressPart(location.City, occupancy.City) &&
+			sameAddressPart(location.ZipCode, occupancy.ZipCode) {
+			return &OccupancyMatch{
+				Occupancy: occupancy,
+				MatchType: OccupancyMatchAddress,
+				Distance:  distance,
+			}
+		}
+
+		if distance == nil || *distance > maximumDistance {
+			continue
+		}
+		if nearest == nil || *distance < *nearest.Distance {
+			nearest = &OccupancyMatch{
+				Occupancy: occupancy,
+				MatchType: OccupancyMatchNearest,
+				Distance:  distance,
+			}
+		}
+	}
+	return nearest
+}
+
+// FindOccupancyForLocation gets the occupancies and links the exposure location to one of them.
+//
+// See `MatchOccupancy`; this returns nil if nothing matches.  To link many locations, get the occupancies
+// once with `GetAllOccupancies` and call `MatchOccupancy` for each one.
+func (c *Client) FindOccupancyForLocation(ctx context.Context, location *ExposureLocation, maximumDistance float64) (*OccupancyMatch, error) {
+	occupancies, err := c.GetAllOccupancies(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return MatchOccupancy(location, occupancies, maximumDistance), nil
+}
+
+// addressAbbreviations are the USPS abbreviations for the common street words.
+var addressAbbreviations = map[string]string{
+	"north":     "n",
+	"south":     "s",
+	"east":      "e",
+	"west":      "w",
+	"northeast": "ne",
+	"northwest": "nw",
+	"southeast": "se",
+	"southwest": "sw",
+	"avenue":    "ave",
+	"boulevard": "blvd",
+	"circle":    "cir",
+	"court":     "ct",
+	"drive":     "dr",
+	"highway":   "hwy",
+	"lane":      "ln",
+	"parkway":   "pkwy",
+	"place":     "pl",
+	"road":      "rd",
+	"street":    "st",
+	"terrace":   "ter",
+	"trail":     "trl",
+}
+
+// normalizeAddress joins the parts of a street address into a lowercase form without punctuation
+// and with the common street words abbreviated.
+func normalizeAddress(parts ...string) string {
+	var words []string
+	for _, part := range parts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(part), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		}) {
+			if abbreviation, ok := addressAbbreviations[word]; ok {
+				word = abbreviation
+			}
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// sameAddressPart returns true if the parts match, or if either of them is unknown.
+func sameAddressPart(a string, b string) bool {
+	a = normalizeAddress(a)
+	b = normalizeAddress(b)
+	return a == "" || b == "" || a == b
+}
+
+// earthRadius is the mean radius of the Earth, in meters.
+const earthRadius = 6371000.0
+
+// haversineDistance returns the distance, in meters, between two longitude/latitude points.
+func haversineDistance(a []float64, b []float64) float64 {
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+	latitudeA := toRadians(a[1])
+	latitudeB := toRadians(b[1])
+	deltaLatitude := latitudeB - latitudeA
+	deltaLongitude := toRadians(b[0] - a[0])
+
+	h := math.Pow(math.Sin(deltaLatitude/2), 2) + math.Cos(latitudeA)*math.Cos(latitudeB)*math.Pow(math.Sin(deltaLongitude/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
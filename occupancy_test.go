@@ -0,0 +1,84 @@
+package emergencyreporting
+
+import (
+	"testing"
+)
+
+func TestMatchOccupancy(t *testing.T) {
+	occupancies := []*Occupancy{
+		{OccupancyID: "1", StreetNumber: "123", StreetPrefix: "N", StreetName: "Main", StreetType: "St", AptOrSuiteNumber: "A", City: "Springfield", ZipCode: "62701", Latitude: "39.7800", Longitude: "-89.6500"},
+		{OccupancyID: "2", StreetNumber: "123", StreetPrefix: "N", StreetName: "Main", StreetType: "St", AptOrSuiteNumber: "B", City: "Springfield", Latitude: "39.7810", Longitude: "-89.6500"},
+		{OccupancyID: "3", StreetName: "500 Oak Avenue", City: "Springfield", Latitude: "39.7900", Longitude: "-89.6600"},
+	}
+
+	rows := []struct {
+		description     string
+		location        *ExposureLocation
+		maximumDistance float64
+		occupancyID     string
+		matchType       string
+	}{
+		{
+			description: "No location",
+		},
+		{
+			description: "Number in the street name",
+			location:    &ExposureLocation{StreetPrefix: "North", StreetName: "123 Main", StreetType: "Street", City: "springfield"},
+			occupancyID: "1",
+			matchType:   OccupancyMatchAddress,
+		},
+		{
+			description: "Unit",
+			location:    &ExposureLocation{StreetPrefix: "N", StreetName: "123 Main", StreetType: "St", AptOrSuiteNumber: "b"},
+			occupancyID: "2",
+			matchType:   OccupancyMatchAddress,
+		},
+		{
+			description: "Number in the occupancy's street name",
+			location:    &ExposureLocation{StreetName: "500 Oak", StreetType: "Ave"},
+			occupancyID: "3",
+			matchType:   OccupancyMatchAddress,
+		},
+		{
+			description: "Different number",
+			location:    &ExposureLocation{StreetPrefix: "N", StreetName: "125 Main", StreetType: "St"},
+		},
+		{
+			description: "Different city",
+			location:    &ExposureLocation{StreetPrefix: "N", StreetName: "123 Main", StreetType: "St", City: "Shelbyville"},
+		},
+		{
+			description:     "Nearest",
+			location:        &ExposureLocation{StreetName: "1 Elsewhere", Latitude: "39.7808", Longitude: "-89.6500"},
+			maximumDistance: 500,
+			occupancyID:     "2",
+			matchType:       OccupancyMatchNearest,
+		},
+		{
+			description:     "Too far away",
+			location:        &ExposureLocation{StreetName: "1 Elsewhere", Latitude: "40.0000", Longitude: "-89.6500"},
+			maximumDistance: 500,
+		},
+		{
+			description: "Addresses only",
+			location:    &ExposureLocation{StreetName: "1 Elsewhere", Latitude: "39.7808", Longitude: "-89.6500"},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			match := MatchOccupancy(row.location, occupancies, row.maximumDistance)
+			if row.occupancyID == "" {
+				if match != nil {
+					t.Fatalf("Expected no match; got occupancy %s (%s)", match.Occupancy.OccupancyID, match.MatchType)
+				}
+				return
+			}
+			if match == nil {
+				t.Fatalf("Expected occupancy %s; got no match", row.occupancyID)
+			}
+			if match.Occupancy.OccupancyID != row.occupancyID || match.MatchType != row.matchType {
+				t.Errorf("Expected occupancy %s (%s); got %s (%s)", row.occupancyID, row.matchType, match.Occupancy.OccupancyID, match.MatchType)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package emergencyreporting
+
+import (
+	"strconv"
+)
+
+// DefaultPageSize is the page size that the "GetAll" functions use if the options don't have a "limit".
+const DefaultPageSize = 100
+
+// paginate calls fetch for each page, starting at the "offset" in the options (if any).
+//
+// The fetch function gets a copy of the options with "limit" and "offset" set, and it returns the number
+// of records on the page; this stops after the first page that isn't full.
+func paginate(options map[string]string, fetch func(pageOptions map[string]string) (int, error)) error {
+	pageSize := DefaultPageSize
+	if limit, err := strconv.Atoi(options["limit"]); err == nil && limit > 0 {
+		pageSize = limit
+	}
+	offset := 0
+	if value, err := strconv.Atoi(options["offset"]); err == nil && value > 0 {
+		offset = value
+	}
+
+	for {
+		pageOptions := map[string]string{}
+		for key, value := range options {
+			pageOptions[key] = value
+		}
+		pageOptions["limit"] = strconv.Itoa(pageSize)
+		pageOptions["offset"] = strconv.Itoa(offset)
+
+		count, err := fetch(pageOptions)
+		if err != nil {
+			return err
+		}
+		if count < pageSize {
+			return nil
+		}
+		offset += count
+	}
+}
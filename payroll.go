@@ -0,0 +1,424 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Paygrade struct {
+	PaygradeID   string `json:"paygradeID"`
+	PaygradeName string `json:"paygradeName"`
+	Rate         string `json:"rate"` // Per hour.
+	IsActive     string `json:"isActive"`
+}
+
+type GetPaygradesResponse struct {
+	Paygrades []*Paygrade `json:"paygrades"`
+}
+
+// PayrollExport is a payroll period that has been sent to the payroll system.
+type PayrollExport struct {
+	ExportID         string `json:"exportID,omitempty"` // Not used for creating exports.
+	PeriodStartDate  string `json:"periodStartDate"`
+	PeriodEndDate    string `json:"periodEndDate"`
+	ExportedDateTime string `json:"exportedDateTime"`
+	TotalHours       string `json:"totalHours"`
+	TotalAmount      string `json:"totalAmount"`
+}
+
+type GetPayrollExportsResponse struct {
+	Exports []*PayrollExport `json:"exports"`
+}
+
+type PostPayrollExportResponse struct {
+	ExportID string `json:"exportID"`
+}
+
+// GetPaygrades gets the pay grades.
+func (c *Client) GetPaygrades(ctx context.Context, options map[string]string) (*GetPaygradesResponse, error) {
+	// https://data.emergencyreporting.com/agencypayroll/paygrades[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencypayroll/paygrades"
+
+	var parsedResponse GetPaygradesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the pay grades: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllPaygrades gets every page of pay grades.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllPaygrades(ctx context.Context, options map[string]string) ([]*Paygrade, error) {
+	var paygrades []*Paygrade
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetPaygrades(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		paygrades = append(paygrades, response.Paygrades...)
+		return len(response.Paygrades), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paygrades, nil
+}
+
+// GetPayrollExports gets the payroll periods that have been exported.
+func (c *Client) GetPayrollExports(ctx context.Context, options map[string]string) (*GetPayrollExportsResponse, error) {
+	// https://data.emergencyreporting.com/agencypayroll/exports[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencypayroll/exports"
+
+	var parsedResponse GetPayrollExportsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the payroll exports: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostPayrollExport records that a payroll period has been exported.
+func (c *Client) PostPayrollExport(ctx context.Context, export PayrollExport) (*PostPayrollExportResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencypayroll/exports
+
+	targetURL := "/agencypayroll/exports"
+
+	jsonInput, err := json.Marshal(export)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostPayrollExportResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the payroll export: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PayrollOptions are the options for `Payroll`.
+type PayrollOptions struct {
+	From time.Time // If set, the events that start before this are skipped.
+	To   time.Time // If set, the events that start at or after this are skipped.
+}
+
+// PayrollLine is what a member gets paid at one pay grade.
+type PayrollLine struct {
+	UserID            string  `json:"userID"`
+	FullName          string  `json:"fullName"`
+	AgencyPersonnelID string  `json:"agencyPersonnelID"`
+	PaygradeName      string  `json:"paygradeName"`
+	Rate              float64 `json:"rate"` // Zero if neither the attendee nor the user has a pay grade.
+	Events            int     `json:"events"`
+	Hours             float64 `json:"hours"`
+	Amount            float64 `json:"amount"` // The hours times the rate, rounded to the cent.
+}
+
+// PayrollProblem is an attendee who could not be paid.
+type PayrollProblem struct {
+	EventID    string
+	AttendeeID string
+	UserID     string
+	Err        error // Such as hours or a rate that can't be parsed, or a pay grade that doesn't exist.
+}
+
+// PayrollError is the error from `Payroll` when some of the attendees could not be paid.
+type PayrollError struct {
+	Problems []*PayrollProblem
+}
+
+// Error returns a summary of all of the problems.
+func (e *PayrollError) Error() string {
+	var parts []string
+	for _, problem := range e.Problems {
+		parts = append(parts, fmt.Sprintf("event %s, user %s: %v", problem.EventID, problem.UserID, problem.Err))
+	}
+	return fmt.Sprintf("%d attendees could not be paid: %s", len(e.Problems), strings.Join(parts, "; "))
+}
+
+// Payroll totals the event hours for each member and pay grade, sorted by the member's name and then the
+// pay grade name.
+//
+// Only attendees who attended (or have no status) count, and events with `IsPaid` set to "0" are skipped.
+// An attendee's hours default to the event's hours and then to the length of the event.  An attendee's
+// pay grade rate defaults to the rate of the pay grade with that name; an attendee without a pay grade
+// gets the user's default event pay grade (and its rate, or else the rate of the pay grade with that name).
+// Date/times without a time zone are in the location of `From` (or `To`).
+//
+// Attendees whose hours or rate can't be worked out are left out, and the error is a `*PayrollError`
+// listing them; the lines for everyone else are still returned.
+func Payroll(events []*Event, attendees []*EventAttendee, users []*User, paygrades []*Paygrade, options PayrollOptions) ([]*PayrollLine, error) {
+	usersByID := map[string]*User{}
+	for _, user := range users {
+		if user != nil {
+			usersByID[user.UserID] = user
+		}
+	}
+	paygradesByName := map[string]*Paygrade{}
+	for _, paygrade := range paygrades {
+		if paygrade != nil {
+			paygradesByName[strings.ToLower(strings.TrimSpace(paygrade.PaygradeName))] = paygrade
+		}
+	}
+	location := options.From.Location()
+	if options.From.IsZero() {
+		location = options.To.Location()
+	}
+
+	eventsByID := map[string]*Event{}
+	for _, event := range events {
+		if event == nil || event.IsPaid == "0" {
+			continue
+		}
+		if !options.From.IsZero() || !options.To.IsZero() {
+			when, ok := parseDateTime(event.StartDateTime, location)
+			if !ok || (!options.From.IsZero() && when.Before(options.From)) || (!options.To.IsZero() && !when.Before(options.To)) {
+				continue
+			}
+		}
+		eventsByID[event.EventID] = event
+	}
+
+	type key struct {
+		userID       string
+		paygradeName string
+		rate         float64
+	}
+	totals := map[key]*PayrollLine{}
+	results := []*PayrollLine{}
+	var problems []*PayrollProblem
+	for _, attendee := range attendees {
+		if attendee == nil || (attendee.Status != "" && attendee.Status != EventAttendeeStatusAttended) {
+			continue
+		}
+		event, ok := eventsByID[attendee.EventID]
+		if !ok {
+			continue
+		}
+
+		user := usersByID[attendee.UserID]
+		hours, err := eventHours(event, attendee, location)
+		var paygradeName string
+		var rate float64
+		if err == nil {
+			paygradeName, rate, err = payrollRate(attendee, user, paygradesByName)
+		}
+		if err != nil {
+			problems = append(problems, &PayrollProblem{
+				EventID:    attendee.EventID,
+				AttendeeID: attendee.AttendeeID,
+				UserID:     attendee.UserID,
+				Err:        err,
+			})
+			continue
+		}
+
+		total, ok := totals[key{attendee.UserID, paygradeName, rate}]
+		if !ok {
+			total = &PayrollLine{
+				UserID:       attendee.UserID,
+				PaygradeName: paygradeName,
+				Rate:         rate,
+			}
+			if user != nil {
+				total.FullName = user.FullName
+				if user.AgencyPersonnelID != nil {
+					total.AgencyPersonnelID = *user.AgencyPersonnelID
+				}
+			}
+			totals[key{attendee.UserID, paygradeName, rate}] = total
+			results = append(results, total)
+		}
+		total.Events++
+		total.Hours += hours
+	}
+
+	for _, result := range results {
+		result.Amount = math.Round(result.Hours*result.Rate*100) / 100
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].FullName != results[j].FullName {
+			return results[i].FullName < results[j].FullName
+		}
+		if results[i].UserID != results[j].UserID {
+			return results[i].UserID < results[j].UserID
+		}
+		return results[i].PaygradeName < results[j].PaygradeName
+	})
+	if len(problems) > 0 {
+		return results, &PayrollError{Problems: problems}
+	}
+	return results, nil
+}
+
+// eventHours returns the hours that the attendee gets for the event.
+func eventHours(event *Event, attendee *EventAttendee, location *time.Location) (float64, error) {
+	for _, value := range []string{attendee.Hours, event.Hours} {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		hours, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || hours < 0 {
+			return 0, fmt.Errorf("invalid hours: %q", value)
+		}
+		return hours, nil
+	}
+	start, ok := parseDateTime(event.StartDateTime, location)
+	if !ok {
+		return 0, fmt.Errorf("no hours, and the event's start is invalid: %q", event.StartDateTime)
+	}
+	end, ok := parseDateTime(event.EndDateTime, location)
+	if !ok || end.Before(start) {
+		return 0, fmt.Errorf("no hours, and the event's end is invalid: %q", event.EndDateTime)
+	}
+	return end.Sub(start).Hours(), nil
+}
+
+// payrollRate returns the pay grade name and rate for the attendee.
+//
+// The user's default pay grade name and rate are only used together, so that an attendee with their own
+// pay grade never gets the user's default rate.
+func payrollRate(attendee *EventAttendee, user *User, paygradesByName map[string]*Paygrade) (string, float64, error) {
+	name := strings.TrimSpace(attendee.PaygradeName)
+	value := strings.TrimSpace(attendee.PaygradeRate)
+	if name == "" && value == "" && user != nil {
+		if user.DefaultEventPaygradeName != nil {
+			name = strings.TrimSpace(*user.DefaultEventPaygradeName)
+		}
+		if user.DefaultEventPaygradeRate != nil {
+			value = strings.TrimSpace(*user.DefaultEventPaygradeRate)
+		}
+	}
+	if value == "" {
+		if name == "" {
+			return "", 0, nil
+		}
+		paygrade, ok := paygradesByName[strings.ToLower(name)]
+		if !ok {
+			return name, 0, fmt.Errorf("unknown pay grade: %q", name)
+		}
+		value = paygrade.Rate
+	}
+	rate, ok := parseRate(value)
+	if !ok {
+		return name, 0, fmt.Errorf("invalid pay rate for %q: %q", name, value)
+	}
+	return name, rate, nil
+}
+
+// parseRate parses a pay rate, such as "15", "15.50", or "$15.50".
+func parseRate(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "$")
+	value = strings.ReplaceAll(value, ",", "")
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 {
+		return 0, false
+	}
+	return rate, true
+}
+
+// payrollCSVHeader is the header row of `WritePayrollCSV`.
+var payrollCSVHeader = []string{"agencyPersonnelID", "fullName", "userID", "paygradeName", "rate", "events", "hours", "amount"}
+
+// WritePayrollCSV writes the payroll lines as CSV (with a header row) for a payroll system.
+//
+// The rates, hours, and amounts have two decimal places.
+func WritePayrollCSV(w io.Writer, lines []*PayrollLine) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(payrollCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		err = writer.Write([]string{
+			line.AgencyPersonnelID,
+			line.FullName,
+			line.UserID,
+			line.PaygradeName,
+			strconv.FormatFloat(line.Rate, 'f', 2, 64),
+			strconv.Itoa(line.Events),
+			strconv.FormatFloat(line.Hours, 'f', 2, 64),
+			strconv.FormatFloat(line.Amount, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// GetPayroll gets the events in the period (along with their attendees), the users, and the pay grades, and
+// returns the payroll.
+//
+// See `Payroll`; like it, this returns the lines along with a `*PayrollError` if some attendees could not be paid.
+func (c *Client) GetPayroll(ctx context.Context, options PayrollOptions) ([]*PayrollLine, error) {
+	var conditions []string
+	if !options.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("startDateTime ge '%s'", options.From.Format("2006-01-02")))
+	}
+	if !options.To.IsZero() {
+		// The filter is only by day; `Payroll` skips the events on the last day that start at or after `To`.
+		conditions = append(conditions, fmt.Sprintf("startDateTime lt '%s'", options.To.AddDate(0, 0, 1).Format("2006-01-02")))
+	}
+	events, err := c.GetAllEvents(ctx, map[string]string{"filter": strings.Join(conditions, " and ")})
+	if err != nil {
+		return nil, err
+	}
+
+	var attendees []*EventAttendee
+	for _, event := range events {
+		if event.IsPaid == "0" {
+			continue
+		}
+		eventAttendees, err := c.GetAllEventAttendees(ctx, event.EventID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, attendee := range eventAttendees {
+			if attendee.EventID == "" {
+				attendee.EventID = event.EventID
+			}
+			attendees = append(attendees, attendee)
+		}
+	}
+
+	users, err := c.GetAllUsers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	paygrades, err := c.GetAllPaygrades(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return Payroll(events, attendees, users, paygrades, options)
+}
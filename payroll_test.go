@@ -0,0 +1,127 @@
+package emergencyreporting
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPayroll(t *testing.T) {
+	stringPointer := func(value string) *string {
+		return &value
+	}
+
+	events := []*Event{
+		{EventID: "1", StartDateTime: "2026-03-01 09:00:00", EndDateTime: "2026-03-01 12:30:00"},
+		{EventID: "2", StartDateTime: "2026-03-02 09:00:00", Hours: "2"},
+		{EventID: "3", StartDateTime: "2026-03-03 09:00:00", Hours: "8", IsPaid: "0"},
+		{EventID: "4", StartDateTime: "2026-04-01 09:00:00", Hours: "8"},
+	}
+	users := []*User{
+		{UserID: "100", FullName: "Jane Doe", AgencyPersonnelID: stringPointer("1001"), DefaultEventPaygradeName: stringPointer("Firefighter"), DefaultEventPaygradeRate: stringPointer("20")},
+		{UserID: "101", FullName: "Al Smith", DefaultEventPaygradeName: stringPointer("Probie")},
+		{UserID: "102", FullName: "Bo Jones"},
+	}
+	paygrades := []*Paygrade{
+		{PaygradeID: "1", PaygradeName: "Firefighter", Rate: "18"},
+		{PaygradeID: "2", PaygradeName: "probie", Rate: "$12.50"},
+		{PaygradeID: "3", PaygradeName: "Captain", Rate: "25"},
+	}
+	options := PayrollOptions{
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	rows := []struct {
+		description string
+		attendees   []*EventAttendee
+		expected    []PayrollLine
+		problems    int
+	}{
+		{
+			description: "Nothing",
+			expected:    []PayrollLine{},
+		},
+		{
+			description: "The user's default pay grade",
+			attendees: []*EventAttendee{
+				{EventID: "1", UserID: "100", Status: EventAttendeeStatusAttended},
+				{EventID: "2", UserID: "100"},
+			},
+			expected: []PayrollLine{
+				{UserID: "100", FullName: "Jane Doe", AgencyPersonnelID: "1001", PaygradeName: "Firefighter", Rate: 20, Events: 2, Hours: 5.5, Amount: 110},
+			},
+		},
+		{
+			description: "The attendee's pay grade is looked up by name",
+			attendees: []*EventAttendee{
+				{EventID: "1", UserID: "100", PaygradeName: "captain"},
+				{EventID: "2", UserID: "101", Hours: "1.1"},
+			},
+			expected: []PayrollLine{
+				{UserID: "101", FullName: "Al Smith", PaygradeName: "Probie", Rate: 12.5, Events: 1, Hours: 1.1, Amount: 13.75},
+				{UserID: "100", FullName: "Jane Doe", AgencyPersonnelID: "1001", PaygradeName: "captain", Rate: 25, Events: 1, Hours: 3.5, Amount: 87.5},
+			},
+		},
+		{
+			description: "No pay grade",
+			attendees: []*EventAttendee{
+				{EventID: "2", UserID: "102"},
+			},
+			expected: []PayrollLine{
+				{UserID: "102", FullName: "Bo Jones", Events: 1, Hours: 2},
+			},
+		},
+		{
+			description: "Skipped",
+			attendees: []*EventAttendee{
+				{EventID: "2", UserID: "100", Status: EventAttendeeStatusExcused},
+				{EventID: "2", UserID: "101", Status: EventAttendeeStatusAbsent},
+				{EventID: "3", UserID: "100"},
+				{EventID: "4", UserID: "100"},
+				{EventID: "999", UserID: "100"},
+				nil,
+			},
+			expected: []PayrollLine{},
+		},
+		{
+			description: "Problems",
+			attendees: []*EventAttendee{
+				{EventID: "1", UserID: "100", PaygradeName: "Chief"},
+				{EventID: "1", UserID: "101", Hours: "lots"},
+				{EventID: "1", UserID: "102", PaygradeRate: "-5"},
+				{EventID: "2", UserID: "100", PaygradeRate: "$15.50"},
+			},
+			expected: []PayrollLine{
+				{UserID: "100", FullName: "Jane Doe", AgencyPersonnelID: "1001", Rate: 15.5, Events: 1, Hours: 2, Amount: 31},
+			},
+			problems: 3,
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			results, err := Payroll(events, row.attendees, users, paygrades, options)
+			if row.problems == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			} else {
+				var payrollError *PayrollError
+				if !errors.As(err, &payrollError) {
+					t.Fatalf("Expected a payroll error; got %v", err)
+				}
+				if len(payrollError.Problems) != row.problems {
+					t.Errorf("Expected %d problems; got %d: %v", row.problems, len(payrollError.Problems), err)
+				}
+			}
+			if len(results) != len(row.expected) {
+				t.Fatalf("Expected %d lines; got %d", len(row.expected), len(results))
+			}
+			for index, result := range results {
+				if *result != row.expected[index] {
+					t.Errorf("Line %d: expected %+v; got %+v", index, row.expected[index], *result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Shift struct {
+	ShiftID   string `json:"shiftID"`
+	ShiftName string `json:"shiftName"` // Such as "A Shift".
+	ShiftCode string `json:"shiftCode"` // The code used by `Exposure.ShiftsOrPlatoon`, such as "A".
+	Color     string `json:"color"`
+	IsActive  string `json:"isActive"`
+}
+
+type GetShiftsResponse struct {
+	Shifts []*Shift `json:"shifts"`
+}
+
+// ShiftCalendarDay is the shift that is on duty for a day.
+type ShiftCalendarDay struct {
+	ShiftDate     string `json:"shiftDate"`
+	ShiftID       string `json:"shiftID"`
+	ShiftName     string `json:"shiftName"`
+	StartDateTime string `json:"startDateTime"`
+	EndDateTime   string `json:"endDateTime"` // Usually the next morning for 24-hour shifts.
+}
+
+type GetShiftCalendarResponse struct {
+	TotalRows string              `json:"totalRows"`
+	Days      []*ShiftCalendarDay `json:"days"`
+}
+
+type RosterPosition struct {
+	PositionID   string `json:"positionID"`
+	PositionName string `json:"positionName"` // Such as "Officer", "Driver/Operator", or "Firefighter".
+	Abbreviation string `json:"abbreviation"`
+	IsActive     string `json:"isActive"`
+}
+
+type GetRosterPositionsResponse struct {
+	Positions []*RosterPosition `json:"positions"`
+}
+
+// Roster is the daily roster for a shift at a station.
+type Roster struct {
+	RosterID      string `json:"rosterID,omitempty"` // Not used for creating rosters.
+	RosterDate    string `json:"rosterDate"`
+	ShiftID       string `json:"shiftID"`
+	ShiftName     string `json:"shiftName,omitempty"` // Not used for creating rosters.
+	StationID     string `json:"stationID"`
+	StartDateTime string `json:"startDateTime"`
+	EndDateTime   string `json:"endDateTime"`
+	Notes         string `json:"notes"`
+	RowVersion    string `json:"rowVersion,omitempty"` // Not used for creating rosters.
+}
+
+type GetRostersResponse struct {
+	TotalRows string    `json:"totalRows"`
+	Rosters   []*Roster `json:"rosters"`
+}
+
+type GetRosterResponse struct {
+	Roster *Roster `json:"roster"`
+}
+
+type PostRosterResponse struct {
+	RosterID string `json:"rosterID"`
+}
+
+type PatchRosterRequest struct {
+	RosterDate    *string `json:"rosterDate,omitempty"`
+	ShiftID       *string `json:"shiftID,omitempty"`
+	StationID     *string `json:"stationID,omitempty"`
+	StartDateTime *string `json:"startDateTime,omitempty"`
+	EndDateTime   *string `json:"endDateTime,omitempty"`
+	Notes         *string `json:"notes,omitempty"`
+}
+
+type PatchRosterResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// RosterAssignment is someone's spot on a daily roster.
+//
+// The start and end are only set when they differ from the roster's, such as for someone who came in late.
+type RosterAssignment struct {
+	AssignmentID  string `json:"assignmentID,omitempty"` // Not used for creating assignments.
+	RosterID      string `json:"rosterID,omitempty"`
+	UserID        string `json:"userID"`
+	ApparatusID   string `json:"apparatusID"` // The department apparatus ID; empty if they are not on an apparatus.
+	PositionID    string `json:"positionID"`
+	PositionName  string `json:"positionName,omitempty"` // Not used for creating assignments.
+	StartDateTime string `json:"startDateTime"`
+	EndDateTime   string `json:"endDateTime"`
+	RowVersion    string `json:"rowVersion,omitempty"` // Not used for creating assignments.
+}
+
+type GetRosterAssignmentsResponse struct {
+	Assignments []*RosterAssignment `json:"assignments"`
+}
+
+type PostRosterAssignmentResponse struct {
+	AssignmentID string `json:"assignmentID"`
+}
+
+type PatchRosterAssignmentRequest struct {
+	UserID        *string `json:"userID,omitempty"`
+	ApparatusID   *string `json:"apparatusID,omitempty"`
+	PositionID    *string `json:"positionID,omitempty"`
+	StartDateTime *string `json:"startDateTime,omitempty"`
+	EndDateTime   *string `json:"endDateTime,omitempty"`
+}
+
+type PatchRosterAssignmentResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// GetShifts gets the shifts (or platoons).
+func (c *Client) GetShifts(ctx context.Context, options map[string]string) (*GetShiftsResponse, error) {
+	// https://data.emergencyreporting.com/agencyshifts/shifts[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyshifts/shifts"
+
+	var parsedResponse GetShiftsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the shifts: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetShiftCalendar gets a page of the shift calendar.
+//
+// Filter on "shiftDate" to get a range of days.
+func (c *Client) GetShiftCalendar(ctx context.Context, options map[string]string) (*GetShiftCalendarResponse, error) {
+	// https://data.emergencyreporting.com/agencyshifts/calendar[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyshifts/calendar"
+
+	var parsedResponse GetShiftCalendarResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the shift calendar: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllShiftCalendar gets every page of the shift calendar.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllShiftCalendar(ctx context.Context, options map[string]string) ([]*ShiftCalendarDay, error) {
+	var days []*ShiftCalendarDay
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetShiftCalendar(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		days = append(days, response.Days...)
+		return len(response.Days), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// GetRosterPositions gets the positions that people can fill on a roster.
+func (c *Client) GetRosterPositions(ctx context.Context, options map[string]string) (*GetRosterPositionsResponse, error) {
+	// https://data.emergencyreporting.com/agencyroster/positions[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyroster/positions"
+
+	var parsedResponse GetRosterPositionsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the roster positions: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetRosters gets a page of daily rosters.
+func (c *Client) GetRosters(ctx context.Context, options map[string]string) (*GetRostersResponse, error) {
+	// https://data.emergencyreporting.com/agencyroster/rosters[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyroster/rosters"
+
+	var parsedResponse GetRostersResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the rosters: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllRosters gets every page of daily rosters.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllRosters(ctx context.Context, options map[string]string) ([]*Roster, error) {
+	var rosters []*Roster
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetRosters(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		rosters = append(rosters, response.Rosters...)
+		return len(response.Rosters), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rosters, nil
+}
+
+// GetRoster gets a daily roster.
+func (c *Client) GetRoster(ctx context.Context, rosterID string) (*GetRosterResponse, error) {
+	// https://data.emergencyreporting.com/agencyroster/rosters/{rosterID}
+
+	targetURL := "/agencyroster/rosters/" + url.PathEscape(rosterID)
+
+	var parsedResponse GetRosterResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the roster: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostRoster creates a daily roster.
+func (c *Client) PostRoster(ctx context.Context, roster Roster) (*PostRosterResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyroster/rosters
+
+	targetURL := "/agencyroster/rosters"
+
+	jsonInput, err := json.Marshal(roster)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostRosterResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the roster: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchRoster updates a daily roster.
+func (c *Client) PatchRoster(ctx context.Context, rosterID string, rowVersion string, payload PatchRosterRequest) (*PatchRosterResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyroster/rosters/{rosterID}
+
+	targetURL := "/agencyroster/rosters/" + url.PathEscape(rosterID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchRosterResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the roster: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetRosterAssignments gets the assignments on a daily roster.
+func (c *Client) GetRosterAssignments(ctx context.Context, rosterID string, options map[string]string) (*GetRosterAssignmentsResponse, error) {
+	// https://data.emergencyreporting.com/agencyroster/rosters/{rosterID}/assignments[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencyroster/rosters/" + url.PathEscape(rosterID) + "/assignments"
+
+	var parsedResponse GetRosterAssignmentsResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the roster assignments: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllRosterAssignments gets every page of the assignments on a daily roster.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllRosterAssignments(ctx context.Context, rosterID string, options map[string]string) ([]*RosterAssignment, error) {
+	var assignments []*RosterAssignment
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetRosterAssignments(ctx, rosterID, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		assignments = append(assignments, response.Assignments...)
+		return len(response.Assignments), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// PostRosterAssignment assigns someone to a daily roster.
+func (c *Client) PostRosterAssignment(ctx context.Context, rosterID string, assignment RosterAssignment) (*PostRosterAssignmentResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyroster/rosters/{rosterID}/assignments
+
+	targetURL := "/agencyroster/rosters/" + url.PathEscape(rosterID) + "/assignments"
+
+	jsonInput, err := json.Marshal(assignment)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostRosterAssignmentResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the roster assignment: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchRosterAssignment updates an assignment on a daily roster.
+func (c *Client) PatchRosterAssignment(ctx context.Context, rosterID string, assignmentID string, rowVersion string, payload PatchRosterAssignmentRequest) (*PatchRosterAssignmentResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencyroster/rosters/{rosterID}/assignments/{assignmentID}
+
+	targetURL := "/agencyroster/rosters/" + url.PathEscape(rosterID) + "/assignments/" + url.PathEscape(assignmentID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchRosterAssignmentResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the roster assignment: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// OnDuty returns the assignments to the apparatus (by department apparatus ID) that cover the time, in the order given.
+//
+// An assignment without a start or end uses its roster's, and a roster without them covers its whole day (in the
+// time's location).  The end is exclusive, so someone going off duty at 07:00 is not on duty at 07:00.
+// Each person is only listed once.
+func OnDuty(rosters []*Roster, assignments []*RosterAssignment, apparatusID string, at time.Time) []*RosterAssignment {
+	rostersByID := map[string]*Roster{}
+	for _, roster := range rosters {
+		rostersByID[roster.RosterID] = roster
+	}
+
+	results := []*RosterAssignment{}
+	seen := map[string]bool{}
+	for _, assignment := range assignments {
+		if assignment.ApparatusID != apparatusID || seen[assignment.UserID] {
+			continue
+		}
+		roster, ok := rostersByID[assignment.RosterID]
+		if !ok {
+			continue
+		}
+
+		start, hasStart := parseDateTime(assignment.StartDateTime, at.Location())
+		if !hasStart {
+			start, hasStart = parseDateTime(roster.StartDateTime, at.Location())
+		}
+		end, hasEnd := parseDateTime(assignment.EndDateTime, at.Location())
+		if !hasEnd {
+			end, hasEnd = parseDateTime(roster.EndDateTime, at.Location())
+		}
+		if !hasStart || !hasEnd {
+			day, ok := parseDateTime(roster.RosterDate, at.Location())
+			if !ok {
+				continue
+			}
+			day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, at.Location())
+			if !hasStart {
+				start = day
+			}
+			if !hasEnd {
+				end = day.AddDate(0, 0, 1)
+			}
+		}
+
+		if at.Before(start) || !at.Before(end) {
+			continue
+		}
+		seen[assignment.UserID] = true
+		results = append(results, assignment)
+	}
+	return results
+}
+
+// GetOnDuty gets the rosters around the time (along with their assignments) and returns the people who were on duty
+// on the apparatus (by department apparatus ID).
+//
+// The rosters from the day before are included, since 24-hour shifts run past midnight.
+//
+// See `OnDuty`.
+func (c *Client) GetOnDuty(ctx context.Context, apparatusID string, at time.Time) ([]*RosterAssignment, error) {
+	filter := fmt.Sprintf("rosterDate ge '%s' and rosterDate lt '%s'", at.AddDate(0, 0, -1).Format("2006-01-02"), at.AddDate(0, 0, 1).Format("2006-01-02"))
+	rosters, err := c.GetAllRosters(ctx, map[string]string{"filter": filter})
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []*RosterAssignment
+	for _, roster := range rosters {
+		rosterAssignments, err := c.GetAllRosterAssignments(ctx, roster.RosterID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range rosterAssignments {
+			if assignment.RosterID == "" {
+				assignment.RosterID = roster.RosterID
+			}
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return OnDuty(rosters, assignments, apparatusID, at), nil
+}
+
+// GetRosterCrew returns the crew members that the rosters say were on each of the exposure's apparatuses when
+// it was dispatched (or alarmed, if there is no dispatch time), leaving out anyone who is already a crew member
+// on that apparatus.  Nothing is added to the exposure.
+//
+// Date/times without a time zone are in the given location.
+func (c *Client) GetRosterCrew(ctx context.Context, exposureID string, location *time.Location) ([]*CrewMember, error) {
+	apparatusesResponse, err := c.GetExposureApparatuses(ctx, exposureID)
+	if err != nil {
+		return nil, err
+	}
+	membersResponse, err := c.GetExposureMembers(ctx, exposureID, nil)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, member := range membersResponse.CrewMembers {
+		existing[member.ApparatusID+"/"+member.UserID] = true
+	}
+
+	crewMembers := []*CrewMember{}
+	for _, apparatus := range apparatusesResponse.Apparatuses {
+		at, ok := parseDateTime(apparatus.DispatchDateTime, location)
+		if !ok {
+			at, ok = parseDateTime(apparatus.AlarmDateTime, location)
+		}
+		if !ok {
+			c.log().Warn("Apparatus has no dispatch or alarm time", "exposureID", exposureID, "apparatusID", apparatus.ApparatusID)
+			continue
+		}
+
+		assignments, err := c.GetOnDuty(ctx, apparatus.DepartmentApparatusID, at)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range assignments {
+			if existing[apparatus.ApparatusID+"/"+assignment.UserID] {
+				continue
+			}
+			crewMembers = append(crewMembers, &CrewMember{
+				UserID:      assignment.UserID,
+				ApparatusID: apparatus.ApparatusID,
+				ExposureID:  exposureID,
+			})
+		}
+	}
+	return crewMembers, nil
+}
@@ -0,0 +1,95 @@
+package emergencyreporting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnDuty(t *testing.T) {
+	rosters := []*Roster{
+		{RosterID: "1", RosterDate: "2026-03-01", StartDateTime: "2026-03-01 07:00:00", EndDateTime: "2026-03-02 07:00:00"},
+		{RosterID: "2", RosterDate: "2026-03-02"},
+	}
+	assignments := []*RosterAssignment{
+		{AssignmentID: "10", RosterID: "1", UserID: "100", ApparatusID: "5"},
+		{AssignmentID: "11", RosterID: "1", UserID: "101", ApparatusID: "5", StartDateTime: "2026-03-01 19:00:00"},
+		{AssignmentID: "12", RosterID: "1", UserID: "102", ApparatusID: "6"},
+		{AssignmentID: "13", RosterID: "2", UserID: "100", ApparatusID: "5"},
+		{AssignmentID: "14", RosterID: "2", UserID: "103", ApparatusID: "5", EndDateTime: "2026-03-02 12:00:00"},
+		{AssignmentID: "15", RosterID: "999", UserID: "104", ApparatusID: "5"},
+	}
+
+	rows := []struct {
+		description string
+		apparatusID string
+		at          time.Time
+		expected    []string
+	}{
+		{
+			description: "Before the shift",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 1, 6, 59, 0, 0, time.UTC),
+			expected:    []string{},
+		},
+		{
+			description: "Roster times",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+			expected:    []string{"10"},
+		},
+		{
+			description: "Assignment times",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC),
+			expected:    []string{"10", "11"},
+		},
+		{
+			description: "Other apparatus",
+			apparatusID: "6",
+			at:          time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC),
+			expected:    []string{"12"},
+		},
+		{
+			description: "Each user once",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC),
+			expected:    []string{"10", "11", "14"},
+		},
+		{
+			description: "The end is exclusive",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC),
+			expected:    []string{"13", "14"},
+		},
+		{
+			description: "The whole roster day",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 2, 23, 59, 0, 0, time.UTC),
+			expected:    []string{"13"},
+		},
+		{
+			description: "After the roster day",
+			apparatusID: "5",
+			at:          time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+			expected:    []string{},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			results := OnDuty(rosters, assignments, row.apparatusID, row.at)
+			actual := []string{}
+			for _, result := range results {
+				actual = append(actual, result.AssignmentID)
+			}
+			if len(actual) != len(row.expected) {
+				t.Fatalf("Expected %v; got %v", row.expected, actual)
+			}
+			for index := range actual {
+				if actual[index] != row.expected[index] {
+					t.Errorf("Expected %v; got %v", row.expected, actual)
+					break
+				}
+			}
+		})
+	}
+}
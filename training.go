@@ -0,0 +1,489 @@
+package emergencyreporting
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TrainingCategory struct {
+	CategoryID   string `json:"categoryID"`
+	CategoryName string `json:"categoryName"`
+	Description  string `json:"description"`
+	IsActive     string `json:"isActive"`
+}
+
+type GetTrainingCategoriesResponse struct {
+	Categories []*TrainingCategory `json:"categories"`
+}
+
+type TrainingClass struct {
+	ClassID          string `json:"classID,omitempty"` // Not used for creating classes.
+	ClassName        string `json:"className"`
+	CategoryID       string `json:"categoryID"`
+	CategoryName     string `json:"categoryName,omitempty"` // Not used for creating classes.
+	StartDateTime    string `json:"startDateTime"`
+	EndDateTime      string `json:"endDateTime"`
+	Hours            string `json:"hours"` // The hours that each attendee gets by default.
+	InstructorUserID string `json:"instructorUserID"`
+	StationID        string `json:"stationID"`
+	Location         string `json:"location"`
+	Description      string `json:"description"`
+	IsComplete       string `json:"isComplete"`
+	RowVersion       string `json:"rowVersion,omitempty"` // Not used for creating classes.
+}
+
+type GetTrainingClassesResponse struct {
+	TotalRows string           `json:"totalRows"`
+	Classes   []*TrainingClass `json:"classes"`
+}
+
+type GetTrainingClassResponse struct {
+	Class *TrainingClass `json:"class"`
+}
+
+type PostTrainingClassResponse struct {
+	ClassID string `json:"classID"`
+}
+
+type PatchTrainingClassRequest struct {
+	ClassName        *string `json:"className,omitempty"`
+	CategoryID       *string `json:"categoryID,omitempty"`
+	StartDateTime    *string `json:"startDateTime,omitempty"`
+	EndDateTime      *string `json:"endDateTime,omitempty"`
+	Hours            *string `json:"hours,omitempty"`
+	InstructorUserID *string `json:"instructorUserID,omitempty"`
+	StationID        *string `json:"stationID,omitempty"`
+	Location         *string `json:"location,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	IsComplete       *string `json:"isComplete,omitempty"`
+}
+
+type PatchTrainingClassResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+type TrainingAttendee struct {
+	AttendeeID string `json:"attendeeID,omitempty"` // Not used for adding attendees.
+	ClassID    string `json:"classID,omitempty"`
+	UserID     string `json:"userID"`
+	Hours      string `json:"hours"`  // If empty, then the class hours are used.
+	Status     string `json:"status"` // Such as "Attended" or "Excused".
+	Notes      string `json:"notes"`
+	RowVersion string `json:"rowVersion,omitempty"`
+}
+
+type GetTrainingClassAttendeesResponse struct {
+	Attendees []*TrainingAttendee `json:"attendees"`
+}
+
+type PostTrainingClassAttendeeResponse struct {
+	AttendeeID string `json:"attendeeID"`
+}
+
+// TrainingHours are the hours that a member got for a class.
+type TrainingHours struct {
+	UserID           string `json:"userID"`
+	ClassID          string `json:"classID"`
+	CategoryID       string `json:"categoryID"`
+	CategoryName     string `json:"categoryName"`
+	TrainingDateTime string `json:"trainingDateTime"`
+	Hours            string `json:"hours"`
+}
+
+type GetTrainingHoursResponse struct {
+	TotalRows string           `json:"totalRows"`
+	Hours     []*TrainingHours `json:"hours"`
+}
+
+// GetTrainingCategories gets the training categories.
+func (c *Client) GetTrainingCategories(ctx context.Context, options map[string]string) (*GetTrainingCategoriesResponse, error) {
+	// https://data.emergencyreporting.com/agencytraining/categories[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencytraining/categories"
+
+	var parsedResponse GetTrainingCategoriesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the training categories: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetTrainingClasses gets a page of training classes.
+func (c *Client) GetTrainingClasses(ctx context.Context, options map[string]string) (*GetTrainingClassesResponse, error) {
+	// https://data.emergencyreporting.com/agencytraining/classes[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencytraining/classes"
+
+	var parsedResponse GetTrainingClassesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the training classes: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllTrainingClasses gets every page of training classes.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllTrainingClasses(ctx context.Context, options map[string]string) ([]*TrainingClass, error) {
+	var classes []*TrainingClass
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetTrainingClasses(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		classes = append(classes, response.Classes...)
+		return len(response.Classes), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// GetTrainingClass gets a training class.
+func (c *Client) GetTrainingClass(ctx context.Context, classID string) (*GetTrainingClassResponse, error) {
+	// https://data.emergencyreporting.com/agencytraining/classes/{classID}
+
+	targetURL := "/agencytraining/classes/" + url.PathEscape(classID)
+
+	var parsedResponse GetTrainingClassResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, nil, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the training class: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostTrainingClass creates a training class.
+func (c *Client) PostTrainingClass(ctx context.Context, class TrainingClass) (*PostTrainingClassResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencytraining/classes
+
+	targetURL := "/agencytraining/classes"
+
+	jsonInput, err := json.Marshal(class)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostTrainingClassResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the training class: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PatchTrainingClass updates a training class.
+func (c *Client) PatchTrainingClass(ctx context.Context, classID string, rowVersion string, payload PatchTrainingClassRequest) (*PatchTrainingClassResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencytraining/classes/{classID}
+
+	targetURL := "/agencytraining/classes/" + url.PathEscape(classID)
+
+	jsonInput, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"ETag":         rowVersion,
+	}
+
+	var parsedResponse PatchTrainingClassResponse
+
+	err = c.internalRequest(ctx, http.MethodPatch, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not patch the training class: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetTrainingClassAttendees gets the attendees of a training class.
+func (c *Client) GetTrainingClassAttendees(ctx context.Context, classID string, options map[string]string) (*GetTrainingClassAttendeesResponse, error) {
+	// https://data.emergencyreporting.com/agencytraining/classes/{classID}/attendees[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencytraining/classes/" + url.PathEscape(classID) + "/attendees"
+
+	var parsedResponse GetTrainingClassAttendeesResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the training class attendees: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// PostTrainingClassAttendee adds an attendee to a training class.
+func (c *Client) PostTrainingClassAttendee(ctx context.Context, classID string, attendee TrainingAttendee) (*PostTrainingClassAttendeeResponse, error) {
+	c.init()
+
+	// https://data.emergencyreporting.com/agencytraining/classes/{classID}/attendees
+
+	targetURL := "/agencytraining/classes/" + url.PathEscape(classID) + "/attendees"
+
+	jsonInput, err := json.Marshal(attendee)
+	if err != nil {
+		return nil, fmt.Errorf("could not create JSON: %w", err)
+	}
+	c.log().Debug("Request body", "body", c.redact(jsonInput))
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var parsedResponse PostTrainingClassAttendeeResponse
+
+	err = c.internalRequest(ctx, http.MethodPost, targetURL, nil, headers, jsonInput, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the training class attendee: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetTrainingHours gets a page of training hours.
+func (c *Client) GetTrainingHours(ctx context.Context, options map[string]string) (*GetTrainingHoursResponse, error) {
+	// https://data.emergencyreporting.com/agencytraining/hours[?rowVersion][&limit][&offset][&filter][&orderby]
+
+	targetURL := "/agencytraining/hours"
+
+	var parsedResponse GetTrainingHoursResponse
+
+	err := c.internalRequest(ctx, http.MethodGet, targetURL, options, nil, nil, &parsedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the training hours: %w", err)
+	}
+
+	return &parsedResponse, nil
+}
+
+// GetAllTrainingHours gets every page of training hours.
+//
+// The "limit" option, if given, is the page size.
+func (c *Client) GetAllTrainingHours(ctx context.Context, options map[string]string) ([]*TrainingHours, error) {
+	var hours []*TrainingHours
+	err := paginate(options, func(pageOptions map[string]string) (int, error) {
+		response, err := c.GetTrainingHours(ctx, pageOptions)
+		if err != nil {
+			return 0, err
+		}
+		hours = append(hours, response.Hours...)
+		return len(response.Hours), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hours, nil
+}
+
+// TrainingAttendanceRecord is a row from a bulk attendance file.
+type TrainingAttendanceRecord struct {
+	Line              int    `json:"line"` // The line in the file, starting at 1 (for the header).
+	AgencyPersonnelID string `json:"agencyPersonnelID"`
+	Hours             string `json:"hours,omitempty"`  // If empty, then the class hours are used.
+	Status            string `json:"status,omitempty"` // If empty, then "Attended" is used.
+}
+
+// ReadTrainingAttendanceCSV reads a bulk attendance file.
+//
+// The first row is the header, which must have an "agencyPersonnelID" column; "hours" and "status"
+// columns are optional, and any others are ignored.  Rows without a personnel ID are skipped.
+func ReadTrainingAttendanceCSV(reader io.Reader) ([]*TrainingAttendanceRecord, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the header: %w", err)
+	}
+	columns := map[string]int{}
+	for index, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = index
+	}
+	personnelIDColumn, ok := columns["agencypersonnelid"]
+	if !ok {
+		return nil, fmt.Errorf("missing column: agencyPersonnelID")
+	}
+	column := func(row []string, name string) string {
+		index, ok := columns[name]
+		if !ok || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	var records []*TrainingAttendanceRecord
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read line %d: %w", line, err)
+		}
+		if personnelIDColumn >= len(row) || strings.TrimSpace(row[personnelIDColumn]) == "" {
+			continue
+		}
+		record := &TrainingAttendanceRecord{
+			Line:              line,
+			AgencyPersonnelID: strings.TrimSpace(row[personnelIDColumn]),
+			Hours:             column(row, "hours"),
+			Status:            column(row, "status"),
+		}
+		if record.Hours != "" {
+			if _, err := strconv.ParseFloat(record.Hours, 64); err != nil {
+				return nil, fmt.Errorf("invalid hours on line %d: %s", line, record.Hours)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// UserIDsByPersonnelID maps the users' agency personnel IDs to their user IDs.
+//
+// Users without a personnel ID are skipped.
+func UserIDsByPersonnelID(users []*User) map[string]string {
+	result := map[string]string{}
+	for _, user := range users {
+		if user == nil || user.AgencyPersonnelID == nil || strings.TrimSpace(*user.AgencyPersonnelID) == "" {
+			continue
+		}
+		result[strings.TrimSpace(*user.AgencyPersonnelID)] = user.UserID
+	}
+	return result
+}
+
+// TrainingAttendee returns the attendee for a bulk attendance record, given the user ID that its
+// personnel ID resolves to.
+func (r *TrainingAttendanceRecord) TrainingAttendee(userID string) TrainingAttendee {
+	attendee := TrainingAttendee{
+		UserID: userID,
+		Hours:  r.Hours,
+		Status: r.Status,
+	}
+	if attendee.Status == "" {
+		attendee.Status = "Attended"
+	}
+	return attendee
+}
+
+// TrainingHoursReportOptions are the options for `TrainingHoursReport`.
+type TrainingHoursReportOptions struct {
+	From time.Time // If set, the hours before this are skipped.
+	To   time.Time // If set, the hours at or after this are skipped.
+}
+
+// MemberTrainingHours are the total hours that a member has in a training category.
+type MemberTrainingHours struct {
+	UserID            string  `json:"userID"`
+	FullName          string  `json:"fullName"`
+	AgencyPersonnelID string  `json:"agencyPersonnelID"`
+	CategoryID        string  `json:"categoryID"`
+	CategoryName      string  `json:"categoryName"`
+	Classes           int     `json:"classes"`
+	Hours             float64 `json:"hours"`
+}
+
+// TrainingHoursReport totals the training hours for each member and category, sorted by the member's
+// name and then the category name.
+//
+// The users (which may be nil) are only used for the names and personnel IDs.  Date/times without a time
+// zone are in the location of `From` (or `To`); hours that can't be parsed are skipped, as are hours
+// without a date/time when there is a date range.
+func TrainingHoursReport(hours []*TrainingHours, users []*User, options TrainingHoursReportOptions) []*MemberTrainingHours {
+	usersByID := map[string]*User{}
+	for _, user := range users {
+		if user != nil {
+			usersByID[user.UserID] = user
+		}
+	}
+	location := options.From.Location()
+	if options.From.IsZero() {
+		location = options.To.Location()
+	}
+
+	type key struct {
+		userID     string
+		categoryID string
+	}
+	totals := map[key]*MemberTrainingHours{}
+	results := []*MemberTrainingHours{}
+	for _, entry := range hours {
+		if entry == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(entry.Hours), 64)
+		if err != nil {
+			continue
+		}
+		if !options.From.IsZero() || !options.To.IsZero() {
+			when, ok := parseDateTime(entry.TrainingDateTime, location)
+			if !ok || (!options.From.IsZero() && when.Before(options.From)) || (!options.To.IsZero() && !when.Before(options.To)) {
+				continue
+			}
+		}
+
+		total, ok := totals[key{entry.UserID, entry.CategoryID}]
+		if !ok {
+			total = &MemberTrainingHours{
+				UserID:       entry.UserID,
+				CategoryID:   entry.CategoryID,
+				CategoryName: entry.CategoryName,
+			}
+			if user, ok := usersByID[entry.UserID]; ok {
+				total.FullName = user.FullName
+				if user.AgencyPersonnelID != nil {
+					total.AgencyPersonnelID = *user.AgencyPersonnelID
+				}
+			}
+			totals[key{entry.UserID, entry.CategoryID}] = total
+			results = append(results, total)
+		}
+		total.Classes++
+		total.Hours += value
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].FullName != results[j].FullName {
+			return results[i].FullName < results[j].FullName
+		}
+		if results[i].UserID != results[j].UserID {
+			return results[i].UserID < results[j].UserID
+		}
+		return results[i].CategoryName < results[j].CategoryName
+	})
+	return results
+}
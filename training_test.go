@@ -0,0 +1,152 @@
+package emergencyreporting
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTrainingAttendanceCSV(t *testing.T) {
+	rows := []struct {
+		description string
+		input       string
+		expected    []*TrainingAttendanceRecord
+		err         string // If set, the error must contain this.
+	}{
+		{
+			description: "Header with a byte order mark",
+			input:       "\ufeffAgencyPersonnelID,Hours,Status\n101,2.5,Excused\n102,,\n",
+			expected: []*TrainingAttendanceRecord{
+				{Line: 2, AgencyPersonnelID: "101", Hours: "2.5", Status: "Excused"},
+				{Line: 3, AgencyPersonnelID: "102"},
+			},
+		},
+		{
+			description: "Other columns and spaces",
+			input:       "name, agencyPersonnelID\nJane Doe, 101 \n",
+			expected: []*TrainingAttendanceRecord{
+				{Line: 2, AgencyPersonnelID: "101"},
+			},
+		},
+		{
+			description: "Rows without a personnel ID are skipped",
+			input:       "agencyPersonnelID,hours\n,1\n101,2\n\"  \",3\n103\n",
+			expected: []*TrainingAttendanceRecord{
+				{Line: 3, AgencyPersonnelID: "101", Hours: "2"},
+				{Line: 5, AgencyPersonnelID: "103"},
+			},
+		},
+		{
+			description: "Missing the personnel ID column",
+			input:       "name,hours\nJane Doe,1\n",
+			err:         "missing column: agencyPersonnelID",
+		},
+		{
+			description: "Invalid hours",
+			input:       "agencyPersonnelID,hours\n101,1\n102,two\n",
+			err:         "invalid hours on line 3: two",
+		},
+		{
+			description: "Empty",
+			input:       "",
+			err:         "could not read the header",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			records, err := ReadTrainingAttendanceCSV(strings.NewReader(row.input))
+			if row.err != "" {
+				if err == nil || !strings.Contains(err.Error(), row.err) {
+					t.Errorf("Expected an error with %q; got %v", row.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(records, row.expected) {
+				t.Errorf("Expected %+v; got %+v", row.expected, records)
+			}
+		})
+	}
+}
+
+func TestTrainingHoursReport(t *testing.T) {
+	personnelID := "101"
+	users := []*User{
+		{UserID: "1", FullName: "Zed Smith"},
+		{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: &personnelID},
+	}
+	hours := []*TrainingHours{
+		{UserID: "1", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "2026-01-01 00:00:00", Hours: "2"},
+		{UserID: "1", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "2026-01-15 18:00:00", Hours: "1.5"},
+		{UserID: "2", CategoryID: "20", CategoryName: "EMS", TrainingDateTime: "2026-01-31 23:59:59", Hours: "3"},
+		{UserID: "2", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "2026-02-01 00:00:00", Hours: "4"},
+		{UserID: "2", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "2025-12-31 23:59:59", Hours: "5"},
+		{UserID: "2", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "", Hours: "6"},
+		{UserID: "1", CategoryID: "10", CategoryName: "Fire", TrainingDateTime: "2026-01-10 00:00:00", Hours: "lots"},
+	}
+
+	rows := []struct {
+		description string
+		options     TrainingHoursReportOptions
+		expected    []*MemberTrainingHours
+	}{
+		{
+			description: "Everything",
+			expected: []*MemberTrainingHours{
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "20", CategoryName: "EMS", Classes: 1, Hours: 3},
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "10", CategoryName: "Fire", Classes: 3, Hours: 15},
+				{UserID: "1", FullName: "Zed Smith", CategoryID: "10", CategoryName: "Fire", Classes: 2, Hours: 3.5},
+			},
+		},
+		{
+			description: "From is inclusive and To is exclusive",
+			options: TrainingHoursReportOptions{
+				From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expected: []*MemberTrainingHours{
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "20", CategoryName: "EMS", Classes: 1, Hours: 3},
+				{UserID: "1", FullName: "Zed Smith", CategoryID: "10", CategoryName: "Fire", Classes: 2, Hours: 3.5},
+			},
+		},
+		{
+			description: "From only",
+			options: TrainingHoursReportOptions{
+				From: time.Date(2026, 1, 15, 18, 0, 0, 0, time.UTC),
+			},
+			expected: []*MemberTrainingHours{
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "20", CategoryName: "EMS", Classes: 1, Hours: 3},
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "10", CategoryName: "Fire", Classes: 1, Hours: 4},
+				{UserID: "1", FullName: "Zed Smith", CategoryID: "10", CategoryName: "Fire", Classes: 1, Hours: 1.5},
+			},
+		},
+		{
+			description: "The time zone comes from the range",
+			options: TrainingHoursReportOptions{
+				To: time.Date(2026, 1, 31, 23, 0, 0, 0, time.FixedZone("UTC-1", -60*60)),
+			},
+			expected: []*MemberTrainingHours{
+				{UserID: "2", FullName: "Ann Jones", AgencyPersonnelID: "101", CategoryID: "10", CategoryName: "Fire", Classes: 1, Hours: 5},
+				{UserID: "1", FullName: "Zed Smith", CategoryID: "10", CategoryName: "Fire", Classes: 2, Hours: 3.5},
+			},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := TrainingHoursReport(hours, users, row.options)
+			if !reflect.DeepEqual(actual, row.expected) {
+				t.Errorf("Expected:")
+				for _, total := range row.expected {
+					t.Errorf("  %+v", total)
+				}
+				t.Errorf("Got:")
+				for _, total := range actual {
+					t.Errorf("  %+v", total)
+				}
+			}
+		})
+	}
+}
@@ -93,6 +93,45 @@ type PostIncidentResponse struct {
 	IncidentID string `json:"incidentID"`
 }
 
+type PatchIncidentRequest struct {
+	StationID             *string `json:"stationID,omitempty"`
+	State                 *string `json:"state,omitempty"`
+	IncidentDateTime      *string `json:"incidentDateTime,omitempty"`
+	FDID                  *string `json:"fdid,omitempty"`
+	IncidentNumber        *string `json:"incidentNumber,omitempty"`
+	PartnerIncidentNumber *string `json:"partnerIncidentNumber,omitempty"`
+	DispatchRunNumber     *string `json:"dispatchRunNumber,omitempty"`
+	IsComplete            *string `json:"isComplete,omitempty"`
+	IsReviewed            *string `json:"isReviewed,omitempty"`
+	NarrativesRequired    *string `json:"narrativesRequired,omitempty"`
+}
+
+type PatchIncidentResponse struct {
+	RowVersion string `json:"rowVersion"`
+}
+
+// Incident keys; these are the fields that `UpsertIncident` can use to find an existing incident.
+const (
+	IncidentKeyIncidentNumber        = "incidentNumber"
+	IncidentKeyPartnerIncidentNumber = "partnerIncidentNumber"
+	IncidentKeyDispatchRunNumber     = "dispatchRunNumber"
+)
+
+// Upsert actions.
+const (
+	UpsertActionCreated   = "created"
+	UpsertActionUpdated   = "updated"
+	UpsertActionUnchanged = "unchanged"
+)
+
+// UpsertIncidentResponse describes what `UpsertIncident` did.
+type UpsertIncidentResponse struct {
+	Action     string                `json:"action"` // One of the `UpsertAction*` constants.
+	IncidentID string                `json:"incidentID"`
+	RowVersion string                `json:"rowVersion,omitempty"` // Not returned when creating incidents.
+	Patch      *PatchIncidentRequest `json:"patch,omitempty"`      // The fields that were changed, if any.
+}
+
 type Exposure struct {
 	ShiftsOrPlatoon                string `json:"shiftsOrPlatoon"`
 	IncidentType                   string `json:"incidentType"`
@@ -125,6 +164,7 @@ type Exposure struct {
 	Location    *ExposureLocation    `json:"-"`
 	Apparatuses []*ExposureApparatus `json:"-"`
 	Narratives  []*ExposureNarrative `json:"-"`
+	CrewMembers []*CrewMember        `json:"-"`
 }
 
 type GetExposuresResponse struct {
@@ -304,6 +344,10 @@ type GetExposureMembersResponse struct {
 	CrewMembers []*CrewMember `json:"crewMembers"`
 }
 
+type PostExposureMemberResponse struct {
+	ExposureUserID string `json:"exposureUserID"`
+}
+
 type CrewMemberRole struct {
 	ExposureUserRoleID string `json:"exposureUserRoleID"`
 	ExposureID         string `json:"exposureID"`
@@ -318,26 +362,27 @@ type GetExposureMemberRolesResponse struct {
 // Note: "rowNum" (string) is the 1-index of the entry; might be tacked on to all array responses?
 
 type User struct {
-	RowNum                   string        `json:"rowNum,omitempty"`
-	AgencyPersonnelID        *string       `json:"agencyPersonnelID"`
-	Email                    *string       `json:"email"`
-	RoleName                 string        `json:"roleName"`
-	UserID                   string        `json:"userID"`
-	Title                    *string       `json:"title"`
-	FullName                 string        `json:"fullName"`
-	Login                    string        `json:"login"`
-	Archive                  string        `json:"Archive"`
-	PrimaryEmail             string        `json:"primaryEmail"`
-	CertificationStatus      string        `json:"certificationStatus"`
-	RoleID                   string        `json:"roleID"`
-	DefaultEventPaygradeName *string       `json:"defaultEventPaygradeName"`
-	DefaultEventPaygradeRate *string       `json:"defaultEventPaygradeRate"`
-	Station                  *string       `json:"station"`
-	Shift                    *string       `json:"shift"`
-	RowVersion               string        `json:"rowVersion"`
-	Licenses                 []interface{} `json:"licenses"` // TODO: Figure out what this is.
-
-	ContactInfo *UserContactInfo `json:"-"`
+	RowNum                   string         `json:"rowNum,omitempty"`
+	AgencyPersonnelID        *string        `json:"agencyPersonnelID"`
+	Email                    *string        `json:"email"`
+	RoleName                 string         `json:"roleName"`
+	UserID                   string         `json:"userID"`
+	Title                    *string        `json:"title"`
+	FullName                 string         `json:"fullName"`
+	Login                    string         `json:"login"`
+	Archive                  string         `json:"Archive"`
+	PrimaryEmail             string         `json:"primaryEmail"`
+	CertificationStatus      string         `json:"certificationStatus"` // The overall status, one of the `CertificationStatus*` constants; see `Licenses` and `Certifications` for the individual ones.
+	RoleID                   string         `json:"roleID"`
+	DefaultEventPaygradeName *string        `json:"defaultEventPaygradeName"`
+	DefaultEventPaygradeRate *string        `json:"defaultEventPaygradeRate"`
+	Station                  *string        `json:"station"`
+	Shift                    *string        `json:"shift"`
+	RowVersion               string         `json:"rowVersion"`
+	Licenses                 []*UserLicense `json:"licenses"`
+
+	ContactInfo    *UserContactInfo     `json:"-"`
+	Certifications []*UserCertification `json:"-"` // Not part of the user; see `GetUserCertifications`.
 }
 
 // CurrentUser represents the "current" user (special user ID of "me").
@@ -381,19 +426,16 @@ type GetUserResponse struct {
 	User *User `json:"user"`
 }
 
-// TODO: MAKE THIS THE CORRECT TYPE
-type UserContactInfo map[string]interface{}
-
-type GetUserContactInfoResponse struct {
-	ContactInfo UserContactInfo `json:"contactInfo"`
-}
-
+// PatchOperation is a single JSON Patch (RFC 6902) operation.
+//
+// The value is left out for "remove" operations.
 type PatchOperation struct {
-	Operation string `json:"op"`
-	Path      string `json:"path"`
-	Value     string `json:"value"`
+	Operation string      `json:"op"`   // One of the `PatchOperation*` constants.
+	Path      string      `json:"path"` // A JSON Pointer, such as "/title"; see `PatchPath`.
+	Value     interface{} `json:"value"`
 }
 
+// PatchUserRequest is a JSON Patch document; the operations are applied in order, and if any of them fail, none of them are.
 type PatchUserRequest []PatchOperation
 
 type PatchUserResponse struct {
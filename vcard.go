@@ -0,0 +1,171 @@
+package emergencyreporting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vCardLineLength is the longest that a vCard line may be (in bytes) before it must be folded.
+const vCardLineLength = 75
+
+// UserVCard returns a vCard (version 3.0, RFC 2426) for the user, suitable for importing into a phone's contacts.
+//
+// The contact info is optional; without it, the vCard only has the user's name, title, and email.
+// Emergency contacts go in the note, since vCard 3.0 has no place for them.
+func UserVCard(user *User, contactInfo *UserContactInfo) string {
+	var lines []string
+	add := func(name string, value string) {
+		lines = append(lines, foldVCardLine(name+":"+value))
+	}
+
+	add("BEGIN", "VCARD")
+	add("VERSION", "3.0")
+
+	fullName := user.FullName
+	var firstName, middleName, lastName string
+	if contactInfo != nil {
+		firstName = contactInfo.FirstName
+		middleName = contactInfo.MiddleName
+		lastName = contactInfo.LastName
+	}
+	if fullName == "" {
+		fullName = strings.Join(strings.Fields(strings.Join([]string{firstName, middleName, lastName}, " ")), " ")
+	}
+	add("FN", escapeVCardValue(fullName))
+	add("N", strings.Join([]string{escapeVCardValue(lastName), escapeVCardValue(firstName), escapeVCardValue(middleName), "", ""}, ";"))
+	if user.Title != nil && *user.Title != "" {
+		add("TITLE", escapeVCardValue(*user.Title))
+	}
+
+	if contactInfo == nil {
+		if user.PrimaryEmail != "" {
+			add("EMAIL;TYPE=INTERNET", escapeVCardValue(user.PrimaryEmail))
+		}
+	} else {
+		for _, phone := range contactInfo.Phones {
+			if phone.PhoneNumber == "" {
+				continue
+			}
+			types := []string{"VOICE"}
+			switch phone.PhoneType {
+			case PhoneTypeMobile:
+				types = []string{"CELL", "VOICE"}
+			case PhoneTypeHome:
+				types = append(types, "HOME")
+			case PhoneTypeWork:
+				types = append(types, "WORK")
+			case PhoneTypePager:
+				types = []string{"PAGER"}
+			case PhoneTypeFax:
+				types = []string{"FAX"}
+			}
+			if phone.IsPrimary {
+				types = append(types, "PREF")
+			}
+			number := phone.PhoneNumber
+			if phone.Extension != "" {
+				number += " x" + phone.Extension
+			}
+			add("TEL;TYPE="+strings.Join(types, ","), escapeVCardValue(number))
+		}
+		for _, email := range contactInfo.Emails {
+			if email.EmailAddress == "" {
+				continue
+			}
+			types := []string{"INTERNET"}
+			switch email.EmailType {
+			case EmailTypePersonal:
+				types = append(types, "HOME")
+			case EmailTypeWork:
+				types = append(types, "WORK")
+			}
+			if email.IsPrimary {
+				types = append(types, "PREF")
+			}
+			add("EMAIL;TYPE="+strings.Join(types, ","), escapeVCardValue(email.EmailAddress))
+		}
+		for _, address := range contactInfo.Addresses {
+			var types []string
+			switch address.AddressType {
+			case AddressTypeHome:
+				types = append(types, "HOME")
+			case AddressTypeMailing:
+				types = append(types, "POSTAL")
+			case AddressTypeWork:
+				types = append(types, "WORK")
+			}
+			if address.IsPrimary {
+				types = append(types, "PREF")
+			}
+			name := "ADR"
+			if len(types) > 0 {
+				name += ";TYPE=" + strings.Join(types, ",")
+			}
+			// The parts are: post office box, extended address, street address, city, region, postal code, and country.
+			parts := []string{
+				"",
+				escapeVCardValue(address.StreetAddress2),
+				escapeVCardValue(address.StreetAddress),
+				escapeVCardValue(address.City),
+				escapeVCardValue(address.State),
+				escapeVCardValue(address.ZipCode),
+				escapeVCardValue(address.Country),
+			}
+			add(name, strings.Join(parts, ";"))
+		}
+
+		var notes []string
+		for _, emergencyContact := range contactInfo.EmergencyContacts {
+			note := "Emergency contact: " + emergencyContact.Name
+			if emergencyContact.Relationship != "" {
+				note += " (" + emergencyContact.Relationship + ")"
+			}
+			for _, value := range []string{emergencyContact.PhoneNumber, emergencyContact.EmailAddress} {
+				if value != "" {
+					note += ", " + value
+				}
+			}
+			notes = append(notes, note)
+		}
+		if len(notes) > 0 {
+			add("NOTE", escapeVCardValue(strings.Join(notes, "\n")))
+		}
+	}
+
+	if user.AgencyPersonnelID != nil && *user.AgencyPersonnelID != "" {
+		add("X-AGENCY-PERSONNEL-ID", escapeVCardValue(*user.AgencyPersonnelID))
+	}
+	add("UID", escapeVCardValue(fmt.Sprintf("emergencyreporting-user-%s", user.UserID)))
+	add("END", "VCARD")
+
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// escapeVCardValue escapes the backslashes, commas, semicolons, and newlines in a vCard value.
+func escapeVCardValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, ",", `\,`)
+	value = strings.ReplaceAll(value, ";", `\;`)
+	value = strings.ReplaceAll(value, "\r\n", `\n`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return value
+}
+
+// foldVCardLine breaks a long line into continuation lines (each starting with a space).
+//
+// The line is never broken in the middle of a UTF-8 character.
+func foldVCardLine(line string) string {
+	var builder strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > vCardLineLength {
+			builder.WriteString("\r\n ")
+			// The leading space counts toward the length.
+			length = 1
+		}
+		builder.WriteRune(r)
+		length += size
+	}
+	return builder.String()
+}
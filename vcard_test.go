@@ -0,0 +1,136 @@
+package emergencyreporting
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserVCard(t *testing.T) {
+	stringPointer := func(value string) *string {
+		return &value
+	}
+
+	rows := []struct {
+		description string
+		user        *User
+		contactInfo *UserContactInfo
+		expected    []string
+	}{
+		{
+			description: "Without contact info",
+			user:        &User{UserID: "1", FullName: "Jane Doe", Title: stringPointer("Captain"), PrimaryEmail: "jane@example.com"},
+			expected: []string{
+				"BEGIN:VCARD",
+				"VERSION:3.0",
+				"FN:Jane Doe",
+				"N:;;;;",
+				"TITLE:Captain",
+				"EMAIL;TYPE=INTERNET:jane@example.com",
+				"UID:emergencyreporting-user-1",
+				"END:VCARD",
+			},
+		},
+		{
+			description: "With contact info",
+			user:        &User{UserID: "1", AgencyPersonnelID: stringPointer("1001"), PrimaryEmail: "ignored@example.com"},
+			contactInfo: &UserContactInfo{
+				FirstName:  "Jane",
+				MiddleName: "Q",
+				LastName:   "Doe",
+				Phones: []*UserPhone{
+					{PhoneType: PhoneTypeMobile, PhoneNumber: "555-0100", IsPrimary: true},
+					{PhoneType: PhoneTypeWork, PhoneNumber: "555-0101", Extension: "12"},
+					{PhoneType: PhoneTypeFax},
+				},
+				Emails: []*UserEmail{
+					{EmailType: EmailTypePersonal, EmailAddress: "jane@example.com", IsPrimary: true},
+					{EmailType: EmailTypeWork, EmailAddress: "jdoe@example.gov"},
+				},
+				Addresses: []*UserAddress{
+					{AddressType: AddressTypeHome, StreetAddress: "1 Main St", StreetAddress2: "Apt 2", City: "Springfield", State: "IL", ZipCode: "62701", Country: "US", IsPrimary: true},
+					{StreetAddress: "PO Box 5"},
+				},
+				EmergencyContacts: []*UserEmergencyContact{
+					{Name: "John Doe", Relationship: "Spouse", PhoneNumber: "555-0199"},
+					{Name: "Ann Doe", EmailAddress: "ann@example.com"},
+				},
+			},
+			expected: []string{
+				"BEGIN:VCARD",
+				"VERSION:3.0",
+				"FN:Jane Q Doe",
+				"N:Doe;Jane;Q;;",
+				"TEL;TYPE=CELL,VOICE,PREF:555-0100",
+				"TEL;TYPE=VOICE,WORK:555-0101 x12",
+				"EMAIL;TYPE=INTERNET,HOME,PREF:jane@example.com",
+				"EMAIL;TYPE=INTERNET,WORK:jdoe@example.gov",
+				"ADR;TYPE=HOME,PREF:;Apt 2;1 Main St;Springfield;IL;62701;US",
+				"ADR:;;PO Box 5;;;;",
+				`NOTE:Emergency contact: John Doe (Spouse)\, 555-0199\nEmergency contact: An`,
+				` n Doe\, ann@example.com`,
+				"X-AGENCY-PERSONNEL-ID:1001",
+				"UID:emergencyreporting-user-1",
+				"END:VCARD",
+			},
+		},
+		{
+			description: "Escaping",
+			user:        &User{UserID: "1", FullName: `Doe, Jane; "J\D"`, Title: stringPointer("Line 1\nLine 2")},
+			expected: []string{
+				"BEGIN:VCARD",
+				"VERSION:3.0",
+				`FN:Doe\, Jane\; "J\\D"`,
+				"N:;;;;",
+				`TITLE:Line 1\nLine 2`,
+				"UID:emergencyreporting-user-1",
+				"END:VCARD",
+			},
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := UserVCard(row.user, row.contactInfo)
+			expected := strings.Join(row.expected, "\r\n") + "\r\n"
+			if actual != expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestFoldVCardLine(t *testing.T) {
+	rows := []struct {
+		description string
+		line        string
+		expected    string
+	}{
+		{
+			description: "Short",
+			line:        "FN:Jane Doe",
+			expected:    "FN:Jane Doe",
+		},
+		{
+			description: "Exactly the limit",
+			line:        strings.Repeat("a", 75),
+			expected:    strings.Repeat("a", 75),
+		},
+		{
+			description: "Two continuation lines",
+			line:        strings.Repeat("a", 75+74+1),
+			expected:    strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a",
+		},
+		{
+			description: "Multi-byte characters are kept whole",
+			line:        strings.Repeat("a", 74) + "é",
+			expected:    strings.Repeat("a", 74) + "\r\n é",
+		},
+	}
+	for _, row := range rows {
+		t.Run(row.description, func(t *testing.T) {
+			actual := foldVCardLine(row.line)
+			if actual != row.expected {
+				t.Errorf("Expected %q; got %q", row.expected, actual)
+			}
+		})
+	}
+}